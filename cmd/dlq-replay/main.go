@@ -0,0 +1,88 @@
+// Command dlq-replay republishes messages from a Kafka dead letter queue to the topic
+// they originally failed on. Replayed records are noted in <dlq>.replayed, so running
+// it again only replays what is new.
+//
+//	go run ./cmd/dlq-replay -dlq booking.created.dlq -error "mongo" -since 2h
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"ticket-tix/common/pkg/events"
+	"time"
+)
+
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	pairs := make([]string, 0, len(h))
+	for k, v := range h {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h headerFlags) Set(value string) error {
+	k, v, _ := strings.Cut(value, "=")
+	h[k] = v
+	return nil
+}
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	headers := headerFlags{}
+	brokers := flag.String("brokers", "localhost:9092", "comma separated Kafka brokers")
+	dlqTopic := flag.String("dlq", "", "DLQ topic to read, e.g. booking.created.dlq")
+	originalTopic := flag.String("original-topic", "", "only replay records whose original-topic header matches")
+	errContains := flag.String("error", "", "only replay records whose error header contains this text")
+	since := flag.Duration("since", 0, "only replay records written to the DLQ within this duration")
+	maxReplays := flag.Int("max-replays", 3, "refuse records that were already replayed this many times")
+	limit := flag.Int("limit", 0, "stop after this many replays (0 = no limit)")
+	dryRun := flag.Bool("dry-run", false, "report what would be replayed without publishing")
+	flag.Var(headers, "header", "only replay records with this header, key=value (repeatable)")
+	flag.Parse()
+
+	if *dlqTopic == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	filter := events.ReplayFilter{
+		OriginalTopic: *originalTopic,
+		ErrorContains: *errContains,
+		Headers:       headers,
+	}
+	if *since > 0 {
+		filter.Since = time.Now().Add(-*since)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	res, err := events.ReplayDLQ(ctx, events.ReplayConfig{
+		Brokers:    strings.Split(*brokers, ","),
+		DLQTopic:   *dlqTopic,
+		Filter:     filter,
+		MaxReplays: *maxReplays,
+		Limit:      *limit,
+		DryRun:     *dryRun,
+	}, logger)
+
+	logger.Info("dlq replay finished",
+		"scanned", res.Scanned,
+		"matched", res.Matched,
+		"replayed", res.Replayed,
+		"refused", res.Refused,
+		"skipped", res.Skipped,
+	)
+	if err != nil {
+		logger.Error("dlq replay failed", "err", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,225 @@
+// Command mock-oidc is an OpenID Connect provider for trying social login locally. It
+// signs in whoever types an email address, without a password.
+//
+//	go run ./cmd/mock-oidc -addr :50070
+//	OIDC_PROVIDERS=mock OIDC_MOCK_ISSUER=http://localhost:50070 OIDC_MOCK_CLIENT_ID=ticket-tix go run ./service/auth/cmd
+//
+// Unticking "email verified" on the sign in page exercises the auth service's refusal
+// to link unverified addresses.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"ticket-tix/common/pkg/jwt"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// codeExpiry is how long an authorization code can be exchanged.
+const codeExpiry = time.Minute
+
+type grant struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	challenge     string
+	email         string
+	emailVerified bool
+	expiresAt     time.Time
+}
+
+type idTokenClaims struct {
+	Nonce         string `json:"nonce,omitempty"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	gojwt.RegisteredClaims
+}
+
+type provider struct {
+	issuer string
+	keys   *jwt.KeyRing
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+var signInPage = template.Must(template.New("signin").Parse(`<!doctype html>
+<title>Mock OIDC sign in</title>
+<form method="post">
+	{{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+	<label>Email <input name="email" type="email" required autofocus></label>
+	<label><input name="email_verified" type="checkbox" value="true" checked> email verified</label>
+	<button>Sign in</button>
+</form>
+`))
+
+func main() {
+	addr := flag.String("addr", ":50070", "listen address")
+	issuer := flag.String("issuer", "http://localhost:50070", "issuer URL, as the auth service reaches it")
+	flag.Parse()
+
+	keyDir, err := os.MkdirTemp("", "mock-oidc-keys")
+	if err != nil {
+		log.Fatalf("create key dir: %v", err)
+	}
+	defer os.RemoveAll(keyDir)
+	keys, err := jwt.LoadKeyRing(keyDir)
+	if err != nil {
+		log.Fatalf("create signing key: %v", err)
+	}
+
+	p := &provider{issuer: strings.TrimSuffix(*issuer, "/"), keys: keys, codes: make(map[string]grant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("GET /authorize", p.authorizeForm)
+	mux.HandleFunc("POST /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+
+	log.Printf("mock OIDC provider %s listening on %s", p.issuer, *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"EdDSA"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.keys.JWKS())
+}
+
+// authorizeForm asks for the email to sign in as, or signs in login_hint directly.
+func (p *provider) authorizeForm(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("redirect_uri") == "" {
+		http.Error(w, "response_type=code and redirect_uri are required", http.StatusBadRequest)
+		return
+	}
+	if hint := q.Get("login_hint"); hint != "" {
+		q.Set("email", hint)
+		q.Set("email_verified", "true")
+		p.redirectWithCode(w, r, q)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	signInPage.Execute(w, q)
+}
+
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.redirectWithCode(w, r, r.PostForm)
+}
+
+func (p *provider) redirectWithCode(w http.ResponseWriter, r *http.Request, form url.Values) {
+	redirect, err := url.Parse(form.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := jwt.NewTokenID()
+	p.mu.Lock()
+	p.codes[code] = grant{
+		clientID:      form.Get("client_id"),
+		redirectURI:   form.Get("redirect_uri"),
+		nonce:         form.Get("nonce"),
+		challenge:     form.Get("code_challenge"),
+		email:         strings.ToLower(form.Get("email")),
+		emailVerified: form.Get("email_verified") == "true",
+		expiresAt:     time.Now().Add(codeExpiry),
+	}
+	p.mu.Unlock()
+
+	q := redirect.Query()
+	q.Set("code", code)
+	q.Set("state", form.Get("state"))
+	redirect.RawQuery = q.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token. Any client secret is accepted.
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(g.expiresAt) ||
+		g.clientID != r.PostForm.Get("client_id") ||
+		g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		!verifyChallenge(g.challenge, r.PostForm.Get("code_verifier")) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	subject := sha256.Sum256([]byte(g.email))
+	idToken, err := p.keys.Sign(idTokenClaims{
+		Nonce:         g.nonce,
+		Email:         g.email,
+		EmailVerified: g.emailVerified,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:    p.issuer,
+			Subject:   hex.EncodeToString(subject[:8]),
+			Audience:  gojwt.ClaimStrings{g.clientID},
+			IssuedAt:  gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": jwt.NewTokenID(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// verifyChallenge checks a PKCE S256 verifier. Clients that sent no challenge pass.
+func verifyChallenge(challenge, verifier string) bool {
+	if challenge == "" {
+		return true
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,71 @@
+// Command schema-check compares the event schemas in common/pkg/events with the
+// recorded registry in common/pkg/events/schemas.json. Run it in CI; after adding an
+// event or bumping a schema version, run it with -write to record the change.
+// Incompatible changes are refused either way.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"ticket-tix/common/pkg/events"
+)
+
+func main() {
+	path := flag.String("file", "common/pkg/events/schemas.json", "registry file to check and update")
+	write := flag.Bool("write", false, "record new compatible schemas in the registry file")
+	flag.Parse()
+
+	f, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("open registry: %v", err)
+	}
+	reg, err := events.LoadRegistry(f)
+	f.Close()
+	if err != nil {
+		log.Fatalf("load registry: %v", err)
+	}
+
+	schemas, err := events.Schemas()
+	if err != nil {
+		log.Fatalf("derive schemas: %v", err)
+	}
+
+	failed, pending := false, false
+	for _, s := range schemas {
+		added, err := reg.Check(s)
+		switch {
+		case err != nil:
+			log.Printf("FAIL %v", err)
+			failed = true
+		case added && *write:
+			if err := reg.Register(s); err != nil {
+				log.Fatalf("register %s v%d: %v", s.Type, s.Version, err)
+			}
+			log.Printf("recorded %s v%d", s.Type, s.Version)
+		case added:
+			log.Printf("NEW  %s v%d is not recorded, run with -write", s.Type, s.Version)
+			pending = true
+		default:
+			log.Printf("ok   %s v%d", s.Type, s.Version)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+
+	if *write {
+		out, err := os.Create(*path)
+		if err != nil {
+			log.Fatalf("create registry: %v", err)
+		}
+		defer out.Close()
+		if err := reg.Save(out); err != nil {
+			log.Fatalf("save registry: %v", err)
+		}
+		return
+	}
+	if pending {
+		os.Exit(1)
+	}
+}
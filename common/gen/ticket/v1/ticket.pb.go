@@ -425,11 +425,13 @@ func (x *ReserveFlexibleSeatResponse) GetSeatNumber() string {
 	return ""
 }
 
-// --- DecreaseTicket (STANDING stock) ---
+// --- DecreaseTicket (STANDING stock, held under reservation_id until expires_at) ---
 type DecreaseTicketRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	EventCategoryId int32                  `protobuf:"varint,1,opt,name=event_category_id,json=eventCategoryId,proto3" json:"event_category_id,omitempty"`
 	DecreaseBy      int64                  `protobuf:"varint,2,opt,name=decrease_by,json=decreaseBy,proto3" json:"decrease_by,omitempty"`
+	ReservationId   string                 `protobuf:"bytes,3,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	HoldSeconds     int64                  `protobuf:"varint,4,opt,name=hold_seconds,json=holdSeconds,proto3" json:"hold_seconds,omitempty"` // 0 uses the server default
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -478,8 +480,24 @@ func (x *DecreaseTicketRequest) GetDecreaseBy() int64 {
 	return 0
 }
 
+func (x *DecreaseTicketRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+func (x *DecreaseTicketRequest) GetHoldSeconds() int64 {
+	if x != nil {
+		return x.HoldSeconds
+	}
+	return 0
+}
+
 type DecreaseTicketResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Remaining     int64                  `protobuf:"varint,1,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix seconds
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -514,11 +532,26 @@ func (*DecreaseTicketResponse) Descriptor() ([]byte, []int) {
 	return file_ticket_proto_rawDescGZIP(), []int{9}
 }
 
-// --- IncreaseTicket (STANDING rollback) ---
+func (x *DecreaseTicketResponse) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *DecreaseTicketResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// --- IncreaseTicket (STANDING rollback of a hold) ---
 type IncreaseTicketRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	EventCategoryId int32                  `protobuf:"varint,1,opt,name=event_category_id,json=eventCategoryId,proto3" json:"event_category_id,omitempty"`
 	IncreaseBy      int64                  `protobuf:"varint,2,opt,name=increase_by,json=increaseBy,proto3" json:"increase_by,omitempty"`
+	ReservationId   string                 `protobuf:"bytes,3,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -567,6 +600,13 @@ func (x *IncreaseTicketRequest) GetIncreaseBy() int64 {
 	return 0
 }
 
+func (x *IncreaseTicketRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
 type IncreaseTicketResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -603,6 +643,313 @@ func (*IncreaseTicketResponse) Descriptor() ([]byte, []int) {
 	return file_ticket_proto_rawDescGZIP(), []int{11}
 }
 
+// --- ConfirmTicketStock (STANDING hold → sold) ---
+type ConfirmTicketStockRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EventCategoryId int32                  `protobuf:"varint,1,opt,name=event_category_id,json=eventCategoryId,proto3" json:"event_category_id,omitempty"`
+	ReservationId   string                 `protobuf:"bytes,2,opt,name=reservation_id,json=reservationId,proto3" json:"reservation_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConfirmTicketStockRequest) Reset() {
+	*x = ConfirmTicketStockRequest{}
+	mi := &file_ticket_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmTicketStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmTicketStockRequest) ProtoMessage() {}
+
+func (x *ConfirmTicketStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmTicketStockRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmTicketStockRequest) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ConfirmTicketStockRequest) GetEventCategoryId() int32 {
+	if x != nil {
+		return x.EventCategoryId
+	}
+	return 0
+}
+
+func (x *ConfirmTicketStockRequest) GetReservationId() string {
+	if x != nil {
+		return x.ReservationId
+	}
+	return ""
+}
+
+type ConfirmTicketStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfirmTicketStockResponse) Reset() {
+	*x = ConfirmTicketStockResponse{}
+	mi := &file_ticket_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfirmTicketStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmTicketStockResponse) ProtoMessage() {}
+
+func (x *ConfirmTicketStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmTicketStockResponse.ProtoReflect.Descriptor instead.
+func (*ConfirmTicketStockResponse) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{13}
+}
+
+// --- ReserveAccessibleSeat (accessible seat + companion, sold as a pair) ---
+type ReserveAccessibleSeatRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EventCategoryId int32                  `protobuf:"varint,1,opt,name=event_category_id,json=eventCategoryId,proto3" json:"event_category_id,omitempty"`
+	EventId         int32                  `protobuf:"varint,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReserveAccessibleSeatRequest) Reset() {
+	*x = ReserveAccessibleSeatRequest{}
+	mi := &file_ticket_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveAccessibleSeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveAccessibleSeatRequest) ProtoMessage() {}
+
+func (x *ReserveAccessibleSeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveAccessibleSeatRequest.ProtoReflect.Descriptor instead.
+func (*ReserveAccessibleSeatRequest) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReserveAccessibleSeatRequest) GetEventCategoryId() int32 {
+	if x != nil {
+		return x.EventCategoryId
+	}
+	return 0
+}
+
+func (x *ReserveAccessibleSeatRequest) GetEventId() int32 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type ReserveAccessibleSeatResponse struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	TicketId            int32                  `protobuf:"varint,1,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	SeatNumber          string                 `protobuf:"bytes,2,opt,name=seat_number,json=seatNumber,proto3" json:"seat_number,omitempty"`
+	CompanionTicketId   int32                  `protobuf:"varint,3,opt,name=companion_ticket_id,json=companionTicketId,proto3" json:"companion_ticket_id,omitempty"`
+	CompanionSeatNumber string                 `protobuf:"bytes,4,opt,name=companion_seat_number,json=companionSeatNumber,proto3" json:"companion_seat_number,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ReserveAccessibleSeatResponse) Reset() {
+	*x = ReserveAccessibleSeatResponse{}
+	mi := &file_ticket_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReserveAccessibleSeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReserveAccessibleSeatResponse) ProtoMessage() {}
+
+func (x *ReserveAccessibleSeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReserveAccessibleSeatResponse.ProtoReflect.Descriptor instead.
+func (*ReserveAccessibleSeatResponse) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReserveAccessibleSeatResponse) GetTicketId() int32 {
+	if x != nil {
+		return x.TicketId
+	}
+	return 0
+}
+
+func (x *ReserveAccessibleSeatResponse) GetSeatNumber() string {
+	if x != nil {
+		return x.SeatNumber
+	}
+	return ""
+}
+
+func (x *ReserveAccessibleSeatResponse) GetCompanionTicketId() int32 {
+	if x != nil {
+		return x.CompanionTicketId
+	}
+	return 0
+}
+
+func (x *ReserveAccessibleSeatResponse) GetCompanionSeatNumber() string {
+	if x != nil {
+		return x.CompanionSeatNumber
+	}
+	return ""
+}
+
+// --- ReleaseAccessibleSeat (SOLD pair → AVAILABLE, when its bookings could not be created) ---
+type ReleaseAccessibleSeatRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	EventCategoryId   int32                  `protobuf:"varint,1,opt,name=event_category_id,json=eventCategoryId,proto3" json:"event_category_id,omitempty"`
+	TicketId          int32                  `protobuf:"varint,2,opt,name=ticket_id,json=ticketId,proto3" json:"ticket_id,omitempty"`
+	CompanionTicketId int32                  `protobuf:"varint,3,opt,name=companion_ticket_id,json=companionTicketId,proto3" json:"companion_ticket_id,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ReleaseAccessibleSeatRequest) Reset() {
+	*x = ReleaseAccessibleSeatRequest{}
+	mi := &file_ticket_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseAccessibleSeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseAccessibleSeatRequest) ProtoMessage() {}
+
+func (x *ReleaseAccessibleSeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseAccessibleSeatRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseAccessibleSeatRequest) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReleaseAccessibleSeatRequest) GetEventCategoryId() int32 {
+	if x != nil {
+		return x.EventCategoryId
+	}
+	return 0
+}
+
+func (x *ReleaseAccessibleSeatRequest) GetTicketId() int32 {
+	if x != nil {
+		return x.TicketId
+	}
+	return 0
+}
+
+func (x *ReleaseAccessibleSeatRequest) GetCompanionTicketId() int32 {
+	if x != nil {
+		return x.CompanionTicketId
+	}
+	return 0
+}
+
+type ReleaseAccessibleSeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseAccessibleSeatResponse) Reset() {
+	*x = ReleaseAccessibleSeatResponse{}
+	mi := &file_ticket_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseAccessibleSeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseAccessibleSeatResponse) ProtoMessage() {}
+
+func (x *ReleaseAccessibleSeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ticket_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseAccessibleSeatResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseAccessibleSeatResponse) Descriptor() ([]byte, []int) {
+	return file_ticket_proto_rawDescGZIP(), []int{17}
+}
+
 var File_ticket_proto protoreflect.FileDescriptor
 
 const file_ticket_proto_rawDesc = "" +
@@ -631,24 +978,51 @@ const file_ticket_proto_rawDesc = "" +
 	"\x1bReserveFlexibleSeatResponse\x12\x1b\n" +
 	"\tticket_id\x18\x01 \x01(\x05R\bticketId\x12\x1f\n" +
 	"\vseat_number\x18\x02 \x01(\tR\n" +
-	"seatNumber\"d\n" +
+	"seatNumber\"\xae\x01\n" +
 	"\x15DecreaseTicketRequest\x12*\n" +
 	"\x11event_category_id\x18\x01 \x01(\x05R\x0feventCategoryId\x12\x1f\n" +
 	"\vdecrease_by\x18\x02 \x01(\x03R\n" +
-	"decreaseBy\"\x18\n" +
-	"\x16DecreaseTicketResponse\"d\n" +
+	"decreaseBy\x12%\n" +
+	"\x0ereservation_id\x18\x03 \x01(\tR\rreservationId\x12!\n" +
+	"\fhold_seconds\x18\x04 \x01(\x03R\vholdSeconds\"U\n" +
+	"\x16DecreaseTicketResponse\x12\x1c\n" +
+	"\tremaining\x18\x01 \x01(\x03R\tremaining\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\"\x8b\x01\n" +
 	"\x15IncreaseTicketRequest\x12*\n" +
 	"\x11event_category_id\x18\x01 \x01(\x05R\x0feventCategoryId\x12\x1f\n" +
 	"\vincrease_by\x18\x02 \x01(\x03R\n" +
-	"increaseBy\"\x18\n" +
-	"\x16IncreaseTicketResponse2\xf6\x03\n" +
+	"increaseBy\x12%\n" +
+	"\x0ereservation_id\x18\x03 \x01(\tR\rreservationId\"\x18\n" +
+	"\x16IncreaseTicketResponse\"n\n" +
+	"\x19ConfirmTicketStockRequest\x12*\n" +
+	"\x11event_category_id\x18\x01 \x01(\x05R\x0feventCategoryId\x12%\n" +
+	"\x0ereservation_id\x18\x02 \x01(\tR\rreservationId\"\x1c\n" +
+	"\x1aConfirmTicketStockResponse\"e\n" +
+	"\x1cReserveAccessibleSeatRequest\x12*\n" +
+	"\x11event_category_id\x18\x01 \x01(\x05R\x0feventCategoryId\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\x05R\aeventId\"\xc1\x01\n" +
+	"\x1dReserveAccessibleSeatResponse\x12\x1b\n" +
+	"\tticket_id\x18\x01 \x01(\x05R\bticketId\x12\x1f\n" +
+	"\vseat_number\x18\x02 \x01(\tR\n" +
+	"seatNumber\x12.\n" +
+	"\x13companion_ticket_id\x18\x03 \x01(\x05R\x11companionTicketId\x122\n" +
+	"\x15companion_seat_number\x18\x04 \x01(\tR\x13companionSeatNumber\"\x97\x01\n" +
+	"\x1cReleaseAccessibleSeatRequest\x12*\n" +
+	"\x11event_category_id\x18\x01 \x01(\x05R\x0feventCategoryId\x12\x1b\n" +
+	"\tticket_id\x18\x02 \x01(\x05R\bticketId\x12.\n" +
+	"\x13companion_ticket_id\x18\x03 \x01(\x05R\x11companionTicketId\"\x1f\n" +
+	"\x1dReleaseAccessibleSeatResponse2\x9f\x06\n" +
 	"\rTicketService\x12O\n" +
 	"\x0eValidateTicket\x12\x1d.ticket.ValidateTicketRequest\x1a\x1e.ticket.ValidateTicketResponse\x12L\n" +
 	"\rReserveTicket\x12\x1c.ticket.ReserveTicketRequest\x1a\x1d.ticket.ReserveTicketResponse\x12L\n" +
 	"\rReleaseTicket\x12\x1c.ticket.ReleaseTicketRequest\x1a\x1d.ticket.ReleaseTicketResponse\x12V\n" +
 	"\vReserveSeat\x12\".ticket.ReserveFlexibleSeatRequest\x1a#.ticket.ReserveFlexibleSeatResponse\x12O\n" +
 	"\x0eDecreaseTicket\x12\x1d.ticket.DecreaseTicketRequest\x1a\x1e.ticket.DecreaseTicketResponse\x12O\n" +
-	"\x0eIncreaseTicket\x12\x1d.ticket.IncreaseTicketRequest\x1a\x1e.ticket.IncreaseTicketResponseBAZ?github.com/dwikikusuma/ticket-tix/common/gen/ticket/v1;ticketv1b\x06proto3"
+	"\x0eIncreaseTicket\x12\x1d.ticket.IncreaseTicketRequest\x1a\x1e.ticket.IncreaseTicketResponse\x12[\n" +
+	"\x12ConfirmTicketStock\x12!.ticket.ConfirmTicketStockRequest\x1a\".ticket.ConfirmTicketStockResponse\x12d\n" +
+	"\x15ReserveAccessibleSeat\x12$.ticket.ReserveAccessibleSeatRequest\x1a%.ticket.ReserveAccessibleSeatResponse\x12d\n" +
+	"\x15ReleaseAccessibleSeat\x12$.ticket.ReleaseAccessibleSeatRequest\x1a%.ticket.ReleaseAccessibleSeatResponseBAZ?github.com/dwikikusuma/ticket-tix/common/gen/ticket/v1;ticketv1b\x06proto3"
 
 var (
 	file_ticket_proto_rawDescOnce sync.Once
@@ -662,20 +1036,26 @@ func file_ticket_proto_rawDescGZIP() []byte {
 	return file_ticket_proto_rawDescData
 }
 
-var file_ticket_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ticket_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_ticket_proto_goTypes = []any{
-	(*ValidateTicketRequest)(nil),       // 0: ticket.ValidateTicketRequest
-	(*ValidateTicketResponse)(nil),      // 1: ticket.ValidateTicketResponse
-	(*ReserveTicketRequest)(nil),        // 2: ticket.ReserveTicketRequest
-	(*ReserveTicketResponse)(nil),       // 3: ticket.ReserveTicketResponse
-	(*ReleaseTicketRequest)(nil),        // 4: ticket.ReleaseTicketRequest
-	(*ReleaseTicketResponse)(nil),       // 5: ticket.ReleaseTicketResponse
-	(*ReserveFlexibleSeatRequest)(nil),  // 6: ticket.ReserveFlexibleSeatRequest
-	(*ReserveFlexibleSeatResponse)(nil), // 7: ticket.ReserveFlexibleSeatResponse
-	(*DecreaseTicketRequest)(nil),       // 8: ticket.DecreaseTicketRequest
-	(*DecreaseTicketResponse)(nil),      // 9: ticket.DecreaseTicketResponse
-	(*IncreaseTicketRequest)(nil),       // 10: ticket.IncreaseTicketRequest
-	(*IncreaseTicketResponse)(nil),      // 11: ticket.IncreaseTicketResponse
+	(*ValidateTicketRequest)(nil),         // 0: ticket.ValidateTicketRequest
+	(*ValidateTicketResponse)(nil),        // 1: ticket.ValidateTicketResponse
+	(*ReserveTicketRequest)(nil),          // 2: ticket.ReserveTicketRequest
+	(*ReserveTicketResponse)(nil),         // 3: ticket.ReserveTicketResponse
+	(*ReleaseTicketRequest)(nil),          // 4: ticket.ReleaseTicketRequest
+	(*ReleaseTicketResponse)(nil),         // 5: ticket.ReleaseTicketResponse
+	(*ReserveFlexibleSeatRequest)(nil),    // 6: ticket.ReserveFlexibleSeatRequest
+	(*ReserveFlexibleSeatResponse)(nil),   // 7: ticket.ReserveFlexibleSeatResponse
+	(*DecreaseTicketRequest)(nil),         // 8: ticket.DecreaseTicketRequest
+	(*DecreaseTicketResponse)(nil),        // 9: ticket.DecreaseTicketResponse
+	(*IncreaseTicketRequest)(nil),         // 10: ticket.IncreaseTicketRequest
+	(*IncreaseTicketResponse)(nil),        // 11: ticket.IncreaseTicketResponse
+	(*ConfirmTicketStockRequest)(nil),     // 12: ticket.ConfirmTicketStockRequest
+	(*ConfirmTicketStockResponse)(nil),    // 13: ticket.ConfirmTicketStockResponse
+	(*ReserveAccessibleSeatRequest)(nil),  // 14: ticket.ReserveAccessibleSeatRequest
+	(*ReserveAccessibleSeatResponse)(nil), // 15: ticket.ReserveAccessibleSeatResponse
+	(*ReleaseAccessibleSeatRequest)(nil),  // 16: ticket.ReleaseAccessibleSeatRequest
+	(*ReleaseAccessibleSeatResponse)(nil), // 17: ticket.ReleaseAccessibleSeatResponse
 }
 var file_ticket_proto_depIdxs = []int32{
 	0,  // 0: ticket.TicketService.ValidateTicket:input_type -> ticket.ValidateTicketRequest
@@ -684,14 +1064,20 @@ var file_ticket_proto_depIdxs = []int32{
 	6,  // 3: ticket.TicketService.ReserveSeat:input_type -> ticket.ReserveFlexibleSeatRequest
 	8,  // 4: ticket.TicketService.DecreaseTicket:input_type -> ticket.DecreaseTicketRequest
 	10, // 5: ticket.TicketService.IncreaseTicket:input_type -> ticket.IncreaseTicketRequest
-	1,  // 6: ticket.TicketService.ValidateTicket:output_type -> ticket.ValidateTicketResponse
-	3,  // 7: ticket.TicketService.ReserveTicket:output_type -> ticket.ReserveTicketResponse
-	5,  // 8: ticket.TicketService.ReleaseTicket:output_type -> ticket.ReleaseTicketResponse
-	7,  // 9: ticket.TicketService.ReserveSeat:output_type -> ticket.ReserveFlexibleSeatResponse
-	9,  // 10: ticket.TicketService.DecreaseTicket:output_type -> ticket.DecreaseTicketResponse
-	11, // 11: ticket.TicketService.IncreaseTicket:output_type -> ticket.IncreaseTicketResponse
-	6,  // [6:12] is the sub-list for method output_type
-	0,  // [0:6] is the sub-list for method input_type
+	12, // 6: ticket.TicketService.ConfirmTicketStock:input_type -> ticket.ConfirmTicketStockRequest
+	14, // 7: ticket.TicketService.ReserveAccessibleSeat:input_type -> ticket.ReserveAccessibleSeatRequest
+	16, // 8: ticket.TicketService.ReleaseAccessibleSeat:input_type -> ticket.ReleaseAccessibleSeatRequest
+	1,  // 9: ticket.TicketService.ValidateTicket:output_type -> ticket.ValidateTicketResponse
+	3,  // 10: ticket.TicketService.ReserveTicket:output_type -> ticket.ReserveTicketResponse
+	5,  // 11: ticket.TicketService.ReleaseTicket:output_type -> ticket.ReleaseTicketResponse
+	7,  // 12: ticket.TicketService.ReserveSeat:output_type -> ticket.ReserveFlexibleSeatResponse
+	9,  // 13: ticket.TicketService.DecreaseTicket:output_type -> ticket.DecreaseTicketResponse
+	11, // 14: ticket.TicketService.IncreaseTicket:output_type -> ticket.IncreaseTicketResponse
+	13, // 15: ticket.TicketService.ConfirmTicketStock:output_type -> ticket.ConfirmTicketStockResponse
+	15, // 16: ticket.TicketService.ReserveAccessibleSeat:output_type -> ticket.ReserveAccessibleSeatResponse
+	17, // 17: ticket.TicketService.ReleaseAccessibleSeat:output_type -> ticket.ReleaseAccessibleSeatResponse
+	9,  // [9:18] is the sub-list for method output_type
+	0,  // [0:9] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -708,7 +1094,7 @@ func file_ticket_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ticket_proto_rawDesc), len(file_ticket_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
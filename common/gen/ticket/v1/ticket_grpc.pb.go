@@ -19,12 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TicketService_ValidateTicket_FullMethodName = "/ticket.TicketService/ValidateTicket"
-	TicketService_ReserveTicket_FullMethodName  = "/ticket.TicketService/ReserveTicket"
-	TicketService_ReleaseTicket_FullMethodName  = "/ticket.TicketService/ReleaseTicket"
-	TicketService_ReserveSeat_FullMethodName    = "/ticket.TicketService/ReserveSeat"
-	TicketService_DecreaseTicket_FullMethodName = "/ticket.TicketService/DecreaseTicket"
-	TicketService_IncreaseTicket_FullMethodName = "/ticket.TicketService/IncreaseTicket"
+	TicketService_ValidateTicket_FullMethodName        = "/ticket.TicketService/ValidateTicket"
+	TicketService_ReserveTicket_FullMethodName         = "/ticket.TicketService/ReserveTicket"
+	TicketService_ReleaseTicket_FullMethodName         = "/ticket.TicketService/ReleaseTicket"
+	TicketService_ReserveSeat_FullMethodName           = "/ticket.TicketService/ReserveSeat"
+	TicketService_DecreaseTicket_FullMethodName        = "/ticket.TicketService/DecreaseTicket"
+	TicketService_IncreaseTicket_FullMethodName        = "/ticket.TicketService/IncreaseTicket"
+	TicketService_ConfirmTicketStock_FullMethodName    = "/ticket.TicketService/ConfirmTicketStock"
+	TicketService_ReserveAccessibleSeat_FullMethodName = "/ticket.TicketService/ReserveAccessibleSeat"
+	TicketService_ReleaseAccessibleSeat_FullMethodName = "/ticket.TicketService/ReleaseAccessibleSeat"
 )
 
 // TicketServiceClient is the client API for TicketService service.
@@ -37,6 +40,9 @@ type TicketServiceClient interface {
 	ReserveSeat(ctx context.Context, in *ReserveFlexibleSeatRequest, opts ...grpc.CallOption) (*ReserveFlexibleSeatResponse, error)
 	DecreaseTicket(ctx context.Context, in *DecreaseTicketRequest, opts ...grpc.CallOption) (*DecreaseTicketResponse, error)
 	IncreaseTicket(ctx context.Context, in *IncreaseTicketRequest, opts ...grpc.CallOption) (*IncreaseTicketResponse, error)
+	ConfirmTicketStock(ctx context.Context, in *ConfirmTicketStockRequest, opts ...grpc.CallOption) (*ConfirmTicketStockResponse, error)
+	ReserveAccessibleSeat(ctx context.Context, in *ReserveAccessibleSeatRequest, opts ...grpc.CallOption) (*ReserveAccessibleSeatResponse, error)
+	ReleaseAccessibleSeat(ctx context.Context, in *ReleaseAccessibleSeatRequest, opts ...grpc.CallOption) (*ReleaseAccessibleSeatResponse, error)
 }
 
 type ticketServiceClient struct {
@@ -107,6 +113,36 @@ func (c *ticketServiceClient) IncreaseTicket(ctx context.Context, in *IncreaseTi
 	return out, nil
 }
 
+func (c *ticketServiceClient) ConfirmTicketStock(ctx context.Context, in *ConfirmTicketStockRequest, opts ...grpc.CallOption) (*ConfirmTicketStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmTicketStockResponse)
+	err := c.cc.Invoke(ctx, TicketService_ConfirmTicketStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ticketServiceClient) ReserveAccessibleSeat(ctx context.Context, in *ReserveAccessibleSeatRequest, opts ...grpc.CallOption) (*ReserveAccessibleSeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReserveAccessibleSeatResponse)
+	err := c.cc.Invoke(ctx, TicketService_ReserveAccessibleSeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ticketServiceClient) ReleaseAccessibleSeat(ctx context.Context, in *ReleaseAccessibleSeatRequest, opts ...grpc.CallOption) (*ReleaseAccessibleSeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseAccessibleSeatResponse)
+	err := c.cc.Invoke(ctx, TicketService_ReleaseAccessibleSeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TicketServiceServer is the server API for TicketService service.
 // All implementations must embed UnimplementedTicketServiceServer
 // for forward compatibility.
@@ -117,6 +153,9 @@ type TicketServiceServer interface {
 	ReserveSeat(context.Context, *ReserveFlexibleSeatRequest) (*ReserveFlexibleSeatResponse, error)
 	DecreaseTicket(context.Context, *DecreaseTicketRequest) (*DecreaseTicketResponse, error)
 	IncreaseTicket(context.Context, *IncreaseTicketRequest) (*IncreaseTicketResponse, error)
+	ConfirmTicketStock(context.Context, *ConfirmTicketStockRequest) (*ConfirmTicketStockResponse, error)
+	ReserveAccessibleSeat(context.Context, *ReserveAccessibleSeatRequest) (*ReserveAccessibleSeatResponse, error)
+	ReleaseAccessibleSeat(context.Context, *ReleaseAccessibleSeatRequest) (*ReleaseAccessibleSeatResponse, error)
 	mustEmbedUnimplementedTicketServiceServer()
 }
 
@@ -145,6 +184,15 @@ func (UnimplementedTicketServiceServer) DecreaseTicket(context.Context, *Decreas
 func (UnimplementedTicketServiceServer) IncreaseTicket(context.Context, *IncreaseTicketRequest) (*IncreaseTicketResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method IncreaseTicket not implemented")
 }
+func (UnimplementedTicketServiceServer) ConfirmTicketStock(context.Context, *ConfirmTicketStockRequest) (*ConfirmTicketStockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTicketStock not implemented")
+}
+func (UnimplementedTicketServiceServer) ReserveAccessibleSeat(context.Context, *ReserveAccessibleSeatRequest) (*ReserveAccessibleSeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveAccessibleSeat not implemented")
+}
+func (UnimplementedTicketServiceServer) ReleaseAccessibleSeat(context.Context, *ReleaseAccessibleSeatRequest) (*ReleaseAccessibleSeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseAccessibleSeat not implemented")
+}
 func (UnimplementedTicketServiceServer) mustEmbedUnimplementedTicketServiceServer() {}
 func (UnimplementedTicketServiceServer) testEmbeddedByValue()                       {}
 
@@ -274,6 +322,60 @@ func _TicketService_IncreaseTicket_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TicketService_ConfirmTicketStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTicketStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).ConfirmTicketStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_ConfirmTicketStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).ConfirmTicketStock(ctx, req.(*ConfirmTicketStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TicketService_ReserveAccessibleSeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReserveAccessibleSeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).ReserveAccessibleSeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_ReserveAccessibleSeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).ReserveAccessibleSeat(ctx, req.(*ReserveAccessibleSeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TicketService_ReleaseAccessibleSeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseAccessibleSeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TicketServiceServer).ReleaseAccessibleSeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TicketService_ReleaseAccessibleSeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TicketServiceServer).ReleaseAccessibleSeat(ctx, req.(*ReleaseAccessibleSeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TicketService_ServiceDesc is the grpc.ServiceDesc for TicketService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,6 +407,18 @@ var TicketService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "IncreaseTicket",
 			Handler:    _TicketService_IncreaseTicket_Handler,
 		},
+		{
+			MethodName: "ConfirmTicketStock",
+			Handler:    _TicketService_ConfirmTicketStock_Handler,
+		},
+		{
+			MethodName: "ReserveAccessibleSeat",
+			Handler:    _TicketService_ReserveAccessibleSeat_Handler,
+		},
+		{
+			MethodName: "ReleaseAccessibleSeat",
+			Handler:    _TicketService_ReleaseAccessibleSeat_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "ticket.proto",
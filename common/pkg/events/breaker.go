@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting Kafka while the producer's circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("kafka circuit breaker open")
+
+// BreakerConfig controls the producer circuit breaker. A zero FailureThreshold
+// disables it.
+type BreakerConfig struct {
+	// FailureThreshold is how many publishes in a row must fail to open the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before a single probe publish
+	// is let through.
+	OpenTimeout time.Duration
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breaker struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		return nil
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = 30 * time.Second
+	}
+	return &breaker{cfg: cfg}
+}
+
+// allow reports whether a publish may go to Kafka. Once the open timeout has passed
+// it lets exactly one probe through; its outcome decides whether the breaker closes.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		b.state = breakerHalfOpen
+		return nil
+	case breakerHalfOpen:
+		// a probe is already in flight
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record feeds the outcome of a publish back into the breaker. Cancellations by the
+// caller say nothing about the cluster and count as neither success nor failure; a
+// cancelled probe returns the breaker to open without restarting the open timeout,
+// so the next publish probes again.
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		if b.state == breakerHalfOpen {
+			b.state = breakerOpen
+		}
+		return
+	}
+
+	if err == nil {
+		if b.state != breakerClosed {
+			log.Println("kafka circuit breaker closed")
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		if b.state != breakerOpen {
+			log.Printf("kafka circuit breaker open after %d failures: %v", b.failures, err)
+		}
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
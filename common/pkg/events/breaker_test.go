@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errBroker = errors.New("broker unreachable")
+
+func TestBreakerTransitions(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	// closed until FailureThreshold publishes in a row fail
+	b.record(errBroker)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after one failure = %v, want nil", err)
+	}
+	b.record(nil)
+	b.record(errBroker)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after a success reset the count = %v, want nil", err)
+	}
+	b.record(errBroker)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() after %d failures = %v, want ErrCircuitOpen", 2, err)
+	}
+
+	// one probe once the open timeout passed
+	expireOpen(b)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() of the probe = %v, want nil", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() beside the probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// a failed probe opens it for another timeout
+	b.record(errBroker)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() after a failed probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// a successful probe closes it
+	expireOpen(b)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() of the probe = %v, want nil", err)
+	}
+	b.record(nil)
+	for range 3 {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() after a successful probe = %v, want nil", err)
+		}
+	}
+}
+
+func TestBreakerCancelledProbe(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+	b.record(errBroker)
+	expireOpen(b)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() of the probe = %v, want nil", err)
+	}
+
+	// the caller gave up on the probe; the next publish probes again
+	b.record(context.Canceled)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after a cancelled probe = %v, want a new probe", err)
+	}
+	b.record(nil)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after a successful probe = %v, want nil", err)
+	}
+
+	// cancellations while closed do not count as failures
+	b.record(context.Canceled)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() after a cancelled publish = %v, want nil", err)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(BreakerConfig{})
+	for range 10 {
+		b.record(errBroker)
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() of a disabled breaker = %v, want nil", err)
+	}
+}
+
+// expireOpen moves the breaker's open time back past its timeout.
+func expireOpen(b *breaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = time.Now().Add(-b.cfg.OpenTimeout)
+}
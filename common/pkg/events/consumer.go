@@ -2,13 +2,16 @@ package events
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/twmb/franz-go/pkg/kerr"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
 )
 
 type ConsumerConfig struct {
@@ -16,23 +19,70 @@ type ConsumerConfig struct {
 	GroupID      string
 	DLQSuffix    string
 	FetchMaxWait time.Duration
+	// Workers is how many records are handled concurrently. Records with the same key
+	// always go to the same worker, so they keep their order.
+	Workers int
+	// MaxInFlightPerPartition pauses fetching a partition once this many of its
+	// records are waiting or being handled; it resumes at half.
+	MaxInFlightPerPartition int
+	// CommitInterval is how often finished offsets are committed while a partition
+	// still has records in flight. A partition is also committed whenever it drains.
+	CommitInterval time.Duration
+	// RevokeTimeout bounds how long a revoked partition waits for its in-flight records
+	// before giving them up to the next owner.
+	RevokeTimeout time.Duration
+	Hooks         ConsumerHooks
+	// Metrics, when set, receives per-partition consumer lag.
+	Metrics *Metrics
+}
+
+// Partitions maps topics to partition numbers.
+type Partitions map[string][]int32
+
+// ConsumerHooks let a service react to the consumer lifecycle, for example to flush
+// buffered writes before offsets are committed. All hooks are optional.
+type ConsumerHooks struct {
+	// OnAssigned runs when partitions are assigned to this member.
+	OnAssigned func(ctx context.Context, assigned Partitions)
+	// OnRevoked runs after the revoked partitions' in-flight records are done and
+	// before their offsets are committed.
+	OnRevoked func(ctx context.Context, revoked Partitions)
+	// OnLost runs when partitions were taken away without a chance to commit.
+	OnLost func(ctx context.Context, lost Partitions)
+	// OnStop runs on shutdown after the workers stopped and before the final commit.
+	OnStop func(ctx context.Context)
 }
 
 func DefaultConsumerConfig(brokers []string, groupID string) ConsumerConfig {
 	return ConsumerConfig{
-		Brokers:      brokers,
-		GroupID:      groupID,
-		DLQSuffix:    ".dlq",
-		FetchMaxWait: 500 * time.Millisecond,
+		Brokers:                 brokers,
+		GroupID:                 groupID,
+		DLQSuffix:               ".dlq",
+		FetchMaxWait:            500 * time.Millisecond,
+		Workers:                 16,
+		MaxInFlightPerPartition: 500,
+		CommitInterval:          time.Second,
+		RevokeTimeout:           10 * time.Second,
 	}
 }
 
+type work struct {
+	rec   *kgo.Record
+	state *partitionState
+}
+
 type franzClient struct {
 	client *kgo.Client
 	logger *slog.Logger
-	dlq    *kgo.Client
-	router *Router
-	cfg    ConsumerConfig
+	// producer writes to retry topics and the DLQ
+	producer *kgo.Client
+	router   *Router
+	cfg      ConsumerConfig
+
+	workers []chan work
+
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionState
 }
 
 type Consumer interface {
@@ -41,6 +91,26 @@ type Consumer interface {
 }
 
 func NewConsumer(cfg ConsumerConfig, routeHandler *Router, logger *slog.Logger) (Consumer, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxInFlightPerPartition <= 0 {
+		cfg.MaxInFlightPerPartition = 500
+	}
+	if cfg.CommitInterval <= 0 {
+		cfg.CommitInterval = time.Second
+	}
+	if cfg.RevokeTimeout <= 0 {
+		cfg.RevokeTimeout = 10 * time.Second
+	}
+
+	c := &franzClient{
+		router:     routeHandler,
+		logger:     logger,
+		cfg:        cfg,
+		partitions: make(map[topicPartition]*partitionState),
+	}
+
 	client, err := kgo.NewClient(
 		kgo.SeedBrokers(cfg.Brokers...),
 		kgo.ConsumerGroup(cfg.GroupID),
@@ -48,15 +118,23 @@ func NewConsumer(cfg ConsumerConfig, routeHandler *Router, logger *slog.Logger)
 		kgo.DisableAutoCommit(),
 		kgo.FetchMaxWait(cfg.FetchMaxWait),
 		kgo.FetchMinBytes(1),
+		// rebalances wait until the records of a poll are dispatched, see Start
+		kgo.BlockRebalanceOnPoll(),
+		kgo.OnPartitionsAssigned(c.onAssigned),
+		kgo.OnPartitionsRevoked(c.onRevoked),
+		kgo.OnPartitionsLost(c.onLost),
 	)
 	if err != nil {
 		fmt.Println("failed to create Kafka consumer:", err)
 		return nil, err
 	}
 
-	dlqClient, err := kgo.NewClient(
+	// retry and DLQ topics are created on first use if the deployment did not create
+	// them, otherwise forwardFailure would retry a missing topic until shutdown
+	producerClient, err := kgo.NewClient(
 		kgo.SeedBrokers(cfg.Brokers...),
 		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.AllowAutoTopicCreation(),
 	)
 
 	if err != nil {
@@ -64,13 +142,9 @@ func NewConsumer(cfg ConsumerConfig, routeHandler *Router, logger *slog.Logger)
 		return nil, err
 	}
 
-	return &franzClient{
-		client: client,
-		dlq:    dlqClient,
-		router: routeHandler,
-		logger: logger,
-		cfg:    cfg,
-	}, nil
+	c.client = client
+	c.producer = producerClient
+	return c, nil
 }
 
 func (c *franzClient) Start(ctx context.Context) error {
@@ -79,47 +153,300 @@ func (c *franzClient) Start(ctx context.Context) error {
 		c.logger.Info("no topics defined")
 		return nil
 	}
+
+	var wg sync.WaitGroup
+	c.workers = make([]chan work, c.cfg.Workers)
+	for i := range c.workers {
+		c.workers[i] = make(chan work, c.cfg.MaxInFlightPerPartition)
+		wg.Add(1)
+		go func(queue chan work) {
+			defer wg.Done()
+			c.runWorker(ctx, queue)
+		}(c.workers[i])
+	}
+
+	committerDone := make(chan struct{})
+	go func() {
+		defer close(committerDone)
+		c.runCommitter(ctx)
+	}()
+
 	c.client.AddConsumeTopics(topics...)
-	c.logger.Info("consumer started")
+	c.logger.Info("consumer started", "workers", c.cfg.Workers)
 	for {
 		fetches := c.client.PollFetches(ctx)
-		if err := fetches.Err0(); err != nil {
-			if errors.Is(err, context.Canceled) {
-				c.logger.Warn("context canceled, stopping consumer gracefully")
-				return nil
-			}
-			c.logger.Warn("fetch error continue to next message", "error", err.Error())
-			continue
+		if ctx.Err() != nil {
+			c.logger.Warn("context canceled, stopping consumer gracefully")
+			break
 		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			c.logger.Warn("fetch error", "topic", topic, "partition", partition, "error", err.Error())
+		})
 
-		var wg sync.WaitGroup
 		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
-			wg.Add(1)
-			partition := p
-			go func() {
-				defer wg.Done()
-				c.handlePartition(ctx, partition)
-			}()
+			c.dispatch(ctx, p)
 		})
+		c.client.AllowRebalance()
+	}
+
+	for _, queue := range c.workers {
+		close(queue)
+	}
+	wg.Wait()
+	<-committerDone
+
+	// whatever the workers skipped is left for redelivery; the revoke on Close must
+	// not wait for it
+	c.mu.Lock()
+	for _, state := range c.partitions {
+		state.stopAll()
+	}
+	c.mu.Unlock()
+
+	stopCtx := context.WithoutCancel(ctx)
+	if c.cfg.Hooks.OnStop != nil {
+		c.cfg.Hooks.OnStop(stopCtx)
+	}
+	c.commitAll(stopCtx)
+	return nil
+}
 
-		wg.Wait()
+func (c *franzClient) onAssigned(ctx context.Context, _ *kgo.Client, assigned map[string][]int32) {
+	c.logger.Info("partitions assigned", "partitions", assigned)
+	if c.cfg.Hooks.OnAssigned != nil {
+		c.cfg.Hooks.OnAssigned(ctx, assigned)
+	}
+}
 
-		if err := c.client.CommitUncommittedOffsets(ctx); err != nil {
-			c.logger.Warn("failed to commit uncommitted offsets", "error", err.Error())
+// onRevoked lets the workers finish what they already have for the revoked partitions,
+// then commits them, so the next owner does not handle those records again.
+func (c *franzClient) onRevoked(ctx context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	states := c.takePartitions(revoked)
+	c.logger.Info("partitions revoked, draining", "partitions", revoked)
+
+	timeout := time.NewTimer(c.cfg.RevokeTimeout)
+	defer timeout.Stop()
+	for _, state := range states {
+		select {
+		case <-state.waitDrained():
+		case <-timeout.C:
+			c.logger.Warn("revoke drain timed out, leaving remaining records to the next owner",
+				"topic", state.tp.topic, "partition", state.tp.partition)
+			state.stopAll()
 		}
 	}
+
+	if c.cfg.Hooks.OnRevoked != nil {
+		c.cfg.Hooks.OnRevoked(ctx, revoked)
+	}
+	for _, state := range states {
+		c.commitPartition(ctx, state)
+	}
 }
 
-func (c *franzClient) sendToDlq(ctx context.Context, original *kgo.Record, handleErr error) {
-	dlqTopic := original.Topic + c.cfg.DLQSuffix
-	headers := make([]kgo.RecordHeader, len(original.Headers), len(original.Headers)+4)
-	copy(headers, original.Headers)
-	headers = append(headers,
-		kgo.RecordHeader{Key: "original-topic", Value: []byte(original.Topic)},
-		kgo.RecordHeader{Key: "original-partition", Value: []byte(fmt.Sprintf("%d", original.Partition))},
-		kgo.RecordHeader{Key: "original-offset", Value: []byte(fmt.Sprintf("%d", original.Offset))},
-		kgo.RecordHeader{Key: "error", Value: []byte(handleErr.Error())},
-	)
+// onLost drops the lost partitions without committing; their new owner already took over.
+func (c *franzClient) onLost(ctx context.Context, _ *kgo.Client, lost map[string][]int32) {
+	c.logger.Warn("partitions lost", "partitions", lost)
+	for _, state := range c.takePartitions(lost) {
+		state.stopAll()
+	}
+	if c.cfg.Hooks.OnLost != nil {
+		c.cfg.Hooks.OnLost(ctx, lost)
+	}
+}
+
+// takePartitions removes partitions from tracking and returns their state. The client
+// keeps a partition paused across reassignment, so the paused ones are resumed here;
+// otherwise a partition assigned back to this consumer would never be fetched again.
+func (c *franzClient) takePartitions(partitions map[string][]int32) []*partitionState {
+	c.mu.Lock()
+	var states []*partitionState
+	paused := make(map[string][]int32)
+	for topic, nums := range partitions {
+		for _, p := range nums {
+			tp := topicPartition{topic: topic, partition: p}
+			if state, ok := c.partitions[tp]; ok {
+				states = append(states, state)
+				delete(c.partitions, tp)
+				if state.fetchPaused() {
+					paused[topic] = append(paused[topic], p)
+				}
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if len(paused) > 0 {
+		c.client.ResumeFetchPartitions(paused)
+	}
+	return states
+}
+
+func (c *franzClient) partitionState(topic string, partition int32) *partitionState {
+	tp := topicPartition{topic: topic, partition: partition}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.partitions[tp]
+	if !ok {
+		state = newPartitionState(tp)
+		c.partitions[tp] = state
+	}
+	return state
+}
+
+// dispatch hands the records of one fetched partition to the workers. Sending blocks
+// when a worker queue is full, which holds up polling until workers catch up.
+func (c *franzClient) dispatch(ctx context.Context, p kgo.FetchTopicPartition) {
+	state := c.partitionState(p.Topic, p.Partition)
+	retry := c.router.IsRetryTopic(p.Topic)
+	if n := len(p.Records); n > 0 {
+		c.cfg.Metrics.observeLag(p.Topic, p.Partition, p.HighWatermark-(p.Records[n-1].Offset+1))
+	}
+
+	for _, rec := range p.Records {
+		inFlight, ok := state.add(rec)
+		if !ok {
+			continue
+		}
+		if inFlight >= c.cfg.MaxInFlightPerPartition && state.setPaused(true) {
+			c.client.PauseFetchPartitions(map[string][]int32{p.Topic: {p.Partition}})
+		}
+
+		select {
+		case c.workers[c.workerFor(rec, retry)] <- work{rec: rec, state: state}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// workerFor picks the worker for a record by key, so one key is never handled by two
+// workers at once. Retry partitions are handled by a single worker in offset order,
+// since their records become due in that order.
+func (c *franzClient) workerFor(rec *kgo.Record, retry bool) int {
+	h := fnv.New32a()
+	h.Write([]byte(rec.Topic))
+	if retry || len(rec.Key) == 0 {
+		h.Write([]byte(strconv.Itoa(int(rec.Partition))))
+	} else {
+		h.Write(rec.Key)
+	}
+	return int(h.Sum32() % uint32(len(c.workers)))
+}
+
+func (c *franzClient) runWorker(ctx context.Context, queue chan work) {
+	for w := range queue {
+		if ctx.Err() != nil || w.state.skip(w.rec) {
+			continue
+		}
+		c.process(ctx, w)
+	}
+}
+
+func (c *franzClient) process(ctx context.Context, w work) {
+	rec := w.rec
+	if c.router.IsRetryTopic(rec.Topic) {
+		if due := notBeforeHeader(headerMap(rec.Headers)); time.Now().Before(due) {
+			// retry topics are ordered by due time, so the rest of the partition waits too
+			c.deferPartition(w.state, rec, due)
+			return
+		}
+	}
+
+	msgCtx, msg := c.toMessage(ctx, rec)
+	err := c.router.Route(msgCtx, msg)
+	if err != nil {
+		c.logger.Error("message routing failed, forwarding to retry/DLQ",
+			"topic", rec.Topic,
+			"partition", rec.Partition,
+			"offset", rec.Offset,
+			"key", string(rec.Key),
+			"err", err,
+		)
+		if !c.forwardFailure(ctx, rec, err) {
+			// leave this and later records uncommitted so they are redelivered
+			w.state.stop(rec)
+			return
+		}
+	}
+
+	remaining := w.state.complete(rec)
+	if remaining <= c.cfg.MaxInFlightPerPartition/2 && w.state.setPaused(false) {
+		c.client.ResumeFetchPartitions(map[string][]int32{rec.Topic: {rec.Partition}})
+	}
+	if remaining == 0 {
+		c.commitPartition(ctx, w.state)
+	}
+}
+
+func (c *franzClient) runCommitter(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.CommitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.commitAll(ctx)
+		}
+	}
+}
+
+func (c *franzClient) commitAll(ctx context.Context) {
+	c.mu.Lock()
+	states := make([]*partitionState, 0, len(c.partitions))
+	for _, state := range c.partitions {
+		states = append(states, state)
+	}
+	c.mu.Unlock()
+
+	for _, state := range states {
+		c.commitPartition(ctx, state)
+	}
+}
+
+// commitPartition commits the finished prefix of one partition.
+func (c *franzClient) commitPartition(ctx context.Context, state *partitionState) {
+	offset, ok := state.commitPoint()
+	if !ok {
+		return
+	}
+
+	var commitErr error
+	c.client.CommitOffsetsSync(ctx, map[string]map[int32]kgo.EpochOffset{
+		state.tp.topic: {state.tp.partition: offset},
+	}, func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, resp *kmsg.OffsetCommitResponse, err error) {
+		if err != nil {
+			commitErr = err
+			return
+		}
+		for _, t := range resp.Topics {
+			for _, p := range t.Partitions {
+				if err := kerr.ErrorForCode(p.ErrorCode); err != nil {
+					commitErr = err
+				}
+			}
+		}
+	})
+	if commitErr != nil {
+		c.logger.Warn("failed to commit offsets",
+			"topic", state.tp.topic,
+			"partition", state.tp.partition,
+			"offset", offset.Offset,
+			"error", commitErr.Error(),
+		)
+		return
+	}
+	state.markCommitted(offset.Offset)
+}
+
+// sendToDlq copies a failed record to <base topic><DLQSuffix>, annotated with where it
+// came from and why it failed. A record that failed on a retry topic is filed under
+// its base topic so a replay goes back there.
+func (c *franzClient) sendToDlq(ctx context.Context, original *kgo.Record, handleErr error) error {
+	baseTopic := c.router.BaseTopic(original.Topic)
+	dlqTopic := baseTopic + c.cfg.DLQSuffix
+	headers := recordHeaders(dlqHeaders(headerMap(original.Headers), baseTopic, original.Partition, original.Offset, handleErr))
 
 	rec := &kgo.Record{
 		Topic:   dlqTopic,
@@ -128,57 +455,130 @@ func (c *franzClient) sendToDlq(ctx context.Context, original *kgo.Record, handl
 		Headers: headers,
 	}
 
-	results := c.dlq.ProduceSync(ctx, rec)
+	results := c.producer.ProduceSync(ctx, rec)
 	if err := results.FirstErr(); err != nil {
-		c.logger.Error("CRITICAL: DLQ produce failed — message may be lost",
+		c.logger.Error("CRITICAL: DLQ produce failed",
 			"dlq_topic", dlqTopic,
 			"original_topic", original.Topic,
 			"original_offset", original.Offset,
 			"err", err,
 		)
-	} else {
-		c.logger.Warn("message sent to DLQ",
-			"dlq_topic", dlqTopic,
+		return err
+	}
+
+	c.logger.Warn("message sent to DLQ",
+		"dlq_topic", dlqTopic,
+		"original_topic", original.Topic,
+		"original_offset", original.Offset,
+	)
+	return nil
+}
+
+// forwardFailure moves a failed record to the next retry tier of its route, or to the
+// DLQ once there is none left. It keeps trying until the record is stored somewhere or
+// ctx ends; a record that was not stored must not be committed.
+func (c *franzClient) forwardFailure(ctx context.Context, original *kgo.Record, handleErr error) bool {
+	backoff := 500 * time.Millisecond
+	for {
+		var err error
+		if next, delay, ok := c.router.NextRetry(original.Topic); ok {
+			err = c.sendToRetry(ctx, original, next, delay, handleErr)
+		} else {
+			err = c.sendToDlq(ctx, original, handleErr)
+		}
+		if err == nil {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// sendToRetry republishes a failed record to a retry topic with a not-before header
+// the delayed consumer waits for.
+func (c *franzClient) sendToRetry(ctx context.Context, original *kgo.Record, topic string, delay time.Duration, handleErr error) error {
+	headers, attempt, notBefore := retryHeaders(headerMap(original.Headers), delay, handleErr)
+
+	rec := &kgo.Record{
+		Topic:   topic,
+		Key:     original.Key,
+		Value:   original.Value,
+		Headers: recordHeaders(headers),
+	}
+	if err := c.producer.ProduceSync(ctx, rec).FirstErr(); err != nil {
+		c.logger.Error("retry produce failed",
+			"retry_topic", topic,
 			"original_topic", original.Topic,
 			"original_offset", original.Offset,
+			"err", err,
 		)
+		return err
 	}
+
+	c.logger.Warn("message scheduled for retry",
+		"retry_topic", topic,
+		"attempt", attempt,
+		"not_before", notBefore,
+	)
+	return nil
+}
+
+// deferPartition rewinds a retry partition to a record that is not due yet and pauses
+// it until then, so waiting never blocks polling or other partitions.
+func (c *franzClient) deferPartition(state *partitionState, rec *kgo.Record, until time.Time) {
+	state.stop(rec)
+
+	partitions := map[string][]int32{rec.Topic: {rec.Partition}}
+	state.setDeferred(true)
+	c.client.PauseFetchPartitions(partitions)
+	c.client.SetOffsets(map[string]map[int32]kgo.EpochOffset{
+		rec.Topic: {rec.Partition: {Epoch: rec.LeaderEpoch, Offset: rec.Offset}},
+	})
+
+	time.AfterFunc(time.Until(until), func() {
+		state.setDeferred(false)
+		c.client.ResumeFetchPartitions(partitions)
+	})
 }
 
 func (c *franzClient) Close() {
 	c.logger.Info("closing consumer")
 	c.client.Close()
-	c.dlq.Close()
+	c.producer.Close()
 	c.logger.Info("consumer closed")
 }
 
-func (c *franzClient) handlePartition(ctx context.Context, p kgo.FetchTopicPartition) {
-	p.EachRecord(func(rec *kgo.Record) {
-		msg := c.toMessage(rec)
-		err := c.router.Route(ctx, msg)
-		if err != nil {
-			c.logger.Error("message routing failed, sending to DLQ",
-				"topic", rec.Topic,
-				"partition", rec.Partition,
-				"offset", rec.Offset,
-				"key", string(rec.Key),
-				"err", err,
-			)
-		}
-
-		c.client.MarkCommitRecords(rec)
-	})
-}
-
-func (c *franzClient) toMessage(rec *kgo.Record) Message {
-	headers := make(map[string]string, len(rec.Headers))
-	for _, h := range rec.Headers {
-		headers[h.Key] = string(h.Value)
-	}
-	return Message{
+// toMessage converts a record to a Message and returns ctx carrying the trace the
+// producer attached through the traceparent header.
+func (c *franzClient) toMessage(ctx context.Context, rec *kgo.Record) (context.Context, Message) {
+	headers := headerMap(rec.Headers)
+	return extractTrace(ctx, headers), Message{
 		Key:     rec.Key,
 		Value:   rec.Value,
 		Headers: headers,
 		Topic:   rec.Topic,
 	}
 }
+
+func headerMap(headers []kgo.RecordHeader) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+func recordHeaders(headers map[string]string) []kgo.RecordHeader {
+	rh := make([]kgo.RecordHeader, 0, len(headers))
+	for k, v := range headers {
+		rh = append(rh, kgo.RecordHeader{Key: k, Value: []byte(v)})
+	}
+	return rh
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DedupStore remembers which messages were already handled successfully.
+type DedupStore interface {
+	Seen(ctx context.Context, key string) (bool, error)
+	MarkProcessed(ctx context.Context, key string) error
+}
+
+type dedupConfig struct {
+	header string
+}
+
+type DedupOption func(*dedupConfig)
+
+// WithDedupHeader picks the header that identifies a message. Defaults to event-id.
+func WithDedupHeader(name string) DedupOption {
+	return func(c *dedupConfig) {
+		c.header = name
+	}
+}
+
+// WithDeduplication skips messages that were already handled, so redelivery after a
+// crash or rebalance does not repeat side effects. Messages are identified per topic
+// by the event-id header (or the one set with WithDedupHeader); messages without it
+// are passed through. A message is only marked once the handler succeeded.
+func WithDeduplication(store DedupStore, opts ...DedupOption) Middleware {
+	cfg := dedupConfig{header: HeaderEventID}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg Message) error {
+			id := msg.Headers[cfg.header]
+			if id == "" {
+				return next(ctx, msg)
+			}
+			key := msg.Topic + ":" + id
+
+			seen, err := store.Seen(ctx, key)
+			if err != nil {
+				return fmt.Errorf("dedup lookup: %w", err)
+			}
+			if seen {
+				return nil
+			}
+
+			if err := next(ctx, msg); err != nil {
+				return err
+			}
+			if err := store.MarkProcessed(ctx, key); err != nil {
+				return fmt.Errorf("dedup mark: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+type redisDedupStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisDedupStore keeps processed keys in Redis for ttl, which should cover the
+// longest redelivery window including retry tiers.
+func NewRedisDedupStore(client *redis.Client, ttl time.Duration) DedupStore {
+	return &redisDedupStore{client: client, ttl: ttl}
+}
+
+func dedupKey(key string) string {
+	return "dedup:" + key
+}
+
+func (s *redisDedupStore) Seen(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Exists(ctx, dedupKey(key)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *redisDedupStore) MarkProcessed(ctx context.Context, key string) error {
+	return s.client.Set(ctx, dedupKey(key), 1, s.ttl).Err()
+}
+
+type mongoDedupStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDedupStore keeps processed keys in collection and creates a TTL index so
+// they are dropped after ttl.
+func NewMongoDedupStore(ctx context.Context, collection *mongo.Collection, ttl time.Duration) (DedupStore, error) {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "processed_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create dedup ttl index: %w", err)
+	}
+	return &mongoDedupStore{collection: collection}, nil
+}
+
+func (s *mongoDedupStore) Seen(ctx context.Context, key string) (bool, error) {
+	err := s.collection.FindOne(ctx, bson.M{"_id": key}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *mongoDedupStore) MarkProcessed(ctx context.Context, key string) error {
+	_, err := s.collection.InsertOne(ctx, bson.M{"_id": key, "processed_at": time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
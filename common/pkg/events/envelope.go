@@ -0,0 +1,168 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+)
+
+// Envelope headers. The envelope travels in Message.Headers so the payload can be
+// either JSON or protobuf.
+const (
+	HeaderEventID       = "event-id"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+	HeaderOccurredAt    = "occurred-at"
+	HeaderProducer      = "source"
+	HeaderContentType   = "content-type"
+	HeaderCorrelationID = "correlation-id"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Event is implemented by every payload that goes through Publish and Handle. Bump
+// SchemaVersion whenever the payload shape changes; the schema registry decides
+// whether the change is allowed.
+type Event interface {
+	EventType() string
+	SchemaVersion() int
+}
+
+// Envelope is a decoded event together with its metadata.
+type Envelope[T Event] struct {
+	ID            string
+	Type          string
+	SchemaVersion int
+	OccurredAt    time.Time
+	Producer      string
+	CorrelationID string
+	Data          T
+}
+
+// Encode wraps data in an envelope and returns the message to publish. Protobuf
+// messages are encoded as protobuf, everything else as JSON. It is exposed for callers
+// that store messages before publishing them, such as an outbox.
+func Encode[T Event](producer, key string, data T) (Message, error) {
+	var (
+		payload     []byte
+		contentType string
+		err         error
+	)
+	if pm, ok := any(data).(proto.Message); ok {
+		payload, err = proto.Marshal(pm)
+		contentType = ContentTypeProtobuf
+	} else {
+		payload, err = json.Marshal(data)
+		contentType = ContentTypeJSON
+	}
+	if err != nil {
+		return Message{}, fmt.Errorf("encode %s: %w", data.EventType(), err)
+	}
+
+	return Message{
+		Key:   []byte(key),
+		Value: payload,
+		Headers: map[string]string{
+			HeaderEventID:       uuid.New().String(),
+			HeaderEventType:     data.EventType(),
+			HeaderSchemaVersion: strconv.Itoa(data.SchemaVersion()),
+			HeaderOccurredAt:    time.Now().UTC().Format(time.RFC3339Nano),
+			HeaderProducer:      producer,
+			HeaderContentType:   contentType,
+			HeaderCorrelationID: key,
+		},
+	}, nil
+}
+
+// Publish encodes data and publishes it to the topic named after its event type.
+func Publish[T Event](ctx context.Context, p Producer, producer, key string, data T) error {
+	msg, err := Encode(producer, key, data)
+	if err != nil {
+		return err
+	}
+	return p.Publish(ctx, data.EventType(), msg)
+}
+
+// Decode turns a message back into a typed envelope. It rejects messages of another
+// event type and messages written with a newer schema version than T knows about.
+// Messages without envelope headers are read as version 1 JSON.
+func Decode[T Event](msg Message) (Envelope[T], error) {
+	env := Envelope[T]{Data: newEvent[T]()}
+	want := env.Data.EventType()
+
+	env.ID = msg.Headers[HeaderEventID]
+	env.Type = msg.Headers[HeaderEventType]
+	env.Producer = msg.Headers[HeaderProducer]
+	env.CorrelationID = msg.Headers[HeaderCorrelationID]
+	if env.Type == "" {
+		env.Type = want
+	}
+	if env.Type != want {
+		return env, fmt.Errorf("decode event: got type %q, want %q", env.Type, want)
+	}
+
+	env.SchemaVersion = 1
+	if v := msg.Headers[HeaderSchemaVersion]; v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return env, fmt.Errorf("decode %s: bad schema version %q", want, v)
+		}
+		env.SchemaVersion = version
+	}
+	if env.SchemaVersion > env.Data.SchemaVersion() {
+		return env, fmt.Errorf("decode %s: schema version %d is newer than supported %d", want, env.SchemaVersion, env.Data.SchemaVersion())
+	}
+
+	if at := msg.Headers[HeaderOccurredAt]; at != "" {
+		if t, err := time.Parse(time.RFC3339Nano, at); err == nil {
+			env.OccurredAt = t
+		}
+	}
+
+	var err error
+	switch msg.Headers[HeaderContentType] {
+	case ContentTypeProtobuf:
+		pm, ok := any(env.Data).(proto.Message)
+		if !ok {
+			return env, fmt.Errorf("decode %s: protobuf payload for a non-protobuf type", want)
+		}
+		err = proto.Unmarshal(msg.Value, pm)
+	default:
+		err = json.Unmarshal(msg.Value, &env.Data)
+	}
+	if err != nil {
+		return env, fmt.Errorf("decode %s: %w", want, err)
+	}
+	return env, nil
+}
+
+// newEvent returns a usable zero T. Generated protobuf types are pointers, which
+// need allocating before they can be unmarshalled into.
+func newEvent[T Event]() T {
+	var zero T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero
+}
+
+// Handle adapts a typed handler to a MessageHandler for Router.Handle.
+func Handle[T Event](fn func(ctx context.Context, env Envelope[T]) error) MessageHandler {
+	return func(ctx context.Context, msg Message) error {
+		env, err := Decode[T](msg)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, env)
+	}
+}
@@ -0,0 +1,130 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// protoEvent is a protobuf message event, as generated types are pointers.
+type protoEvent struct {
+	wrapperspb.StringValue
+}
+
+func (*protoEvent) EventType() string  { return "test.proto" }
+func (*protoEvent) SchemaVersion() int { return 2 }
+
+// pointerEvent is a pointer event that is not a protobuf message.
+type pointerEvent struct {
+	Name string `json:"name"`
+}
+
+func (*pointerEvent) EventType() string  { return "test.pointer" }
+func (*pointerEvent) SchemaVersion() int { return 1 }
+
+func TestEncodeDecodeProtobuf(t *testing.T) {
+	data := &protoEvent{}
+	data.Value = "seat A-1-3"
+
+	msg, err := Encode("test-service", "key-1", data)
+	if err != nil {
+		t.Fatalf("Encode() = %v", err)
+	}
+	if got := msg.Headers[HeaderContentType]; got != ContentTypeProtobuf {
+		t.Fatalf("content type = %q, want %q", got, ContentTypeProtobuf)
+	}
+
+	env, err := Decode[*protoEvent](msg)
+	if err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if env.Data.GetValue() != "seat A-1-3" {
+		t.Fatalf("decoded value = %q, want %q", env.Data.GetValue(), "seat A-1-3")
+	}
+	if env.Type != "test.proto" || env.SchemaVersion != 2 {
+		t.Fatalf("decoded %s v%d, want test.proto v2", env.Type, env.SchemaVersion)
+	}
+
+	// a protobuf payload cannot be read into a JSON event
+	if _, err := Decode[*pointerEvent](withHeader(msg, HeaderEventType, "test.pointer")); err == nil {
+		t.Fatal("Decode() of a protobuf payload into a JSON event succeeded")
+	}
+}
+
+func TestEnvelopeHeaders(t *testing.T) {
+	before := time.Now().UTC()
+	msg, err := Encode("booking-service", "b-1", BookingCreatedEvent{BookingID: "b-1", SeatNumber: "A-1-1"})
+	if err != nil {
+		t.Fatalf("Encode() = %v", err)
+	}
+
+	for header, want := range map[string]string{
+		HeaderEventType:     "booking.created",
+		HeaderSchemaVersion: "1",
+		HeaderProducer:      "booking-service",
+		HeaderContentType:   ContentTypeJSON,
+		HeaderCorrelationID: "b-1",
+	} {
+		if got := msg.Headers[header]; got != want {
+			t.Errorf("header %s = %q, want %q", header, got, want)
+		}
+	}
+	if msg.Headers[HeaderEventID] == "" {
+		t.Error("header event-id is empty")
+	}
+
+	env, err := Decode[BookingCreatedEvent](msg)
+	if err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if env.ID != msg.Headers[HeaderEventID] || env.CorrelationID != "b-1" || env.Producer != "booking-service" {
+		t.Fatalf("envelope = %+v, want the ids of the headers", env)
+	}
+	if env.SchemaVersion != 1 || env.OccurredAt.Before(before.Truncate(time.Microsecond)) {
+		t.Fatalf("envelope version %d at %s, want 1 at or after %s", env.SchemaVersion, env.OccurredAt, before)
+	}
+	if env.Data.BookingID != "b-1" || env.Data.SeatNumber != "A-1-1" {
+		t.Fatalf("decoded data = %+v", env.Data)
+	}
+
+	tests := []struct {
+		name    string
+		msg     Message
+		wantErr bool
+		version int
+	}{
+		{name: "newer schema version", msg: withHeader(msg, HeaderSchemaVersion, "2"), wantErr: true},
+		{name: "bad schema version", msg: withHeader(msg, HeaderSchemaVersion, "one"), wantErr: true},
+		{name: "other event type", msg: withHeader(msg, HeaderEventType, "ticket.reservation.expired"), wantErr: true},
+		{name: "without envelope headers", msg: Message{Value: msg.Value}, version: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := Decode[BookingCreatedEvent](tt.msg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Decode() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() = %v", err)
+			}
+			if env.SchemaVersion != tt.version || env.Data.BookingID != "b-1" {
+				t.Fatalf("Decode() = v%d %+v", env.SchemaVersion, env.Data)
+			}
+		})
+	}
+}
+
+// withHeader returns a copy of msg with header set to value.
+func withHeader(msg Message, header, value string) Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[header] = value
+	msg.Headers = headers
+	return msg
+}
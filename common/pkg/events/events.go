@@ -1,6 +1,9 @@
 package events
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type BookingCreatedEvent struct {
 	BookingID    string    `json:"booking_id"`
@@ -11,3 +14,38 @@ type BookingCreatedEvent struct {
 	CategoryType string    `json:"category_type"`
 	OccurredAt   time.Time `json:"occurred_at"`
 }
+
+// TicketReservationExpiredEvent is published when a RESERVED seat lapses back to
+// AVAILABLE because nobody completed the booking in time.
+type TicketReservationExpiredEvent struct {
+	TicketID   int32     `json:"ticket_id"`
+	EventID    int32     `json:"event_id"`
+	EventCatID int32     `json:"event_cat_id"`
+	SeatNumber string    `json:"seat_number"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+func (BookingCreatedEvent) EventType() string  { return "booking.created" }
+func (BookingCreatedEvent) SchemaVersion() int { return 1 }
+
+func (TicketReservationExpiredEvent) EventType() string  { return "ticket.reservation.expired" }
+func (TicketReservationExpiredEvent) SchemaVersion() int { return 1 }
+
+// Schemas lists the current schema of every event the services exchange. New event
+// types must be added here so the registry check covers them.
+func Schemas() ([]Schema, error) {
+	var schemas []Schema
+	var errs []error
+	for _, schemaOf := range []func() (Schema, error){
+		SchemaOf[BookingCreatedEvent],
+		SchemaOf[TicketReservationExpiredEvent],
+	} {
+		s, err := schemaOf()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, errors.Join(errs...)
+}
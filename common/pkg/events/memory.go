@@ -0,0 +1,299 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrGroupActive is returned by a memory consumer whose group is already consuming.
+var ErrGroupActive = errors.New("consumer group already active")
+
+// MemoryBroker is an in-process stand-in for Kafka. It is a Producer, and
+// MemoryBroker.Consumer returns Consumers that route, retry, dead-letter and commit
+// like the Kafka consumer does, so a flow can be run in go test without a cluster.
+//
+// Every topic has a single partition and a group handles it in offset order.
+type MemoryBroker struct {
+	mu        sync.Mutex
+	topics    map[string][]memoryRecord
+	committed map[string]map[string]int64
+	active    map[string]bool
+	// published is closed and replaced whenever a record is appended
+	published chan struct{}
+}
+
+type memoryRecord struct {
+	msg       Message
+	offset    int64
+	timestamp time.Time
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		topics:    make(map[string][]memoryRecord),
+		committed: make(map[string]map[string]int64),
+		active:    make(map[string]bool),
+		published: make(chan struct{}),
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, msg Message) error {
+	return b.PublishBatch(ctx, topic, []Message{msg})
+}
+
+// PublishBatch appends msgs to topic. Like the Kafka producer it adds the trace
+// context of ctx as a traceparent header unless a message already carries one.
+func (b *MemoryBroker) PublishBatch(ctx context.Context, topic string, msgs []Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, m := range msgs {
+		headers := make(map[string]string, len(m.Headers)+2)
+		injectTrace(ctx, headers)
+		for k, v := range m.Headers {
+			headers[k] = v
+		}
+		b.topics[topic] = append(b.topics[topic], memoryRecord{
+			msg: Message{
+				Key:     m.Key,
+				Value:   m.Value,
+				Headers: headers,
+				Topic:   topic,
+			},
+			offset:    int64(len(b.topics[topic])),
+			timestamp: time.Now(),
+		})
+	}
+	close(b.published)
+	b.published = make(chan struct{})
+	return nil
+}
+
+// PublishAsync publishes synchronously; done is called before it returns.
+func (b *MemoryBroker) PublishAsync(ctx context.Context, topic string, msg Message, done func(error)) {
+	err := b.Publish(ctx, topic, msg)
+	if done != nil {
+		done(err)
+	}
+}
+
+// Close is a no-op, so code under test can close its producer while the test keeps
+// consuming from the broker.
+func (b *MemoryBroker) Close() {}
+
+// Messages returns a copy of everything published to topic, in offset order.
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msgs := make([]Message, len(b.topics[topic]))
+	for i, rec := range b.topics[topic] {
+		msgs[i] = copyMessage(rec.msg)
+	}
+	return msgs
+}
+
+// Committed returns the next offset group will read from topic.
+func (b *MemoryBroker) Committed(group, topic string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[group][topic]
+}
+
+// Consumer returns a consumer for the group. Only DLQSuffix, GroupID, Hooks and
+// Metrics of cfg are used; a group can have one running consumer at a time.
+func (b *MemoryBroker) Consumer(cfg ConsumerConfig, router *Router, logger *slog.Logger) Consumer {
+	if cfg.DLQSuffix == "" {
+		cfg.DLQSuffix = ".dlq"
+	}
+	return &memoryConsumer{
+		broker: b,
+		router: router,
+		logger: logger,
+		cfg:    cfg,
+		closed: make(chan struct{}),
+	}
+}
+
+// next waits for the record at offset of topic.
+func (b *MemoryBroker) next(ctx context.Context, topic string, offset int64) (memoryRecord, bool) {
+	for {
+		b.mu.Lock()
+		records := b.topics[topic]
+		published := b.published
+		b.mu.Unlock()
+
+		if offset < int64(len(records)) {
+			return copyRecord(records[offset]), true
+		}
+		select {
+		case <-published:
+		case <-ctx.Done():
+			return memoryRecord{}, false
+		}
+	}
+}
+
+func (b *MemoryBroker) commit(group, topic string, offset int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.committed[group] == nil {
+		b.committed[group] = make(map[string]int64)
+	}
+	b.committed[group][topic] = offset
+}
+
+func (b *MemoryBroker) lag(topic string, offset int64) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int64(len(b.topics[topic])) - offset
+}
+
+func (b *MemoryBroker) join(group string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active[group] {
+		return false
+	}
+	b.active[group] = true
+	return true
+}
+
+func (b *MemoryBroker) leave(group string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.active, group)
+}
+
+type memoryConsumer struct {
+	broker *MemoryBroker
+	router *Router
+	logger *slog.Logger
+	cfg    ConsumerConfig
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Start consumes every routed topic from the group's committed offsets until ctx
+// ends or the consumer is closed.
+func (c *memoryConsumer) Start(ctx context.Context) error {
+	topics := c.router.GetTopics()
+	if len(topics) == 0 {
+		c.logger.Info("no topics defined")
+		return nil
+	}
+	if !c.broker.join(c.cfg.GroupID) {
+		return fmt.Errorf("start consumer %q: %w", c.cfg.GroupID, ErrGroupActive)
+	}
+	defer c.broker.leave(c.cfg.GroupID)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	assigned := make(Partitions, len(topics))
+	for _, topic := range topics {
+		assigned[topic] = []int32{0}
+	}
+	if c.cfg.Hooks.OnAssigned != nil {
+		c.cfg.Hooks.OnAssigned(ctx, assigned)
+	}
+
+	var wg sync.WaitGroup
+	for _, topic := range topics {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.consume(ctx, topic)
+		}()
+	}
+	wg.Wait()
+
+	if c.cfg.Hooks.OnStop != nil {
+		c.cfg.Hooks.OnStop(context.WithoutCancel(ctx))
+	}
+	return nil
+}
+
+// consume handles one topic in offset order, committing after each record that was
+// handled or forwarded to a retry topic or the DLQ.
+func (c *memoryConsumer) consume(ctx context.Context, topic string) {
+	offset := c.broker.Committed(c.cfg.GroupID, topic)
+	retry := c.router.IsRetryTopic(topic)
+	for {
+		rec, ok := c.broker.next(ctx, topic, offset)
+		if !ok {
+			return
+		}
+		if retry {
+			if wait := time.Until(notBeforeHeader(rec.msg.Headers)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if err := c.router.Route(extractTrace(ctx, rec.msg.Headers), rec.msg); err != nil {
+			c.logger.Error("message routing failed, forwarding to retry/DLQ",
+				"topic", topic,
+				"offset", rec.offset,
+				"key", string(rec.msg.Key),
+				"err", err,
+			)
+			if err := c.forwardFailure(ctx, topic, rec, err); err != nil {
+				return
+			}
+		}
+
+		offset++
+		c.broker.commit(c.cfg.GroupID, topic, offset)
+		c.cfg.Metrics.observeLag(topic, 0, c.broker.lag(topic, offset))
+	}
+}
+
+// forwardFailure moves a failed record to the next retry tier of its route, or to
+// the DLQ, with the same headers the Kafka consumer writes.
+func (c *memoryConsumer) forwardFailure(ctx context.Context, topic string, rec memoryRecord, handleErr error) error {
+	msg := Message{Key: rec.msg.Key, Value: rec.msg.Value}
+	if next, delay, ok := c.router.NextRetry(topic); ok {
+		msg.Headers, _, _ = retryHeaders(rec.msg.Headers, delay, handleErr)
+		return c.broker.Publish(ctx, next, msg)
+	}
+
+	baseTopic := c.router.BaseTopic(topic)
+	msg.Headers = dlqHeaders(rec.msg.Headers, baseTopic, 0, rec.offset, handleErr)
+	return c.broker.Publish(ctx, baseTopic+c.cfg.DLQSuffix, msg)
+}
+
+func (c *memoryConsumer) Close() {
+	c.closeOnce.Do(func() { close(c.closed) })
+}
+
+func copyRecord(rec memoryRecord) memoryRecord {
+	rec.msg = copyMessage(rec.msg)
+	return rec
+}
+
+func copyMessage(msg Message) Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	msg.Headers = headers
+	return msg
+}
@@ -34,6 +34,8 @@ func WithLogging(logger *slog.Logger) Middleware {
 	}
 }
 
+// WithRetry retries inline and holds up the partition while it waits, so keep it to a
+// few short attempts and use WithRetryTopics for anything longer.
 func WithRetry(maxRetries int, backoff time.Duration) Middleware {
 	return func(next MessageHandler) MessageHandler {
 		return func(ctx context.Context, msg Message) error {
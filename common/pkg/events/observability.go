@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "ticket-tix/common/pkg/events"
+
+// traceContext carries W3C traceparent/tracestate in Message.Headers. It is used
+// directly rather than through the global propagator, which defaults to a no-op.
+var traceContext = propagation.TraceContext{}
+
+// injectTrace writes the span context of ctx into headers.
+func injectTrace(ctx context.Context, headers map[string]string) {
+	traceContext.Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// extractTrace returns ctx with the remote span context found in headers, if any.
+func extractTrace(ctx context.Context, headers map[string]string) context.Context {
+	return traceContext.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// Metrics holds the consumer side Prometheus collectors. Create it once per
+// registerer and share it between WithMetrics and ConsumerConfig.Metrics.
+type Metrics struct {
+	processed *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	lag       *prometheus.GaugeVec
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "events_messages_processed_total",
+			Help: "Messages handled successfully, by topic.",
+		}, []string{"topic"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "events_messages_failed_total",
+			Help: "Messages whose handler returned an error, by topic.",
+		}, []string{"topic"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "events_handler_duration_seconds",
+			Help:    "Handler latency, by topic.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"topic"}),
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "events_consumer_lag",
+			Help: "High watermark minus the next offset to fetch, by partition.",
+		}, []string{"topic", "partition"}),
+	}
+	reg.MustRegister(m.processed, m.failed, m.duration, m.lag)
+	return m
+}
+
+func (m *Metrics) observeLag(topic string, partition int32, lag int64) {
+	if m == nil {
+		return
+	}
+	m.lag.WithLabelValues(topic, fmt.Sprint(partition)).Set(float64(lag))
+}
+
+func WithMetrics(m *Metrics) Middleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			m.duration.WithLabelValues(msg.Topic).Observe(time.Since(start).Seconds())
+			if err != nil {
+				m.failed.WithLabelValues(msg.Topic).Inc()
+			} else {
+				m.processed.WithLabelValues(msg.Topic).Inc()
+			}
+			return err
+		}
+	}
+}
+
+// WithTracing wraps the handler in a consumer span. The span continues the trace
+// found in the message's traceparent header, so the producer's request and the
+// handler end up in the same trace. A nil provider uses the global one.
+func WithTracing(provider trace.TracerProvider) Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(tracerName)
+
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msg Message) error {
+			ctx = extractTrace(ctx, msg.Headers)
+			ctx, span := tracer.Start(ctx, msg.Topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "kafka"),
+					attribute.String("messaging.destination.name", msg.Topic),
+					attribute.String("messaging.message.id", msg.Headers[HeaderEventID]),
+					attribute.String("messaging.kafka.message.key", string(msg.Key)),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"ticket-tix/common/pkg/middleware"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestTracePropagation follows one trace from a caller through the HTTP tracing
+// middleware, a published event and the consumer's WithTracing span.
+func TestTracePropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	broker := NewMemoryBroker()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.TracingMiddleware("test-service"))
+	r.POST("/bookings", func(c *gin.Context) {
+		msg := Message{Key: []byte("b-1"), Value: []byte(`{}`), Headers: map[string]string{HeaderEventID: "evt-1"}}
+		if err := broker.Publish(c.Request.Context(), "booking.created", msg); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	// the caller's span is sent along as traceparent, like another service would
+	callerCtx, caller := provider.Tracer("caller").Start(context.Background(), "create booking",
+		trace.WithSpanKind(trace.SpanKindClient))
+	req := httptest.NewRequest(http.MethodPost, "/bookings", nil)
+	propagation.TraceContext{}.Inject(callerCtx, propagation.HeaderCarrier(req.Header))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	caller.End()
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /bookings = %d, want %d", w.Code, http.StatusCreated)
+	}
+
+	handled := make(chan trace.SpanContext, 1)
+	router := NewRouter()
+	router.Handle("booking.created", func(ctx context.Context, msg Message) error {
+		handled <- trace.SpanContextFromContext(ctx)
+		return nil
+	}, WithTracing(provider))
+	runConsumer(t, broker, "test", router, func() bool {
+		return broker.Committed("test", "booking.created") == 1
+	})
+
+	spans := spansByName(recorder.Ended())
+	server, ok := spans["POST /bookings"]
+	if !ok {
+		t.Fatalf("no server span recorded, got %v", keys(spans))
+	}
+	consumer, ok := spans["booking.created process"]
+	if !ok {
+		t.Fatalf("no consumer span recorded, got %v", keys(spans))
+	}
+
+	traceID := caller.SpanContext().TraceID()
+	if server.SpanContext().TraceID() != traceID || server.Parent().SpanID() != caller.SpanContext().SpanID() {
+		t.Errorf("server span does not continue the caller's traceparent")
+	}
+	if consumer.SpanContext().TraceID() != traceID {
+		t.Errorf("consumer span trace = %s, want %s", consumer.SpanContext().TraceID(), traceID)
+	}
+	if consumer.Parent().SpanID() != server.SpanContext().SpanID() || !consumer.Parent().IsRemote() {
+		t.Errorf("consumer span parent = %s, want the remote server span %s",
+			consumer.Parent().SpanID(), server.SpanContext().SpanID())
+	}
+	if consumer.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("consumer span kind = %s, want consumer", consumer.SpanKind())
+	}
+	if got := <-handled; got.SpanID() != consumer.SpanContext().SpanID() {
+		t.Errorf("handler context span = %s, want the consumer span %s", got.SpanID(), consumer.SpanContext().SpanID())
+	}
+}
+
+func TestInjectTrace(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+
+	t.Run("from context", func(t *testing.T) {
+		rec := (&franzKafka{}).toRecord(ctx, "booking.created", Message{Key: []byte("b-1")})
+		headers := make(map[string]string)
+		for _, h := range rec.Headers {
+			headers[h.Key] = string(h.Value)
+		}
+		got := trace.SpanContextFromContext(extractTrace(context.Background(), headers))
+		if got.TraceID() != span.SpanContext().TraceID() || got.SpanID() != span.SpanContext().SpanID() {
+			t.Fatalf("extracted span context %v, want %v", got, span.SpanContext())
+		}
+	})
+
+	t.Run("message header wins", func(t *testing.T) {
+		// an outbox row keeps the traceparent of the request that wrote it
+		_, stored := provider.Tracer("test").Start(context.Background(), "stored")
+		stored.End()
+		headers := make(map[string]string)
+		injectTrace(trace.ContextWithSpan(context.Background(), stored), headers)
+
+		broker := NewMemoryBroker()
+		if err := broker.Publish(ctx, "booking.created", Message{Headers: headers}); err != nil {
+			t.Fatalf("Publish() = %v", err)
+		}
+		published := broker.Messages("booking.created")[0]
+		got := trace.SpanContextFromContext(extractTrace(context.Background(), published.Headers))
+		if got.TraceID() != stored.SpanContext().TraceID() {
+			t.Fatalf("published trace = %s, want the stored %s", got.TraceID(), stored.SpanContext().TraceID())
+		}
+	})
+
+	t.Run("no trace", func(t *testing.T) {
+		headers := make(map[string]string)
+		injectTrace(context.Background(), headers)
+		if len(headers) != 0 {
+			t.Fatalf("headers = %v, want none without a span", headers)
+		}
+		if sc := trace.SpanContextFromContext(extractTrace(context.Background(), headers)); sc.IsValid() {
+			t.Fatalf("extracted %v from empty headers", sc)
+		}
+	})
+}
+
+// runConsumer consumes from broker with group until done reports true, then stops.
+func runConsumer(t *testing.T, broker *MemoryBroker, group string, router *Router, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumer := broker.Consumer(ConsumerConfig{GroupID: group}, router, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	stopped := make(chan error, 1)
+	go func() { stopped <- consumer.Start(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("consumer did not finish in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	consumer.Close()
+	if err := <-stopped; err != nil {
+		t.Fatalf("consumer Start() = %v", err)
+	}
+}
+
+func spansByName(spans []sdktrace.ReadOnlySpan) map[string]sdktrace.ReadOnlySpan {
+	byName := make(map[string]sdktrace.ReadOnlySpan, len(spans))
+	for _, s := range spans {
+		byName[s.Name()] = s
+	}
+	return byName
+}
+
+func keys(spans map[string]sdktrace.ReadOnlySpan) []string {
+	names := make([]string, 0, len(spans))
+	for name := range spans {
+		names = append(names, name)
+	}
+	return names
+}
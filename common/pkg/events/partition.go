@@ -0,0 +1,184 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionState tracks the records of one partition that were handed to workers.
+// Workers finish records out of order (different keys run in parallel), so only the
+// contiguous prefix of finished records is committable.
+type partitionState struct {
+	tp topicPartition
+
+	mu      sync.Mutex
+	pending []*kgo.Record
+	done    map[int64]bool
+	// stopAt is the first offset that must not be handled in this session, set when a
+	// retry partition is deferred or a record could not be stored; -1 when unset.
+	stopAt int64
+	// head is the next offset to commit and commitEpoch its leader epoch.
+	head        int64
+	commitEpoch int32
+	committed   int64
+	paused      bool
+	// deferred is set while deferPartition holds the partition paused.
+	deferred bool
+	drained  chan struct{}
+}
+
+func newPartitionState(tp topicPartition) *partitionState {
+	return &partitionState{
+		tp:        tp,
+		done:      make(map[int64]bool),
+		stopAt:    -1,
+		head:      -1,
+		committed: -1,
+	}
+}
+
+// add queues a fetched record and returns how many records are now in flight. It
+// refuses records past a stop point until the partition is fetched again from it.
+func (s *partitionState) add(rec *kgo.Record) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.stopAt < 0:
+	case rec.Offset > s.stopAt:
+		return len(s.pending), false
+	case rec.Offset == s.stopAt:
+		// fetched again from the rewind point; the stop no longer applies
+		s.stopAt = -1
+	}
+	s.pending = append(s.pending, rec)
+	return len(s.pending), true
+}
+
+// skip reports whether a queued record should be dropped instead of handled.
+func (s *partitionState) skip(rec *kgo.Record) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopAt >= 0 && rec.Offset >= s.stopAt
+}
+
+// complete marks a record as handled, advances the commit head past every finished
+// record at the front and returns how many records are still in flight.
+func (s *partitionState) complete(rec *kgo.Record) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.done[rec.Offset] = true
+	for len(s.pending) > 0 && s.done[s.pending[0].Offset] {
+		first := s.pending[0]
+		delete(s.done, first.Offset)
+		s.head = first.Offset + 1
+		s.commitEpoch = first.LeaderEpoch
+		s.pending = s.pending[1:]
+	}
+	s.signalDrainedLocked()
+	return len(s.pending)
+}
+
+// stop drops rec and everything after it from this session; they will be fetched
+// again after a rewind or by the next owner of the partition.
+func (s *partitionState) stop(rec *kgo.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopAt < 0 || rec.Offset < s.stopAt {
+		s.stopAt = rec.Offset
+	}
+	kept := s.pending[:0]
+	for _, r := range s.pending {
+		if r.Offset < s.stopAt {
+			kept = append(kept, r)
+		} else {
+			delete(s.done, r.Offset)
+		}
+	}
+	s.pending = kept
+	s.signalDrainedLocked()
+}
+
+// stopAll drops every record that has not finished yet. Records already running
+// finish, but their completion no longer counts.
+func (s *partitionState) stopAll() {
+	s.mu.Lock()
+	first := (*kgo.Record)(nil)
+	if len(s.pending) > 0 {
+		first = s.pending[0]
+	}
+	s.mu.Unlock()
+
+	if first != nil {
+		s.stop(first)
+	}
+}
+
+// commitPoint returns the offset to commit, if it moved since the last commit.
+func (s *partitionState) commitPoint() (kgo.EpochOffset, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.head < 0 || s.head == s.committed {
+		return kgo.EpochOffset{}, false
+	}
+	return kgo.EpochOffset{Epoch: s.commitEpoch, Offset: s.head}, true
+}
+
+func (s *partitionState) markCommitted(offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if offset > s.committed {
+		s.committed = offset
+	}
+}
+
+// setPaused records the backpressure state and reports whether it changed.
+func (s *partitionState) setPaused(paused bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused == paused {
+		return false
+	}
+	s.paused = paused
+	return true
+}
+
+func (s *partitionState) setDeferred(deferred bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deferred = deferred
+}
+
+// fetchPaused reports whether fetching the partition was paused, for backpressure or
+// by deferPartition.
+func (s *partitionState) fetchPaused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused || s.deferred
+}
+
+// waitDrained returns a channel closed once no records are in flight.
+func (s *partitionState) waitDrained() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.drained == nil {
+		s.drained = make(chan struct{})
+	}
+	ch := s.drained
+	s.signalDrainedLocked()
+	return ch
+}
+
+func (s *partitionState) signalDrainedLocked() {
+	if len(s.pending) == 0 && s.drained != nil {
+		close(s.drained)
+		s.drained = nil
+	}
+}
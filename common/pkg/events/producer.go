@@ -2,25 +2,64 @@ package events
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Producer interface {
 	Publish(ctx context.Context, topic string, msg Message) error
 	PublishBatch(ctx context.Context, topic string, msgs []Message) error
+	// PublishAsync queues msg and returns at once. done is called with the outcome
+	// once the message is stored or has failed.
+	PublishAsync(ctx context.Context, topic string, msg Message, done func(error))
 	Close()
 }
 
+// Acks is how many replicas must store a record before a publish succeeds.
+type Acks int
+
+const (
+	AcksAll Acks = iota
+	AcksLeader
+	AcksNone
+)
+
+// Compression is the codec used for produced batches.
+type Compression string
+
+const (
+	CompressionNone   Compression = "none"
+	CompressionGzip   Compression = "gzip"
+	CompressionSnappy Compression = "snappy"
+	CompressionLZ4    Compression = "lz4"
+	CompressionZstd   Compression = "zstd"
+)
+
 type ProducerConfig struct {
-	Brokers        []string
-	MaxRetries     int
-	RetryBackoff   time.Duration
-	MaxBatchBytes  int32
+	Brokers       []string
+	MaxRetries    int
+	RetryBackoff  time.Duration
+	MaxBatchBytes int32
+	// ProduceTimeout bounds a whole Publish, retries included.
 	ProduceTimeout time.Duration
+	Acks           Acks
+	// Idempotent makes retries unable to duplicate or reorder records. It needs AcksAll.
+	Idempotent  bool
+	Compression Compression
+	Breaker     BreakerConfig
+	// SpoolDir, when set, keeps messages that could not be published on local disk
+	// and replays them in order once Kafka is reachable again. A spooled message
+	// counts as published.
+	SpoolDir            string
+	SpoolReplayInterval time.Duration
 }
 
 func GetDefaultConfig(brokers []string) ProducerConfig {
@@ -30,69 +69,141 @@ func GetDefaultConfig(brokers []string) ProducerConfig {
 		MaxRetries:     3, // ✅ Reduced for dev
 		RetryBackoff:   100 * time.Millisecond,
 		ProduceTimeout: 10 * time.Second,
+		Acks:           AcksAll,
+		Idempotent:     true,
+		Compression:    CompressionSnappy,
+		Breaker: BreakerConfig{
+			FailureThreshold: 5,
+			OpenTimeout:      30 * time.Second,
+		},
+		SpoolReplayInterval: 5 * time.Second,
 	}
 }
 
 type franzKafka struct {
-	client *kgo.Client
+	client  *kgo.Client
+	cfg     ProducerConfig
+	breaker *breaker
+	spool   *diskSpool
+
+	stopReplay chan struct{}
+	replayDone chan struct{}
 }
 
 func NewProducer(cfg ProducerConfig) (Producer, error) {
-	client, err := kgo.NewClient(
-		kgo.SeedBrokers(cfg.Brokers...),
-		kgo.ProducerBatchMaxBytes(cfg.MaxBatchBytes),
+	opts, err := cfg.clientOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		fmt.Println("failed to create Kafka producer:", err)
+		return nil, err
+	}
+
+	pingCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := client.Ping(pingCtx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+	}
+	log.Println("✅ Kafka producer connected successfully")
 
-		//Use LeaderAck for single-node dev
-		kgo.RequiredAcks(kgo.LeaderAck()),
-		kgo.DisableIdempotentWrite(),
+	p := &franzKafka{
+		client:  client,
+		cfg:     cfg,
+		breaker: newBreaker(cfg.Breaker),
+	}
 
-		////Multi Node
-		//kgo.RequiredAcks(kgo.AllISRAcks()),
-		////kgo.DisableIdempotentWrite(),
+	if cfg.SpoolDir != "" {
+		p.spool, err = openSpool(cfg.SpoolDir)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		if cfg.SpoolReplayInterval <= 0 {
+			p.cfg.SpoolReplayInterval = 5 * time.Second
+		}
+		p.stopReplay = make(chan struct{})
+		p.replayDone = make(chan struct{})
+		go p.runReplay()
+	}
+	return p, nil
+}
 
+func (cfg ProducerConfig) clientOptions() ([]kgo.Opt, error) {
+	if cfg.ProduceTimeout <= 0 {
+		return nil, fmt.Errorf("producer config: ProduceTimeout must be positive")
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ProducerBatchMaxBytes(cfg.MaxBatchBytes),
 		kgo.ProduceRequestTimeout(cfg.ProduceTimeout),
+		kgo.RecordDeliveryTimeout(cfg.ProduceTimeout),
 		kgo.RetryBackoffFn(func(attempt int) time.Duration {
 			return cfg.RetryBackoff * time.Duration(1<<uint(attempt-1))
 		}),
 		kgo.RecordRetries(cfg.MaxRetries),
-		kgo.MetadataMaxAge(30*time.Second),
+		kgo.MetadataMaxAge(30 * time.Second),
 
 		// Logger
 		//kgo.WithLogger(kgo.BasicLogger(log.Writer(), kgo.LogLevelDebug, nil)),
-	)
-
-	if err != nil {
-		fmt.Println("failed to create Kafka producer:", err)
-		return nil, err
 	}
 
-	pingCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-	if err := client.Ping(pingCtx); err != nil {
-		client.Close()
-		return nil, fmt.Errorf("failed to connect to Kafka: %w", err)
+	switch cfg.Acks {
+	case AcksAll:
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	case AcksLeader:
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()))
+	case AcksNone:
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()))
+	default:
+		return nil, fmt.Errorf("producer config: unknown acks %d", cfg.Acks)
+	}
+	if cfg.Idempotent && cfg.Acks != AcksAll {
+		return nil, fmt.Errorf("producer config: idempotent writes need AcksAll")
+	}
+	if !cfg.Idempotent {
+		opts = append(opts, kgo.DisableIdempotentWrite())
 	}
-	log.Println("✅ Kafka producer connected successfully")
 
-	return &franzKafka{client: client}, nil
+	switch cfg.Compression {
+	case "", CompressionNone:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.NoCompression()))
+	case CompressionGzip:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.GzipCompression()))
+	case CompressionSnappy:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.SnappyCompression()))
+	case CompressionLZ4:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.Lz4Compression()))
+	case CompressionZstd:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.ZstdCompression()))
+	default:
+		return nil, fmt.Errorf("producer config: unknown compression %q", cfg.Compression)
+	}
+	return opts, nil
 }
 
 func (r *franzKafka) Publish(ctx context.Context, topic string, msg Message) error {
-	kafkaCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	kafkaCtx, cancel := context.WithTimeout(ctx, r.cfg.ProduceTimeout)
 	defer cancel()
 
-	rec := r.toRecord(topic, msg)
+	kafkaCtx, span := startPublishSpan(kafkaCtx, topic)
+	defer span.End()
 
-	result := r.client.ProduceSync(kafkaCtx, rec)
-	if err := result.FirstErr(); err != nil {
+	if err := r.produce(kafkaCtx, r.toRecord(kafkaCtx, topic, msg)); err != nil {
 		fmt.Println("failed to publish message:", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 	return nil
 }
 
 func (r *franzKafka) PublishBatch(ctx context.Context, topic string, msgs []Message) error {
-	kafkaCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	kafkaCtx, cancel := context.WithTimeout(ctx, r.cfg.ProduceTimeout)
 	defer cancel()
 
 	if len(msgs) == 0 {
@@ -101,19 +212,154 @@ func (r *franzKafka) PublishBatch(ctx context.Context, topic string, msgs []Mess
 
 	records := make([]*kgo.Record, len(msgs))
 	for i, m := range msgs {
-		records[i] = r.toRecord(topic, m)
+		records[i] = r.toRecord(kafkaCtx, topic, m)
 	}
 
-	result := r.client.ProduceSync(kafkaCtx, records...)
-	if err := result.FirstErr(); err != nil {
+	if err := r.produce(kafkaCtx, records...); err != nil {
 		fmt.Println("failed to publish batch messages:", err)
 		return err
 	}
 	return nil
 }
 
+// PublishAsync hands msg to the client's buffer. The record is bound to ctx, so
+// cancelling ctx before delivery fails it; done may run on a client goroutine.
+func (r *franzKafka) PublishAsync(ctx context.Context, topic string, msg Message, done func(error)) {
+	if done == nil {
+		done = func(error) {}
+	}
+	ctx, span := startPublishSpan(ctx, topic)
+	rec := r.toRecord(ctx, topic, msg)
+
+	finish := func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		done(err)
+	}
+
+	if r.spool.size() > 0 {
+		// keep order behind what is already waiting on disk
+		finish(r.spool.write(rec))
+		return
+	}
+	if err := r.breaker.allow(); err != nil {
+		finish(r.spoolOr(err, rec))
+		return
+	}
+
+	r.client.Produce(ctx, rec, func(rec *kgo.Record, err error) {
+		r.breaker.record(err)
+		if err != nil {
+			err = r.spoolOr(err, rec)
+		}
+		finish(err)
+	})
+}
+
+// produce publishes records synchronously through the breaker. Records that could
+// not be published go to the spool when there is one.
+func (r *franzKafka) produce(ctx context.Context, records ...*kgo.Record) error {
+	if r.spool.size() > 0 {
+		// keep order behind what is already waiting on disk
+		return r.spool.write(records...)
+	}
+	if err := r.breaker.allow(); err != nil {
+		return r.spoolOr(err, records...)
+	}
+
+	results := r.client.ProduceSync(ctx, records...)
+	err := results.FirstErr()
+	r.breaker.record(err)
+	if err == nil {
+		return nil
+	}
+
+	var failed []*kgo.Record
+	for _, res := range results {
+		if res.Err != nil {
+			failed = append(failed, res.Record)
+		}
+	}
+	return r.spoolOr(err, failed...)
+}
+
+// spoolOr writes records to the spool, or returns err when there is no spool.
+func (r *franzKafka) spoolOr(err error, records ...*kgo.Record) error {
+	if r.spool == nil {
+		return err
+	}
+	if spoolErr := r.spool.write(records...); spoolErr != nil {
+		return errors.Join(err, spoolErr)
+	}
+	log.Printf("spooled %d message(s) after publish failure: %v", len(records), err)
+	return nil
+}
+
+func (r *franzKafka) runReplay() {
+	defer close(r.replayDone)
+	ticker := time.NewTicker(r.cfg.SpoolReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stopReplay:
+			return
+		case <-ticker.C:
+			r.replaySpool()
+		}
+	}
+}
+
+// replaySpool publishes spooled records oldest first and stops at the first failure,
+// so their order is kept.
+func (r *franzKafka) replaySpool() {
+	if r.spool.size() == 0 {
+		return
+	}
+	files, err := r.spool.files()
+	if err != nil {
+		log.Printf("failed to list spool: %v", err)
+		return
+	}
+
+	for _, file := range files {
+		if r.breaker.allow() != nil {
+			return
+		}
+		rec, err := r.spool.read(file)
+		if err != nil {
+			log.Printf("skipping spooled record: %v", err)
+			if err := r.spool.quarantine(file); err != nil {
+				log.Printf("%v", err)
+				return
+			}
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.cfg.ProduceTimeout)
+		err = r.client.ProduceSync(ctx, rec).FirstErr()
+		cancel()
+		r.breaker.record(err)
+		if err != nil {
+			log.Printf("spool replay paused: %v", err)
+			return
+		}
+		if err := r.spool.remove(file); err != nil {
+			log.Printf("%v", err)
+			return
+		}
+	}
+	log.Printf("spool replayed %d message(s)", len(files))
+}
+
 func (r *franzKafka) Close() {
 	log.Println("🔌 Closing Kafka producer...")
+	if r.stopReplay != nil {
+		close(r.stopReplay)
+		<-r.replayDone
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	r.client.Flush(ctx)
@@ -121,18 +367,29 @@ func (r *franzKafka) Close() {
 	log.Println("✅ Kafka producer closed")
 }
 
-func (r *franzKafka) toRecord(topic string, msg Message) *kgo.Record {
-	rec := &kgo.Record{
-		Topic: topic,
-		Key:   msg.Key,
-		Value: msg.Value,
+// toRecord converts msg to a Kafka record. The trace context of ctx is added as a
+// traceparent header unless msg already carries one, e.g. from an outbox row.
+func (r *franzKafka) toRecord(ctx context.Context, topic string, msg Message) *kgo.Record {
+	headers := make(map[string]string, len(msg.Headers)+2)
+	injectTrace(ctx, headers)
+	for k, v := range msg.Headers {
+		headers[k] = v
 	}
 
-	for k, v := range msg.Headers {
-		rec.Headers = append(rec.Headers, kgo.RecordHeader{
-			Key:   k,
-			Value: []byte(v),
-		})
+	return &kgo.Record{
+		Topic:   topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: recordHeaders(headers),
 	}
-	return rec
+}
+
+func startPublishSpan(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
 }
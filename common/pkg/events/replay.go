@@ -0,0 +1,324 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Headers set on records in the dead letter queue.
+const (
+	HeaderOriginalTopic     = "original-topic"
+	HeaderOriginalPartition = "original-partition"
+	HeaderOriginalOffset    = "original-offset"
+	HeaderError             = "error"
+	HeaderReplayCount       = "replay-count"
+)
+
+// Headers set on records in a retry topic.
+const (
+	// HeaderNotBefore holds the unix milliseconds before which the record must not be handled.
+	HeaderNotBefore    = "not-before"
+	HeaderRetryAttempt = "retry-attempt"
+	HeaderRetryError   = "retry-error"
+)
+
+func isRetryHeader(key string) bool {
+	switch key {
+	case HeaderNotBefore, HeaderRetryAttempt, HeaderRetryError:
+		return true
+	}
+	return false
+}
+
+// retryHeaders returns headers for a record moving to a retry tier that is due after
+// delay. Retry headers from an earlier tier are replaced and the attempt counted on.
+func retryHeaders(headers map[string]string, delay time.Duration, handleErr error) (map[string]string, int, time.Time) {
+	attempt := 1
+	if n, err := strconv.Atoi(headers[HeaderRetryAttempt]); err == nil {
+		attempt = n + 1
+	}
+	out := make(map[string]string, len(headers)+3)
+	for k, v := range headers {
+		if !isRetryHeader(k) {
+			out[k] = v
+		}
+	}
+	notBefore := time.Now().Add(delay)
+	out[HeaderNotBefore] = strconv.FormatInt(notBefore.UnixMilli(), 10)
+	out[HeaderRetryAttempt] = strconv.Itoa(attempt)
+	out[HeaderRetryError] = handleErr.Error()
+	return out, attempt, notBefore
+}
+
+// notBeforeHeader reads the not-before header of a retry record.
+func notBeforeHeader(headers map[string]string) time.Time {
+	if ms, err := strconv.ParseInt(headers[HeaderNotBefore], 10, 64); err == nil {
+		return time.UnixMilli(ms)
+	}
+	return time.Time{}
+}
+
+func isDLQHeader(key string) bool {
+	switch key {
+	case HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset, HeaderError:
+		return true
+	}
+	return false
+}
+
+// dlqHeaders returns headers for a record moving to the DLQ. Diagnostic and retry
+// headers from an earlier trip are replaced, everything else (such as replay-count)
+// is kept.
+func dlqHeaders(headers map[string]string, baseTopic string, partition int32, offset int64, handleErr error) map[string]string {
+	out := make(map[string]string, len(headers)+4)
+	for k, v := range headers {
+		if !isDLQHeader(k) && !isRetryHeader(k) {
+			out[k] = v
+		}
+	}
+	out[HeaderOriginalTopic] = baseTopic
+	out[HeaderOriginalPartition] = strconv.Itoa(int(partition))
+	out[HeaderOriginalOffset] = strconv.FormatInt(offset, 10)
+	out[HeaderError] = handleErr.Error()
+	return out
+}
+
+// ReplayFilter selects which DLQ records are replayed. Empty fields match everything.
+type ReplayFilter struct {
+	OriginalTopic string
+	// ErrorContains matches a substring of the error header.
+	ErrorContains string
+	// Headers must all be present with exactly these values.
+	Headers map[string]string
+	// Since skips records written to the DLQ before this time.
+	Since time.Time
+}
+
+func (f ReplayFilter) matches(rec *kgo.Record, headers map[string]string) bool {
+	if f.OriginalTopic != "" && headers[HeaderOriginalTopic] != f.OriginalTopic {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(headers[HeaderError], f.ErrorContains) {
+		return false
+	}
+	for k, v := range f.Headers {
+		if headers[k] != v {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && rec.Timestamp.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+type ReplayConfig struct {
+	Brokers  []string
+	DLQTopic string
+	// ReplayedTopic records which DLQ records were replayed, so that later runs skip
+	// them. Defaults to the DLQ topic with a ".replayed" suffix.
+	ReplayedTopic string
+	Filter        ReplayFilter
+	// MaxReplays is how many times one message may be replayed. A record whose
+	// replay-count has reached it is refused.
+	MaxReplays int
+	// Limit stops after this many replays; 0 means no limit.
+	Limit int
+	// IdleTimeout ends the scan once no new records arrive for this long.
+	IdleTimeout time.Duration
+	DryRun      bool
+}
+
+type ReplayResult struct {
+	Scanned  int
+	Matched  int
+	Replayed int
+	Refused  int
+	// Skipped counts matching records an earlier run replayed already.
+	Skipped int
+}
+
+// ReplayDLQ reads the DLQ topic from the beginning and republishes matching records to
+// their original topic with an incremented replay-count header. Each DLQ record is
+// replayed once: replayed records are written to the ReplayedTopic and skipped by
+// later runs. If the replay fails again, the consumer puts a new record with the higher
+// replay-count in the DLQ, which is what MaxReplays limits.
+func ReplayDLQ(ctx context.Context, cfg ReplayConfig, logger *slog.Logger) (ReplayResult, error) {
+	var res ReplayResult
+	if cfg.DLQTopic == "" {
+		return res, errors.New("dlq topic is required")
+	}
+	if cfg.ReplayedTopic == "" {
+		cfg.ReplayedTopic = cfg.DLQTopic + ".replayed"
+	}
+	if cfg.MaxReplays <= 0 {
+		cfg.MaxReplays = 3
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 5 * time.Second
+	}
+
+	replayed, err := loadReplayed(ctx, cfg)
+	if err != nil {
+		return res, err
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ConsumeTopics(cfg.DLQTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.AllowAutoTopicCreation(),
+	)
+	if err != nil {
+		return res, fmt.Errorf("create replay client: %w", err)
+	}
+	defer client.Close()
+
+	err = scanTopic(ctx, client, cfg.IdleTimeout, func(rec *kgo.Record) (bool, error) {
+		res.Scanned++
+		return replayRecord(ctx, client, cfg, replayed, rec, &res, logger)
+	})
+	return res, err
+}
+
+// replayedKey identifies a DLQ record in the ReplayedTopic.
+func replayedKey(partition int32, offset int64) string {
+	return fmt.Sprintf("%d:%d", partition, offset)
+}
+
+// loadReplayed reads the keys of the DLQ records earlier runs replayed.
+func loadReplayed(ctx context.Context, cfg ReplayConfig) (map[string]bool, error) {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.ConsumeTopics(cfg.ReplayedTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create replayed client: %w", err)
+	}
+	defer client.Close()
+
+	replayed := make(map[string]bool)
+	err = scanTopic(ctx, client, cfg.IdleTimeout, func(rec *kgo.Record) (bool, error) {
+		replayed[string(rec.Key)] = true
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", cfg.ReplayedTopic, err)
+	}
+	return replayed, nil
+}
+
+// scanTopic hands every record to fn until the topic is idle for idle, or fn is done.
+// A topic that does not exist yet reads as empty.
+func scanTopic(ctx context.Context, client *kgo.Client, idle time.Duration, fn func(*kgo.Record) (bool, error)) error {
+	for {
+		pollCtx, cancel := context.WithTimeout(ctx, idle)
+		fetches := client.PollFetches(pollCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := fetches.Err0(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("poll: %w", err)
+		}
+		records := fetches.Records()
+		if len(records) == 0 {
+			// caught up with the end of the topic
+			return nil
+		}
+
+		for _, rec := range records {
+			done, err := fn(rec)
+			if err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+		}
+	}
+}
+
+func replayRecord(ctx context.Context, client *kgo.Client, cfg ReplayConfig, replayed map[string]bool, rec *kgo.Record, res *ReplayResult, logger *slog.Logger) (bool, error) {
+	headers := make(map[string]string, len(rec.Headers))
+	for _, h := range rec.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	if !cfg.Filter.matches(rec, headers) {
+		return false, nil
+	}
+	res.Matched++
+
+	target := headers[HeaderOriginalTopic]
+	if target == "" {
+		logger.Warn("dlq record has no original-topic, skipping", "offset", rec.Offset, "partition", rec.Partition)
+		return false, nil
+	}
+
+	key := replayedKey(rec.Partition, rec.Offset)
+	if replayed[key] {
+		res.Skipped++
+		return false, nil
+	}
+
+	count, _ := strconv.Atoi(headers[HeaderReplayCount])
+	if count >= cfg.MaxReplays {
+		res.Refused++
+		logger.Warn("replay refused, limit reached",
+			"original_topic", target,
+			"dlq_offset", rec.Offset,
+			"replay_count", count,
+			"error", headers[HeaderError],
+		)
+		return false, nil
+	}
+
+	out := &kgo.Record{
+		Topic: target,
+		Key:   rec.Key,
+		Value: rec.Value,
+	}
+	for _, h := range rec.Headers {
+		if isDLQHeader(h.Key) || h.Key == HeaderReplayCount {
+			continue
+		}
+		out.Headers = append(out.Headers, h)
+	}
+	out.Headers = append(out.Headers, kgo.RecordHeader{Key: HeaderReplayCount, Value: []byte(strconv.Itoa(count + 1))})
+
+	if !cfg.DryRun {
+		if err := client.ProduceSync(ctx, out).FirstErr(); err != nil {
+			return false, fmt.Errorf("republish dlq offset %d to %s: %w", rec.Offset, target, err)
+		}
+		// a crash between the two writes replays this record once more on the next run
+		mark := &kgo.Record{
+			Topic: cfg.ReplayedTopic,
+			Key:   []byte(key),
+			Value: []byte(strconv.Itoa(count + 1)),
+		}
+		if err := client.ProduceSync(ctx, mark).FirstErr(); err != nil {
+			return false, fmt.Errorf("record replay of dlq offset %d: %w", rec.Offset, err)
+		}
+		replayed[key] = true
+	}
+	res.Replayed++
+	logger.Info("dlq record replayed",
+		"original_topic", target,
+		"dlq_partition", rec.Partition,
+		"dlq_offset", rec.Offset,
+		"replay_count", count+1,
+		"dry_run", cfg.DryRun,
+	)
+
+	return cfg.Limit > 0 && res.Replayed >= cfg.Limit, nil
+}
@@ -4,46 +4,145 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 )
 
+// DefaultRetryTiers is a sensible ladder for WithRetryTopics.
+var DefaultRetryTiers = []time.Duration{5 * time.Second, time.Minute, 10 * time.Minute}
+
+// RouteOption configures a route in Router.Handle. Every Middleware is a RouteOption,
+// so existing Handle calls that only pass middleware keep working.
+type RouteOption interface {
+	applyRoute(r *routeConfig)
+}
+
+type routeConfig struct {
+	middleware []Middleware
+	retryTiers []time.Duration
+}
+
+func (m Middleware) applyRoute(r *routeConfig) {
+	r.middleware = append(r.middleware, m)
+}
+
+type retryTopicsOption []time.Duration
+
+func (o retryTopicsOption) applyRoute(r *routeConfig) {
+	r.retryTiers = o
+}
+
+// WithRetryTopics makes failed messages of a route go through delayed retry topics,
+// <topic>.retry.<delay>, one per tier, before they reach the DLQ. Unlike WithRetry it
+// does not hold up the partition while waiting.
+func WithRetryTopics(tiers ...time.Duration) RouteOption {
+	return retryTopicsOption(tiers)
+}
+
+// RetryTopic is the name of the retry topic for the given tier delay.
+func RetryTopic(topic string, delay time.Duration) string {
+	var label string
+	switch {
+	case delay%time.Hour == 0:
+		label = fmt.Sprintf("%dh", delay/time.Hour)
+	case delay%time.Minute == 0:
+		label = fmt.Sprintf("%dm", delay/time.Minute)
+	default:
+		label = fmt.Sprintf("%ds", delay/time.Second)
+	}
+	return fmt.Sprintf("%s.retry.%s", topic, label)
+}
+
+// route is what a subscribed topic resolves to. The main topic has tier -1 and each
+// retry topic shares the main topic's handler with its own tier index.
+type route struct {
+	handler MessageHandler
+	base    string
+	tiers   []time.Duration
+	tier    int
+}
+
 type Router struct {
-	mu      sync.RWMutex
-	handler map[string]MessageHandler
+	mu     sync.RWMutex
+	routes map[string]*route
 }
 
 func NewRouter() *Router {
 	return &Router{
-		handler: make(map[string]MessageHandler),
+		routes: make(map[string]*route),
 	}
 }
 
-func (r *Router) Handle(topic string, handler MessageHandler, mw ...Middleware) {
-	for i := len(mw) - 1; i >= 0; i-- {
-		handler = mw[i](handler)
+func (r *Router) Handle(topic string, handler MessageHandler, opts ...RouteOption) {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt.applyRoute(&cfg)
+	}
+
+	for i := len(cfg.middleware) - 1; i >= 0; i-- {
+		handler = cfg.middleware[i](handler)
 	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.handler[topic] = handler
+	r.routes[topic] = &route{handler: handler, base: topic, tiers: cfg.retryTiers, tier: -1}
+	for i, delay := range cfg.retryTiers {
+		r.routes[RetryTopic(topic, delay)] = &route{handler: handler, base: topic, tiers: cfg.retryTiers, tier: i}
+	}
 }
 
+// Route calls the handler for msg.Topic. Messages from a retry topic are handed to the
+// handler under their base topic name.
 func (r *Router) Route(ctx context.Context, msg Message) error {
 	r.mu.RLock()
-	handler, ok := r.handler[msg.Topic]
+	rt, ok := r.routes[msg.Topic]
 	r.mu.RUnlock()
 
 	if !ok {
 		return fmt.Errorf("no handler for topic '%s'", msg.Topic)
 	}
-	return handler(ctx, msg)
+	msg.Topic = rt.base
+	return rt.handler(ctx, msg)
+}
+
+// BaseTopic maps a retry topic back to the topic it retries. Other topics map to
+// themselves.
+func (r *Router) BaseTopic(topic string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if rt, ok := r.routes[topic]; ok {
+		return rt.base
+	}
+	return topic
+}
+
+// IsRetryTopic reports whether topic is one of the delayed retry topics.
+func (r *Router) IsRetryTopic(topic string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.routes[topic]
+	return ok && rt.tier >= 0
+}
+
+// NextRetry returns the retry topic and delay a message that failed on topic should
+// move to. ok is false once every tier is used up and the message belongs in the DLQ.
+func (r *Router) NextRetry(topic string) (next string, delay time.Duration, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rt, found := r.routes[topic]
+	if !found || rt.tier+1 >= len(rt.tiers) {
+		return "", 0, false
+	}
+	delay = rt.tiers[rt.tier+1]
+	return RetryTopic(rt.base, delay), delay, true
 }
 
 func (r *Router) GetTopics() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	topics := make([]string, 0, len(r.handler))
+	topics := make([]string, 0, len(r.routes))
 
-	for topic, _ := range r.handler {
+	for topic, _ := range r.routes {
 		topics = append(topics, topic)
 	}
 	return topics
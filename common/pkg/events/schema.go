@@ -0,0 +1,241 @@
+package events
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrIncompatibleSchema = errors.New("incompatible schema change")
+
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+type Field struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Number int32  `json:"number,omitempty"`
+}
+
+// Schema is the wire shape of one version of an event type.
+type Schema struct {
+	Type     string  `json:"type"`
+	Version  int     `json:"version"`
+	Encoding string  `json:"encoding"`
+	Fields   []Field `json:"fields"`
+}
+
+// SchemaOf derives the schema of T from its JSON tags, or from its descriptor for
+// protobuf messages. Other events must be structs.
+func SchemaOf[T Event]() (Schema, error) {
+	data := newEvent[T]()
+	s := Schema{Type: data.EventType(), Version: data.SchemaVersion()}
+
+	if pm, ok := any(data).(proto.Message); ok {
+		s.Encoding = EncodingProtobuf
+		fields := pm.ProtoReflect().Descriptor().Fields()
+		for i := 0; i < fields.Len(); i++ {
+			f := fields.Get(i)
+			s.Fields = append(s.Fields, Field{
+				Name:   string(f.Name()),
+				Type:   f.Kind().String(),
+				Number: int32(f.Number()),
+			})
+		}
+		return s, nil
+	}
+
+	s.Encoding = EncodingJSON
+	t := reflect.TypeOf(data)
+	if t.Kind() != reflect.Struct {
+		return Schema{}, fmt.Errorf("schema of %s: %s is neither a struct nor a protobuf message", s.Type, t)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Fields = append(s.Fields, Field{Name: name, Type: f.Type.String()})
+	}
+	return s, nil
+}
+
+// CheckCompatible reports whether next can replace prev without breaking consumers
+// of either version: fields may be added, but not removed, retyped or renumbered.
+func CheckCompatible(prev, next Schema) error {
+	if prev.Encoding != next.Encoding {
+		return fmt.Errorf("%w: %s v%d changes encoding %s -> %s", ErrIncompatibleSchema, next.Type, next.Version, prev.Encoding, next.Encoding)
+	}
+
+	byName := make(map[string]Field, len(next.Fields))
+	byNumber := make(map[int32]Field, len(next.Fields))
+	for _, f := range next.Fields {
+		byName[f.Name] = f
+		if f.Number != 0 {
+			byNumber[f.Number] = f
+		}
+	}
+
+	for _, old := range prev.Fields {
+		cur, ok := byName[old.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s v%d removes field %q", ErrIncompatibleSchema, next.Type, next.Version, old.Name)
+		}
+		if cur.Type != old.Type {
+			return fmt.Errorf("%w: %s v%d changes field %q from %s to %s", ErrIncompatibleSchema, next.Type, next.Version, old.Name, old.Type, cur.Type)
+		}
+		if old.Number != 0 {
+			if reused, ok := byNumber[old.Number]; ok && reused.Name != old.Name {
+				return fmt.Errorf("%w: %s v%d reuses field number %d", ErrIncompatibleSchema, next.Type, next.Version, old.Number)
+			}
+			if cur.Number != old.Number {
+				return fmt.Errorf("%w: %s v%d renumbers field %q", ErrIncompatibleSchema, next.Type, next.Version, old.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Registry holds every recorded version of every event type.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string][]Schema
+}
+
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string][]Schema)}
+}
+
+// LoadRegistry reads a registry written by Save.
+func LoadRegistry(r io.Reader) (*Registry, error) {
+	var schemas []Schema
+	if err := json.NewDecoder(r).Decode(&schemas); err != nil {
+		return nil, fmt.Errorf("decode schemas: %w", err)
+	}
+
+	reg := NewRegistry()
+	for _, s := range schemas {
+		reg.schemas[s.Type] = append(reg.schemas[s.Type], s)
+	}
+	for _, versions := range reg.schemas {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+	return reg, nil
+}
+
+// Check compares s with what is recorded. It returns added=true when s is a new type
+// or a new, compatible version, and an error when s changes a recorded version or
+// breaks compatibility with the latest one.
+func (r *Registry) Check(s Schema) (added bool, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := r.schemas[s.Type]
+	if len(versions) == 0 {
+		return true, nil
+	}
+	for _, v := range versions {
+		if v.Version == s.Version {
+			if !reflect.DeepEqual(v, s) {
+				return false, fmt.Errorf("%w: %s v%d changed without a version bump", ErrIncompatibleSchema, s.Type, s.Version)
+			}
+			return false, nil
+		}
+	}
+
+	latest := versions[len(versions)-1]
+	if s.Version < latest.Version {
+		return false, fmt.Errorf("%w: %s v%d is older than recorded v%d", ErrIncompatibleSchema, s.Type, s.Version, latest.Version)
+	}
+	if err := CheckCompatible(latest, s); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Register records s if Check accepts it.
+func (r *Registry) Register(s Schema) error {
+	added, err := r.Check(s)
+	if err != nil || !added {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[s.Type] = append(r.schemas[s.Type], s)
+	return nil
+}
+
+// Save writes every recorded schema, ordered by type and version.
+func (r *Registry) Save(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.schemas))
+	for t := range r.schemas {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+
+	all := make([]Schema, 0)
+	for _, t := range types {
+		all = append(all, r.schemas[t]...)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(all)
+}
+
+//go:embed schemas.json
+var recordedSchemas string
+
+// RecordedRegistry is the registry checked in next to this file.
+func RecordedRegistry() (*Registry, error) {
+	return LoadRegistry(strings.NewReader(recordedSchemas))
+}
+
+// CheckSchemas verifies every event in Schemas against the recorded registry. A new
+// type or version also fails until it is recorded with cmd/schema-check -write.
+func CheckSchemas() error {
+	reg, err := RecordedRegistry()
+	if err != nil {
+		return err
+	}
+
+	schemas, err := Schemas()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range schemas {
+		added, err := reg.Check(s)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if added {
+			errs = append(errs, fmt.Errorf("%s v%d is not recorded in schemas.json", s.Type, s.Version))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,183 @@
+package events
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestCheckSchemas fails when an event type changes without the change being recorded
+// in schemas.json, or when the change breaks a recorded version.
+func TestCheckSchemas(t *testing.T) {
+	if err := CheckSchemas(); err != nil {
+		t.Fatalf("event schemas do not match schemas.json (record compatible changes with go run ./cmd/schema-check -write):\n%v", err)
+	}
+}
+
+func TestSchemaOf(t *testing.T) {
+	s, err := SchemaOf[*protoEvent]()
+	if err != nil {
+		t.Fatalf("SchemaOf[*protoEvent]() = %v", err)
+	}
+	want := Schema{Type: "test.proto", Version: 2, Encoding: EncodingProtobuf, Fields: []Field{{Name: "value", Type: "string", Number: 1}}}
+	if !reflect.DeepEqual(s, want) {
+		t.Fatalf("SchemaOf[*protoEvent]() = %+v, want %+v", s, want)
+	}
+
+	s, err = SchemaOf[BookingCreatedEvent]()
+	if err != nil || s.Encoding != EncodingJSON || s.Fields[0] != (Field{Name: "booking_id", Type: "string"}) {
+		t.Fatalf("SchemaOf[BookingCreatedEvent]() = %+v, %v", s, err)
+	}
+
+	// a pointer that is not a protobuf message has no fields to derive
+	if _, err := SchemaOf[*pointerEvent](); err == nil {
+		t.Fatal("SchemaOf[*pointerEvent]() succeeded, want an error")
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	jsonV1 := Schema{
+		Type:     "booking.created",
+		Version:  1,
+		Encoding: EncodingJSON,
+		Fields: []Field{
+			{Name: "booking_id", Type: "string"},
+			{Name: "user_id", Type: "int32"},
+		},
+	}
+	protoV1 := Schema{
+		Type:     "booking.created",
+		Version:  1,
+		Encoding: EncodingProtobuf,
+		Fields: []Field{
+			{Name: "booking_id", Type: "string", Number: 1},
+			{Name: "user_id", Type: "int32", Number: 2},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		prev    Schema
+		next    Schema
+		wantErr bool
+	}{
+		{
+			name: "unchanged",
+			prev: jsonV1,
+			next: jsonV1,
+		},
+		{
+			name: "added field",
+			prev: jsonV1,
+			next: withFields(jsonV1,
+				Field{Name: "booking_id", Type: "string"},
+				Field{Name: "user_id", Type: "int32"},
+				Field{Name: "seat_number", Type: "string"},
+			),
+		},
+		{
+			name:    "removed field",
+			prev:    jsonV1,
+			next:    withFields(jsonV1, Field{Name: "booking_id", Type: "string"}),
+			wantErr: true,
+		},
+		{
+			name: "retyped field",
+			prev: jsonV1,
+			next: withFields(jsonV1,
+				Field{Name: "booking_id", Type: "string"},
+				Field{Name: "user_id", Type: "int64"},
+			),
+			wantErr: true,
+		},
+		{
+			name:    "changed encoding",
+			prev:    jsonV1,
+			next:    withEncoding(jsonV1, EncodingProtobuf),
+			wantErr: true,
+		},
+		{
+			name: "added protobuf field",
+			prev: protoV1,
+			next: withFields(protoV1,
+				Field{Name: "booking_id", Type: "string", Number: 1},
+				Field{Name: "user_id", Type: "int32", Number: 2},
+				Field{Name: "seat_number", Type: "string", Number: 3},
+			),
+		},
+		{
+			name: "renumbered protobuf field",
+			prev: protoV1,
+			next: withFields(protoV1,
+				Field{Name: "booking_id", Type: "string", Number: 1},
+				Field{Name: "user_id", Type: "int32", Number: 3},
+			),
+			wantErr: true,
+		},
+		{
+			name: "reused protobuf field number",
+			prev: protoV1,
+			next: withFields(protoV1,
+				Field{Name: "booking_id", Type: "string", Number: 2},
+				Field{Name: "user_id", Type: "int32", Number: 1},
+			),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCompatible(tt.prev, tt.next)
+			if tt.wantErr {
+				if !errors.Is(err, ErrIncompatibleSchema) {
+					t.Fatalf("CheckCompatible() = %v, want ErrIncompatibleSchema", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CheckCompatible() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestRegistryCheck(t *testing.T) {
+	v1 := Schema{
+		Type:     "ticket.reservation.expired",
+		Version:  1,
+		Encoding: EncodingJSON,
+		Fields:   []Field{{Name: "ticket_id", Type: "int32"}},
+	}
+	reg := NewRegistry()
+	if err := reg.Register(v1); err != nil {
+		t.Fatalf("Register(v1) = %v", err)
+	}
+
+	// changing a recorded version in place is refused even when compatible
+	edited := withFields(v1, Field{Name: "ticket_id", Type: "int32"}, Field{Name: "seat_number", Type: "string"})
+	if _, err := reg.Check(edited); !errors.Is(err, ErrIncompatibleSchema) {
+		t.Fatalf("Check(edited v1) = %v, want ErrIncompatibleSchema", err)
+	}
+
+	v2 := edited
+	v2.Version = 2
+	added, err := reg.Check(v2)
+	if err != nil || !added {
+		t.Fatalf("Check(v2) = %v, %v, want added", added, err)
+	}
+
+	broken := withFields(v2, Field{Name: "seat_number", Type: "string"})
+	if _, err := reg.Check(broken); !errors.Is(err, ErrIncompatibleSchema) {
+		t.Fatalf("Check(v2 without ticket_id) = %v, want ErrIncompatibleSchema", err)
+	}
+}
+
+func withFields(s Schema, fields ...Field) Schema {
+	s.Fields = fields
+	return s
+}
+
+func withEncoding(s Schema, encoding string) Schema {
+	s.Encoding = encoding
+	return s
+}
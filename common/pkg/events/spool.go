@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// spooledRecord is the on-disk form of a record that could not be published.
+type spooledRecord struct {
+	Topic   string            `json:"topic"`
+	Key     []byte            `json:"key"`
+	Value   []byte            `json:"value"`
+	Headers map[string]string `json:"headers"`
+}
+
+// diskSpool keeps records that could not be published as one file each, named so
+// that they sort in the order they were spooled.
+type diskSpool struct {
+	dir string
+
+	mu      sync.Mutex
+	seq     uint64
+	pending atomic.Int64
+}
+
+func openSpool(dir string) (*diskSpool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	s := &diskSpool{dir: dir}
+
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	s.pending.Store(int64(len(files)))
+	return s, nil
+}
+
+// size is the number of records waiting in the spool.
+func (s *diskSpool) size() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.pending.Load()
+}
+
+// write stores records on disk. Each file is written under a temporary name and
+// renamed, so a crash never leaves a half-written record behind.
+func (s *diskSpool) write(records ...*kgo.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range records {
+		data, err := json.Marshal(spooledRecord{
+			Topic:   rec.Topic,
+			Key:     rec.Key,
+			Value:   rec.Value,
+			Headers: headerMap(rec.Headers),
+		})
+		if err != nil {
+			return fmt.Errorf("encode spooled record: %w", err)
+		}
+
+		s.seq++
+		name := fmt.Sprintf("%020d-%010d.json", time.Now().UnixNano(), s.seq)
+		tmp := filepath.Join(s.dir, name+".tmp")
+		if err := os.WriteFile(tmp, data, 0o644); err != nil {
+			return fmt.Errorf("write spooled record: %w", err)
+		}
+		if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+			return fmt.Errorf("write spooled record: %w", err)
+		}
+		s.pending.Add(1)
+	}
+	return nil
+}
+
+// files lists the spooled records, oldest first.
+func (s *diskSpool) files() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("read spool dir: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (s *diskSpool) read(file string) (*kgo.Record, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read spooled record: %w", err)
+	}
+	var rec spooledRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("decode spooled record %s: %w", filepath.Base(file), err)
+	}
+	return &kgo.Record{
+		Topic:   rec.Topic,
+		Key:     rec.Key,
+		Value:   rec.Value,
+		Headers: recordHeaders(rec.Headers),
+	}, nil
+}
+
+func (s *diskSpool) remove(file string) error {
+	if err := os.Remove(file); err != nil {
+		return fmt.Errorf("remove spooled record: %w", err)
+	}
+	s.pending.Add(-1)
+	return nil
+}
+
+// quarantine moves a record that cannot be decoded out of the way, so it does not
+// hold up the rest of the spool.
+func (s *diskSpool) quarantine(file string) error {
+	if err := os.Rename(file, file+".bad"); err != nil {
+		return fmt.Errorf("quarantine spooled record: %w", err)
+	}
+	s.pending.Add(-1)
+	return nil
+}
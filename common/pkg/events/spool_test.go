@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestSpoolKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := openSpool(dir)
+	if err != nil {
+		t.Fatalf("openSpool() = %v", err)
+	}
+	for i := range 3 {
+		if err := spool.write(spoolRecord(i)); err != nil {
+			t.Fatalf("write() = %v", err)
+		}
+	}
+	if err := spool.write(spoolRecord(3), spoolRecord(4)); err != nil {
+		t.Fatalf("write() = %v", err)
+	}
+
+	// a restarted producer finds the same records in the same order
+	reopened, err := openSpool(dir)
+	if err != nil {
+		t.Fatalf("openSpool() = %v", err)
+	}
+	if reopened.size() != 5 {
+		t.Fatalf("size() after reopen = %d, want 5", reopened.size())
+	}
+	assertSpooled(t, reopened, 0, 1, 2, 3, 4)
+}
+
+// TestProducerSpoolOrder publishes through a producer whose broker is unreachable.
+// Nothing may overtake what is already spooled, and a failed replay keeps it all.
+func TestProducerSpoolOrder(t *testing.T) {
+	client, err := kgo.NewClient(kgo.SeedBrokers("127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("NewClient() = %v", err)
+	}
+	t.Cleanup(client.Close)
+	spool, err := openSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("openSpool() = %v", err)
+	}
+	p := &franzKafka{
+		client:  client,
+		cfg:     ProducerConfig{ProduceTimeout: 200 * time.Millisecond},
+		breaker: newBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute}),
+		spool:   spool,
+	}
+	ctx := context.Background()
+
+	// the first publish fails against the broker and opens the breaker
+	if err := p.Publish(ctx, "test", spoolMessage(0)); err != nil {
+		t.Fatalf("Publish() = %v, want the message spooled", err)
+	}
+	if err := p.breaker.allow(); err == nil {
+		t.Fatal("breaker still closed after a failed publish")
+	}
+	if err := p.PublishBatch(ctx, "test", []Message{spoolMessage(1), spoolMessage(2)}); err != nil {
+		t.Fatalf("PublishBatch() = %v, want the messages spooled", err)
+	}
+
+	// with the breaker closed again, new messages still queue behind the spool
+	p.breaker.record(nil)
+	done := make(chan error, 1)
+	p.PublishAsync(ctx, "test", spoolMessage(3), func(err error) { done <- err })
+	if err := <-done; err != nil {
+		t.Fatalf("PublishAsync() = %v, want the message spooled", err)
+	}
+	assertSpooled(t, spool, 0, 1, 2, 3)
+
+	// the replay fails on the oldest record and stops there
+	p.replaySpool()
+	assertSpooled(t, spool, 0, 1, 2, 3)
+}
+
+func spoolMessage(i int) Message {
+	return Message{Key: []byte("k"), Value: []byte(fmt.Sprint(i))}
+}
+
+func spoolRecord(i int) *kgo.Record {
+	return &kgo.Record{Topic: "test", Key: []byte("k"), Value: []byte(fmt.Sprint(i))}
+}
+
+func assertSpooled(t *testing.T, spool *diskSpool, want ...int) {
+	t.Helper()
+	files, err := spool.files()
+	if err != nil {
+		t.Fatalf("files() = %v", err)
+	}
+	var got []string
+	for _, file := range files {
+		rec, err := spool.read(file)
+		if err != nil {
+			t.Fatalf("read() = %v", err)
+		}
+		got = append(got, string(rec.Value))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("spooled %v, want %v", got, want)
+	}
+	if spool.size() != int64(len(want)) {
+		t.Fatalf("size() = %d, want %d", spool.size(), len(want))
+	}
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -14,46 +16,89 @@ const (
 	RefreshTokenExpiry = 7 * 24 * time.Hour
 )
 
+// Roles a user can have. Access tokens carry the role so services can authorize
+// without calling the auth service.
+const (
+	RoleCustomer  = "customer"
+	RoleOrganizer = "organizer"
+	RoleAdmin     = "admin"
+	RoleGateStaff = "gate-staff"
+)
+
 type Claims struct {
 	UserID int32  `json:"user_id"`
 	Type   string `json:"type"`
+	Role   string `json:"role,omitempty"`
+	// OrgID is the organization an organizer acts for, 0 when the user has none.
+	OrgID         int32 `json:"org_id,omitempty"`
+	EmailVerified bool  `json:"email_verified,omitempty"`
+	// FamilyID groups a refresh token with the tokens it was rotated from and into.
+	FamilyID string `json:"fid,omitempty"`
+	// MFA is set when the session was started with a second factor. Refresh tokens
+	// carry it so the access tokens they are rotated into keep it.
+	MFA bool `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID int32, secret string) (string, error) {
+// Subject is who an access token is issued to.
+type Subject struct {
+	UserID        int32
+	Role          string
+	OrgID         int32
+	EmailVerified bool
+	MFA           bool
+}
+
+func GenerateAccessToken(subject Subject, keys *KeyRing) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Type:   AccessType,
+		UserID:        subject.UserID,
+		Type:          AccessType,
+		Role:          subject.Role,
+		OrgID:         subject.OrgID,
+		EmailVerified: subject.EmailVerified,
+		MFA:           subject.MFA,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        NewTokenID(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
 }
 
-func GenerateRefreshToken(userID int32, secret string) (string, error) {
+// GenerateRefreshToken issues refresh token tokenID of familyID. Both come from
+// NewTokenID. mfa records whether the session was started with a second factor.
+func GenerateRefreshToken(userID int32, mfa bool, familyID, tokenID string, keys *KeyRing) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Type:   RefreshType,
+		UserID:   userID,
+		Type:     RefreshType,
+		FamilyID: familyID,
+		MFA:      mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.Sign(claims)
+}
+
+// NewTokenID returns a random identifier for the jti and fid claims.
+func NewTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
-func ParseToken(tokenStr string, secret string) (*Claims, error) {
+// ParseToken verifies tokenStr with the key its kid header names.
+func ParseToken(tokenStr string, keys KeySource) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, fmt.Errorf("missing kid header")
 		}
-		return []byte(secret), nil
-	})
+		return keys.PublicKey(kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}))
 
 	if err != nil {
 		return nil, err
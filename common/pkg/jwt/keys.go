@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySource resolves the public key a token was signed with from its kid header.
+type KeySource interface {
+	PublicKey(kid string) (crypto.PublicKey, error)
+}
+
+type signingKey struct {
+	id      string
+	private crypto.Signer
+	method  jwt.SigningMethod
+}
+
+// KeyRing holds the key new tokens are signed with and the public keys of earlier
+// keys, so tokens signed before a rotation stay valid until they expire.
+//
+// Keys live in a directory as PKCS#8 PEM files named <kid>.pem. The file that sorts
+// last is the current signing key. Old files can be deleted once the tokens they
+// signed have expired, i.e. after RefreshTokenExpiry.
+type KeyRing struct {
+	dir string
+
+	mu      sync.RWMutex
+	current signingKey
+	public  map[string]crypto.PublicKey
+}
+
+// LoadKeyRing reads every key in dir. When there is none yet it creates an Ed25519 key.
+func LoadKeyRing(dir string) (*KeyRing, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create key dir: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("list keys: %w", err)
+	}
+	sort.Strings(files)
+
+	ring := &KeyRing{dir: dir, public: make(map[string]crypto.PublicKey)}
+	for _, file := range files {
+		key, err := readKey(file)
+		if err != nil {
+			return nil, err
+		}
+		ring.add(key)
+	}
+	if ring.current.private == nil {
+		if _, err := ring.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+// Rotate creates a new Ed25519 key, stores it and signs with it from now on. The
+// previous keys keep verifying. It returns the new kid.
+func (k *KeyRing) Rotate() (string, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return "", fmt.Errorf("encode key: %w", err)
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	// O_EXCL keeps a second rotation within the same second from replacing a live key
+	f, err := os.OpenFile(filepath.Join(k.dir, kid+".pem"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("write key: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", fmt.Errorf("write key: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("write key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.add(signingKey{id: kid, private: private, method: jwt.SigningMethodEdDSA})
+	return kid, nil
+}
+
+// add registers key and makes it the signing key. Callers hold mu or own k exclusively.
+func (k *KeyRing) add(key signingKey) {
+	k.public[key.id] = key.private.Public()
+	k.current = key
+}
+
+func (k *KeyRing) PublicKey(kid string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.public[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	return key, nil
+}
+
+// Sign signs claims with the current key and names it in the kid header.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	key := k.current
+	k.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+func readKey(file string) (signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, fmt.Errorf("read key %s: no PEM block", filepath.Base(file))
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("parse key %s: %w", filepath.Base(file), err)
+	}
+
+	kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		return signingKey{id: kid, private: private, method: jwt.SigningMethodEdDSA}, nil
+	case *rsa.PrivateKey:
+		return signingKey{id: kid, private: private, method: jwt.SigningMethodRS256}, nil
+	}
+	return signingKey{}, fmt.Errorf("parse key %s: unsupported key type %T", filepath.Base(file), parsed)
+}
@@ -11,4 +11,6 @@ type DistributedLock interface {
 	Release(ctx context.Context, key string, token string) error
 }
 
+// ErrLockNotAcquired is returned by Acquire when someone else holds the lock. Any
+// other error means the lock store could not be asked.
 var ErrLockNotAcquired = fmt.Errorf("lock not acquired")
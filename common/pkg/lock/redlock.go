@@ -3,6 +3,7 @@ package lock
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,7 +26,8 @@ func (r *redLock) Acquire(ctx context.Context, key string, ttl time.Duration) (s
 	token := uuid.New().String()
 	startTime := time.Now()
 
-	acquired := 0
+	acquired, held := 0, 0
+	var nodeErr error
 	for _, client := range r.clients {
 		err := client.SetArgs(ctx, key, token, redis.SetArgs{
 			TTL:  ttl,
@@ -34,11 +36,13 @@ func (r *redLock) Acquire(ctx context.Context, key string, ttl time.Duration) (s
 
 		if errors.Is(err, redis.Nil) {
 			// key already exists, skip to next client
+			held++
 			continue
 		}
 
 		if err != nil {
 			// this Node failed to acquire lock, skip to next client
+			nodeErr = err
 			continue
 		}
 
@@ -52,7 +56,14 @@ func (r *redLock) Acquire(ctx context.Context, key string, ttl time.Duration) (s
 	if acquired >= r.quorum && validity > 0 {
 		return token, nil
 	}
-	return "", errors.New("failed to acquire lock")
+
+	// drop the minority we did get so the lock frees up before its TTL
+	r.releaseAll(context.WithoutCancel(ctx), key, token)
+	// the lock counts as taken when enough nodes hold it that no quorum is possible
+	if nodeErr != nil && held <= len(r.clients)-r.quorum {
+		return "", fmt.Errorf("acquire lock: %w", nodeErr)
+	}
+	return "", ErrLockNotAcquired
 }
 
 func (r *redLock) Release(ctx context.Context, key string, token string) error {
@@ -0,0 +1,15 @@
+package mail
+
+import "context"
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. FileMailer is the local implementation; a provider backed
+// one only has to implement Send.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
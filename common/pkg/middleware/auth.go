@@ -12,7 +12,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func AuthMiddleware(secretKey string, redisClient *redis.Client) gin.HandlerFunc {
+// AuthMiddleware verifies the bearer access token against keys, normally a
+// jwt.JWKSCache of the auth service.
+func AuthMiddleware(keys jwt.KeySource, redisClient *redis.Client) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -24,7 +26,7 @@ func AuthMiddleware(secretKey string, redisClient *redis.Client) gin.HandlerFunc
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := jwt.ParseToken(tokenString, secretKey)
+		claims, err := jwt.ParseToken(tokenString, keys)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"code": http.StatusUnauthorized,
@@ -39,18 +41,40 @@ func AuthMiddleware(secretKey string, redisClient *redis.Client) gin.HandlerFunc
 			return
 		}
 
-		blacklistKey := fmt.Sprintf("blacklist:access:%s", tokenString)
-		exists, err := redisClient.Exists(c.Request.Context(), blacklistKey).Result()
-		if err == nil && exists > 0 {
+		if revoked(c.Request.Context(), redisClient, claims) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
 			return
 		}
 
 		c.Set("userID", claims.UserID)
+		c.Set("tokenID", claims.ID)
+		c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		c.Set("role", claims.Role)
+		c.Set("orgID", claims.OrgID)
+		c.Set("emailVerified", claims.EmailVerified)
+		c.Set("mfa", claims.MFA)
 		c.Next()
 	}
 }
 
+// revoked reports whether the auth service revoked the token: blacklisted by jti on
+// logout, or issued before the user's tokens-valid-after time set by a logout from
+// all devices. A Redis failure does not lock everyone out.
+func revoked(ctx context.Context, redisClient *redis.Client, claims *jwt.Claims) bool {
+	pipe := redisClient.Pipeline()
+	blacklisted := pipe.Exists(ctx, fmt.Sprintf("blacklist:access:%s", claims.ID))
+	validAfter := pipe.Get(ctx, fmt.Sprintf("user:tokens-valid-after:%d", claims.UserID))
+	pipe.Exec(ctx)
+
+	if n, err := blacklisted.Result(); err == nil && n > 0 {
+		return true
+	}
+	if after, err := validAfter.Int64(); err == nil && claims.IssuedAt != nil && claims.IssuedAt.Unix() < after {
+		return true
+	}
+	return false
+}
+
 func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout*time.Second)
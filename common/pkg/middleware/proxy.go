@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trustedProxiesEnv lists the proxies, as IPs or CIDRs separated by commas, whose
+// X-Forwarded-For header is believed. Unset, no proxy is trusted.
+const trustedProxiesEnv = "TRUSTED_PROXIES"
+
+// SetTrustedProxies limits whose X-Forwarded-For r trusts. gin trusts every peer by
+// default, which lets any client pick the IP that KeyByIP limits and that sessions
+// record, so each service must call it.
+func SetTrustedProxies(r *gin.Engine) error {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv(trustedProxiesEnv), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return fmt.Errorf("set %s: %w", trustedProxiesEnv, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyFunc picks what a limit is counted against. An empty key skips the limit.
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP counts requests per client IP.
+func KeyByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// KeyByUser counts requests per authenticated user. It must run after AuthMiddleware.
+func KeyByUser(c *gin.Context) string {
+	userID := c.GetInt32("userID")
+	if userID == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(userID))
+}
+
+// KeyByEmail counts requests per "email" field of the JSON body, so that guessing
+// passwords for one account from many IPs is still limited. The body is restored for
+// the handler.
+func KeyByEmail(c *gin.Context) string {
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var req struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(req.Email))
+}
+
+// slidingWindowScript keeps the timestamps of the requests of the last window in a
+// sorted set. It admits the request when fewer than the limit are left, otherwise it
+// returns how long until the oldest one leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return tonumber(oldest[2]) + window - now
+`)
+
+// RateLimiter enforces sliding-window limits shared by every instance of a service.
+type RateLimiter struct {
+	client *redis.Client
+}
+
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{client: client}
+}
+
+// Allow records a request against key and reports whether it is within limit per
+// window. When it is not, the returned duration is how long to wait.
+func (l *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int64())
+	wait, err := slidingWindowScript.Run(ctx, l.client, []string{"ratelimit:" + key},
+		now, window.Milliseconds(), limit, member,
+	).Int64()
+	if err != nil {
+		return false, 0, fmt.Errorf("rate limit: %w", err)
+	}
+	if wait <= 0 {
+		return true, 0, nil
+	}
+	return false, time.Duration(wait) * time.Millisecond, nil
+}
+
+// Limit allows limit requests per window for each key. name keeps the counters of
+// different limits apart. Requests are let through when Redis is unavailable.
+func (l *RateLimiter) Limit(name string, limit int, window time.Duration, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+
+		ok, wait, err := l.Allow(c.Request.Context(), name+":"+k, limit, window)
+		if err != nil {
+			log.Printf("rate limit %s: %v", name, err)
+			c.Next()
+			return
+		}
+		if !ok {
+			tooManyRequests(c, wait)
+			return
+		}
+		c.Next()
+	}
+}
+
+// LockoutConfig configures progressive lockout: after Threshold failures within
+// Window the key is locked for Base, and every further failure doubles the lock up
+// to Max.
+type LockoutConfig struct {
+	Name      string
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// failureScript counts a failure and sets the lock once the threshold is reached.
+var failureScript = redis.NewScript(`
+local fails = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+local threshold = tonumber(ARGV[2])
+if fails < threshold then
+	return 0
+end
+local lock = tonumber(ARGV[3]) * 2 ^ (fails - threshold)
+lock = math.min(lock, tonumber(ARGV[4]))
+redis.call('SET', KEYS[2], '1', 'PX', math.floor(lock))
+return math.floor(lock)
+`)
+
+// Lockout locks a key out after repeated failures. A request fails when the handler
+// answers 401 Unauthorized; any 2xx answer clears the failures.
+func (l *RateLimiter) Lockout(cfg LockoutConfig, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		failsKey := fmt.Sprintf("lockout:%s:fails:%s", cfg.Name, k)
+		lockKey := fmt.Sprintf("lockout:%s:lock:%s", cfg.Name, k)
+		ctx := c.Request.Context()
+
+		locked, err := l.client.PTTL(ctx, lockKey).Result()
+		if err == nil && locked > 0 {
+			tooManyRequests(c, locked)
+			return
+		}
+
+		c.Next()
+
+		switch status := c.Writer.Status(); {
+		case status == http.StatusUnauthorized:
+			err = failureScript.Run(ctx, l.client, []string{failsKey, lockKey},
+				cfg.Window.Milliseconds(), cfg.Threshold, cfg.Base.Milliseconds(), cfg.Max.Milliseconds(),
+			).Err()
+		case status >= 200 && status < 300:
+			err = l.client.Del(ctx, failsKey).Err()
+		}
+		if err != nil {
+			log.Printf("lockout %s: %v", cfg.Name, err)
+		}
+	}
+}
+
+func tooManyRequests(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error": "too many requests",
+		"code":  http.StatusTooManyRequests,
+	})
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+type Run struct {
+	ID        string    `json:"id"`
+	Job       string    `json:"job"`
+	Trigger   string    `json:"trigger"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Items     int       `json:"items"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type HistoryStore interface {
+	Record(ctx context.Context, run Run) error
+	List(ctx context.Context, job string, limit int) ([]Run, error)
+}
+
+// redisHistory keeps the last `keep` runs of each job in a Redis list, so every
+// instance sees the same history.
+type redisHistory struct {
+	client *redis.Client
+	keep   int64
+}
+
+func NewRedisHistory(client *redis.Client, keep int) HistoryStore {
+	if keep <= 0 {
+		keep = 100
+	}
+	return &redisHistory{client: client, keep: int64(keep)}
+}
+
+func historyKey(job string) string {
+	return fmt.Sprintf("scheduler:history:%s", job)
+}
+
+func (h *redisHistory) Record(ctx context.Context, run Run) error {
+	payload, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("marshal run: %w", err)
+	}
+
+	key := historyKey(run.Job)
+	pipe := h.client.TxPipeline()
+	pipe.LPush(ctx, key, payload)
+	pipe.LTrim(ctx, key, 0, h.keep-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("record run: %w", err)
+	}
+	return nil
+}
+
+func (h *redisHistory) List(ctx context.Context, job string, limit int) ([]Run, error) {
+	if limit <= 0 || int64(limit) > h.keep {
+		limit = int(h.keep)
+	}
+
+	items, err := h.client.LRange(ctx, historyKey(job), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list runs: %w", err)
+	}
+
+	runs := make([]Run, 0, len(items))
+	for _, item := range items {
+		var run Run
+		if err := json.Unmarshal([]byte(item), &run); err != nil {
+			return nil, fmt.Errorf("unmarshal run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes exposes the admin endpoints for listing jobs, reading their run
+// history and triggering a run by hand.
+func (s *Scheduler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/jobs", s.listJobs)
+	router.GET("/jobs/:name/runs", s.listRuns)
+	router.POST("/jobs/:name/trigger", s.trigger)
+}
+
+func (s *Scheduler) listJobs(c *gin.Context) {
+	jobs := s.Jobs()
+	res := make([]gin.H, 0, len(jobs))
+	for _, job := range jobs {
+		res = append(res, gin.H{
+			"name":     job.Name,
+			"schedule": job.Schedule,
+			"timeout":  job.Timeout.String(),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": res})
+}
+
+func (s *Scheduler) listRuns(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	runs, err := s.History(c.Request.Context(), c.Param("name"), limit)
+	if errors.Is(err, ErrUnknownJob) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+func (s *Scheduler) trigger(c *gin.Context) {
+	run, err := s.Trigger(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, ErrUnknownJob):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "run": run})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run})
+}
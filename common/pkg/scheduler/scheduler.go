@@ -0,0 +1,232 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"ticket-tix/common/pkg/lock"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// JobFunc does one pass of a job and reports how many items it processed.
+type JobFunc func(ctx context.Context) (items int, err error)
+
+type Job struct {
+	Name string
+	// Schedule is a standard five-field cron spec or a descriptor such as "@every 1m".
+	Schedule string
+	// Timeout bounds a single run and is also the TTL of its locks. Defaults to 5 minutes.
+	Timeout time.Duration
+	Run     JobFunc
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs jobs on their schedules in every instance of a service, but uses the
+// distributed lock so only one instance executes a given tick:
+//   - a tick lock, keyed by job and scheduled time, is claimed by the first instance
+//     and left to expire so late instances skip that tick;
+//   - a run lock, keyed by job, is held for the duration of a run so scheduled and
+//     manual runs never overlap.
+type Scheduler struct {
+	lock    lock.DistributedLock
+	history HistoryStore
+	logger  *slog.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*entry
+}
+
+func New(locker lock.DistributedLock, history HistoryStore, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		lock:    locker,
+		history: history,
+		logger:  logger,
+		jobs:    make(map[string]*entry),
+	}
+}
+
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("register job: name and run are required")
+	}
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("register job %s: parse schedule: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = 5 * time.Minute
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("register job %s: already registered", job.Name)
+	}
+	s.jobs[job.Name] = &entry{job: job, schedule: schedule}
+	return nil
+}
+
+// Jobs lists the registered jobs.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		jobs = append(jobs, e.job)
+	}
+	return jobs
+}
+
+// Start runs every registered job on its schedule until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.RLock()
+	entries := make([]*entry, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		entries = append(entries, e)
+	}
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := nextTick(e.schedule, time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("scheduler stopped", "job", e.job.Name)
+			return
+		case <-timer.C:
+			s.runScheduled(ctx, e, next)
+		}
+	}
+}
+
+// nextTick returns the next time schedule fires after now. Cron specs name wall clock
+// times already, but cron counts an @every delay from now, which differs between
+// instances; those ticks are aligned to multiples of the delay instead, so every
+// instance computes the same tick and its lock key.
+func nextTick(schedule cron.Schedule, now time.Time) time.Time {
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(every.Delay).Add(every.Delay)
+	}
+	return schedule.Next(now)
+}
+
+func (s *Scheduler) runScheduled(ctx context.Context, e *entry, tick time.Time) {
+	tickKey := fmt.Sprintf("scheduler:tick:%s:%d", e.job.Name, tick.Unix())
+	if _, err := s.lock.Acquire(ctx, tickKey, e.job.Timeout); err != nil {
+		// ErrLockNotAcquired means another instance owns this tick
+		if !errors.Is(err, lock.ErrLockNotAcquired) {
+			s.logger.Error("scheduled job skipped", "job", e.job.Name, "error", err)
+		}
+		return
+	}
+
+	if _, err := s.execute(ctx, e, TriggerSchedule); err != nil && !errors.Is(err, ErrJobRunning) {
+		s.logger.Error("scheduled job failed", "job", e.job.Name, "error", err)
+	}
+}
+
+// Trigger runs a job immediately, outside its schedule. It fails with ErrJobRunning if
+// a run is already in progress on any instance.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (Run, error) {
+	s.mu.RLock()
+	e, ok := s.jobs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return Run{}, ErrUnknownJob
+	}
+	return s.execute(ctx, e, TriggerManual)
+}
+
+// History returns the most recent runs of a job, newest first.
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]Run, error) {
+	s.mu.RLock()
+	_, ok := s.jobs[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	return s.history.List(ctx, name, limit)
+}
+
+func (s *Scheduler) execute(ctx context.Context, e *entry, trigger string) (Run, error) {
+	runKey := fmt.Sprintf("scheduler:run:%s", e.job.Name)
+	token, err := s.lock.Acquire(ctx, runKey, e.job.Timeout)
+	if errors.Is(err, lock.ErrLockNotAcquired) {
+		return Run{}, ErrJobRunning
+	}
+	if err != nil {
+		return Run{}, fmt.Errorf("acquire run lock: %w", err)
+	}
+	defer s.lock.Release(context.WithoutCancel(ctx), runKey, token)
+
+	runCtx, cancel := context.WithTimeout(ctx, e.job.Timeout)
+	defer cancel()
+
+	run := Run{
+		ID:        uuid.New().String(),
+		Job:       e.job.Name,
+		Trigger:   trigger,
+		StartedAt: time.Now(),
+	}
+	items, runErr := s.safeRun(runCtx, e.job.Run)
+	run.EndedAt = time.Now()
+	run.Items = items
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+
+	s.logger.Info("job finished",
+		"job", run.Job,
+		"trigger", run.Trigger,
+		"items", run.Items,
+		"duration", run.EndedAt.Sub(run.StartedAt),
+		"error", run.Error,
+	)
+	if err := s.history.Record(context.WithoutCancel(ctx), run); err != nil {
+		s.logger.Error("failed to record job run", "job", run.Job, "error", err)
+	}
+
+	if runErr != nil {
+		return run, fmt.Errorf("run job %s: %w", e.job.Name, runErr)
+	}
+	return run, nil
+}
+
+// safeRun keeps a panicking job from taking the whole service down.
+func (s *Scheduler) safeRun(ctx context.Context, fn JobFunc) (items int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
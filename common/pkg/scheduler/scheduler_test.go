@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"ticket-tix/common/pkg/lock"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+func TestNextTickAlignsEvery(t *testing.T) {
+	schedule, err := cron.ParseStandard("@every 1m")
+	if err != nil {
+		t.Fatalf("ParseStandard() = %v", err)
+	}
+
+	// instances started at different times agree on the next tick
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	want := base.Add(time.Minute)
+	for _, offset := range []time.Duration{0, time.Second, 29 * time.Second, 59*time.Second + 999*time.Millisecond} {
+		if got := nextTick(schedule, base.Add(offset)); !got.Equal(want) {
+			t.Errorf("nextTick(%s) = %s, want %s", base.Add(offset), got, want)
+		}
+	}
+
+	spec, err := cron.ParseStandard("*/5 * * * *")
+	if err != nil {
+		t.Fatalf("ParseStandard() = %v", err)
+	}
+	if got := nextTick(spec, base.Add(time.Minute)); !got.Equal(base.Add(5 * time.Minute)) {
+		t.Errorf("nextTick(cron spec) = %s, want %s", got, base.Add(5*time.Minute))
+	}
+}
+
+// TestSchedulersShareTicks runs two instances of a scheduler, started half a tick
+// apart, against one lock store. Every tick runs once.
+func TestSchedulersShareTicks(t *testing.T) {
+	locker := newMemoryLock()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var mu sync.Mutex
+	var runs []time.Time
+	job := Job{
+		Name:     "count",
+		Schedule: "@every 1s",
+		Timeout:  5 * time.Second,
+		Run: func(ctx context.Context) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs = append(runs, time.Now())
+			return 1, nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := range 2 {
+		s := New(locker, &memoryHistory{}, logger)
+		if err := s.Register(job); err != nil {
+			t.Fatalf("Register() = %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Duration(i) * 500 * time.Millisecond)
+			s.Start(ctx)
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) < 2 {
+		t.Fatalf("job ran %d times in 3.5s, want at least 2", len(runs))
+	}
+	seen := make(map[int64]bool)
+	for _, run := range runs {
+		second := run.Unix()
+		if seen[second] {
+			t.Fatalf("tick %d ran more than once, runs: %v", second, runs)
+		}
+		seen[second] = true
+	}
+}
+
+// memoryLock is a lock.DistributedLock for a single process.
+type memoryLock struct {
+	mu    sync.Mutex
+	locks map[string]memoryLockEntry
+}
+
+type memoryLockEntry struct {
+	token     string
+	expiresAt time.Time
+}
+
+func newMemoryLock() *memoryLock {
+	return &memoryLock{locks: make(map[string]memoryLockEntry)}
+}
+
+func (l *memoryLock) Acquire(_ context.Context, key string, ttl time.Duration) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if held, ok := l.locks[key]; ok && time.Now().Before(held.expiresAt) {
+		return "", lock.ErrLockNotAcquired
+	}
+	token := uuid.New().String()
+	l.locks[key] = memoryLockEntry{token: token, expiresAt: time.Now().Add(ttl)}
+	return token, nil
+}
+
+func (l *memoryLock) Release(_ context.Context, key, token string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks[key].token == token {
+		delete(l.locks, key)
+	}
+	return nil
+}
+
+type memoryHistory struct {
+	mu   sync.Mutex
+	runs []Run
+}
+
+func (h *memoryHistory) Record(_ context.Context, run Run) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.runs = append(h.runs, run)
+	return nil
+}
+
+func (h *memoryHistory) List(context.Context, string, int) ([]Run, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.runs, nil
+}
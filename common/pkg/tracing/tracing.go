@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// exporterEnv selects where finished spans go. "console" writes them to stdout; unset,
+// spans are still recorded and propagated but not exported.
+const exporterEnv = "OTEL_TRACES_EXPORTER"
+
+// Init installs an SDK tracer provider for service as the global one. Without it the
+// global provider is a no-op: spans have no ids, so no traceparent is propagated
+// over HTTP or Kafka. Call the returned function on shutdown to flush spans.
+func Init(service string) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", service),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	switch exporter := os.Getenv(exporterEnv); exporter {
+	case "", "none":
+	case "console":
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("create trace exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unknown %s %q", exporterEnv, exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_tickets_seat_id;
+ALTER TABLE tickets DROP COLUMN IF EXISTS seat_id;
+
+DROP INDEX IF EXISTS idx_event_section_categories_category;
+DROP TABLE IF EXISTS event_section_categories;
+
+ALTER TABLE events DROP COLUMN IF EXISTS venue_id;
+
+DROP INDEX IF EXISTS idx_seats_row_id;
+DROP TABLE IF EXISTS seats;
+
+DROP INDEX IF EXISTS idx_rows_section_id;
+DROP TABLE IF EXISTS rows;
+
+DROP INDEX IF EXISTS idx_sections_venue_id;
+DROP TABLE IF EXISTS sections;
+
+DROP TABLE IF EXISTS venues;
@@ -0,0 +1,75 @@
+-- ==========================================
+-- VENUES
+-- ==========================================
+CREATE TABLE IF NOT EXISTS venues (
+          id SERIAL PRIMARY KEY,
+          name VARCHAR(100) NOT NULL,
+          address VARCHAR(255) NOT NULL,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ==========================================
+-- SECTIONS
+-- ==========================================
+CREATE TABLE IF NOT EXISTS sections (
+            id SERIAL PRIMARY KEY,
+            venue_id INT NOT NULL REFERENCES venues(id) ON DELETE CASCADE,
+            name VARCHAR(50) NOT NULL,
+            code VARCHAR(8) NOT NULL,               -- short prefix used in seat numbers, e.g. 'A'
+            pos_x DOUBLE PRECISION NOT NULL DEFAULT 0,
+            pos_y DOUBLE PRECISION NOT NULL DEFAULT 0,
+            width DOUBLE PRECISION NOT NULL DEFAULT 0,
+            height DOUBLE PRECISION NOT NULL DEFAULT 0,
+            UNIQUE (venue_id, code)
+);
+
+CREATE INDEX idx_sections_venue_id ON sections(venue_id);
+
+-- ==========================================
+-- ROWS
+-- ==========================================
+CREATE TABLE IF NOT EXISTS rows (
+            id SERIAL PRIMARY KEY,
+            section_id INT NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+            label VARCHAR(4) NOT NULL,
+            display_order INT NOT NULL DEFAULT 0,
+            UNIQUE (section_id, label)
+);
+
+CREATE INDEX idx_rows_section_id ON rows(section_id);
+
+-- ==========================================
+-- SEATS
+-- ==========================================
+CREATE TABLE IF NOT EXISTS seats (
+             id SERIAL PRIMARY KEY,
+             row_id INT NOT NULL REFERENCES rows(id) ON DELETE CASCADE,
+             label VARCHAR(4) NOT NULL,
+             pos_x DOUBLE PRECISION NOT NULL DEFAULT 0,
+             pos_y DOUBLE PRECISION NOT NULL DEFAULT 0,
+             is_accessible BOOLEAN NOT NULL DEFAULT FALSE,
+             is_obstructed_view BOOLEAN NOT NULL DEFAULT FALSE,
+             UNIQUE (row_id, label)
+);
+
+CREATE INDEX idx_seats_row_id ON seats(row_id);
+
+-- ==========================================
+-- EVENT LAYOUT
+-- ==========================================
+ALTER TABLE events
+    ADD COLUMN venue_id INT REFERENCES venues(id);
+
+CREATE TABLE IF NOT EXISTS event_section_categories (
+            event_id INT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            section_id INT NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+            event_category_id INT NOT NULL REFERENCES event_categories(id) ON DELETE CASCADE,
+            PRIMARY KEY (event_id, section_id)
+);
+
+CREATE INDEX idx_event_section_categories_category ON event_section_categories(event_category_id);
+
+ALTER TABLE tickets
+    ADD COLUMN seat_id INT REFERENCES seats(id);
+
+CREATE INDEX idx_tickets_seat_id ON tickets(seat_id);
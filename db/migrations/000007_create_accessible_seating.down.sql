@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_seats_companion_seat_id;
+
+ALTER TABLE event_categories DROP COLUMN IF EXISTS accessible_release_at;
+
+ALTER TABLE seats DROP COLUMN IF EXISTS companion_seat_id;
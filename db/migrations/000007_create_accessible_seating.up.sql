@@ -0,0 +1,9 @@
+-- an accessible seat points at the companion seat that must be sold with it
+ALTER TABLE seats
+    ADD COLUMN companion_seat_id INT REFERENCES seats(id);
+
+-- accessible + companion seats are held back from general sale until this time
+ALTER TABLE event_categories
+    ADD COLUMN accessible_release_at TIMESTAMP;
+
+CREATE UNIQUE INDEX idx_seats_companion_seat_id ON seats(companion_seat_id) WHERE companion_seat_id IS NOT NULL;
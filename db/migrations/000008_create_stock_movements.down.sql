@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_stock_movements_event_category_id;
+DROP TABLE IF EXISTS stock_movements;
@@ -0,0 +1,13 @@
+-- ==========================================
+-- STOCK MOVEMENTS (standing stock ledger)
+-- ==========================================
+CREATE TABLE IF NOT EXISTS stock_movements (
+            id BIGSERIAL PRIMARY KEY,
+            event_category_id INT NOT NULL REFERENCES event_categories(id) ON DELETE CASCADE,
+            delta INT NOT NULL,                     -- negative = taken, positive = returned
+            reason VARCHAR(20) NOT NULL,            -- 'BOOKING', 'RELEASE', 'REPAIR'
+            reference VARCHAR(100),
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_stock_movements_event_category_id ON stock_movements(event_category_id, created_at);
@@ -0,0 +1,16 @@
+-- ==========================================
+-- OUTBOX (events written in the same transaction as the state change)
+-- ==========================================
+CREATE TABLE IF NOT EXISTS outbox_events (
+            id BIGSERIAL PRIMARY KEY,
+            topic VARCHAR(100) NOT NULL,
+            message_key VARCHAR(200) NOT NULL,
+            payload BYTEA NOT NULL,
+            headers JSONB NOT NULL DEFAULT '{}',
+            attempts INT NOT NULL DEFAULT 0,
+            last_error TEXT,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+            published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(id) WHERE published_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_events_created_by;
+ALTER TABLE events DROP COLUMN IF EXISTS created_by;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,12 @@
+-- ==========================================
+-- USER ROLES
+-- ==========================================
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer'
+        CHECK (role IN ('customer', 'organizer', 'admin', 'gate-staff'));
+
+-- the organizer who created the event; organizers may only manage their own events
+ALTER TABLE events
+    ADD COLUMN created_by INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_events_created_by ON events(created_by);
@@ -0,0 +1,23 @@
+-- ==========================================
+-- ORGANIZATIONS (event organizers)
+-- ==========================================
+CREATE TABLE IF NOT EXISTS organizations (
+            id SERIAL PRIMARY KEY,
+            name VARCHAR(100) NOT NULL,
+            slug VARCHAR(100) UNIQUE NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- a user belongs to at most one organization
+CREATE TABLE IF NOT EXISTS organization_members (
+            user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+            organization_id INT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_organization_members_organization_id ON organization_members(organization_id);
+
+ALTER TABLE events
+    ADD COLUMN organizer_id INT REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_events_organizer_id ON events(organizer_id);
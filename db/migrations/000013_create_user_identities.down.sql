@@ -0,0 +1,13 @@
+-- passwordless users could only be kept by deleting them or inventing a password, so
+-- refuse to go back until they have set one
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE password_hash IS NULL) THEN
+        RAISE EXCEPTION 'users without a password exist; have them set one before rolling back';
+    END IF;
+END
+$$;
+
+DROP TABLE IF EXISTS user_identities;
+ALTER TABLE users
+    ALTER COLUMN password_hash SET NOT NULL;
@@ -0,0 +1,19 @@
+-- ==========================================
+-- SOCIAL LOGIN (OIDC)
+-- ==========================================
+-- users who only sign in through a provider have no password
+ALTER TABLE users
+    ALTER COLUMN password_hash DROP NOT NULL;
+
+-- a provider account (issuer subject) linked to a user
+CREATE TABLE IF NOT EXISTS user_identities (
+            id SERIAL PRIMARY KEY,
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            provider VARCHAR(50) NOT NULL,
+            subject VARCHAR(255) NOT NULL,
+            email VARCHAR(255) NOT NULL,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
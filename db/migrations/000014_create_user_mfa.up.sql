@@ -0,0 +1,23 @@
+-- ==========================================
+-- TWO-FACTOR AUTHENTICATION (TOTP)
+-- ==========================================
+-- enabled_at stays NULL until the user confirmed a code from their authenticator
+CREATE TABLE IF NOT EXISTS user_mfa (
+            user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+            secret VARCHAR(64) NOT NULL,
+            -- the newest TOTP time step used, so a code cannot be used twice
+            last_used_step BIGINT NOT NULL DEFAULT 0,
+            enabled_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- single-use codes for when the authenticator is lost, stored as sha256 hashes
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+            id SERIAL PRIMARY KEY,
+            user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+            code_hash VARCHAR(64) NOT NULL,
+            used_at TIMESTAMP,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
@@ -0,0 +1,6 @@
+-- rows that kept failing to publish are set aside so later rows are not held up
+ALTER TABLE outbox_events
+    ADD COLUMN dead_lettered_at TIMESTAMP WITH TIME ZONE;
+
+DROP INDEX IF EXISTS idx_outbox_events_pending;
+CREATE INDEX idx_outbox_events_pending ON outbox_events(id) WHERE published_at IS NULL AND dead_lettered_at IS NULL;
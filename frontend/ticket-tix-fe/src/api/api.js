@@ -1,7 +1,12 @@
 const API_BASE = "http://localhost:50061";
 
 async function apiFetch(path, opts = {}) {
-  const res = await fetch(`${API_BASE}${path}`, opts);
+  // admin endpoints need an organizer or admin access token
+  const token = localStorage.getItem("access_token");
+  const headers = token
+    ? { ...opts.headers, Authorization: `Bearer ${token}` }
+    : opts.headers;
+  const res = await fetch(`${API_BASE}${path}`, { ...opts, headers });
   if (!res.ok) {
     const b = await res.json().catch(() => ({}));
     throw new Error(b.error || `HTTP ${res.status}`);
@@ -17,6 +22,8 @@ const api = {
     return apiFetch(`/events?${q}`);
   },
   detail: (id) => apiFetch(`/event/${id}`),
+  seatingChart: (id) => apiFetch(`/event/${id}/seating-chart`),
+  venueChart: (id) => apiFetch(`/venues/${id}/chart`),
   create: (fd) => apiFetch("/events", { method: "POST", body: fd }),
   uploadImages: (id, fd) =>
     apiFetch(`/events/${id}/images`, { method: "POST", body: fd }),
@@ -6,10 +6,17 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"ticket-tix/common/pkg/db"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/common/pkg/mail"
+	"ticket-tix/common/pkg/middleware"
+	"ticket-tix/common/pkg/tracing"
 	"ticket-tix/service/auth/internal/handler"
+	"ticket-tix/service/auth/internal/infra/oidc"
 	intRedis "ticket-tix/service/auth/internal/infra/redis"
 	"ticket-tix/service/auth/internal/repository"
 	"ticket-tix/service/auth/internal/service"
@@ -24,7 +31,16 @@ const (
 
 	redisPort = "localhost:6379"
 
-	secretKey = "sudo-secret-key"
+	// keyDir holds the JWT signing keys; the auth service creates one on first start
+	keyDir = "keys/jwt"
+
+	// mail is written to files until a provider is configured
+	mailDir = "tmp/mail"
+	appURL  = "http://localhost:5173"
+
+	// OIDC providers are listed in this variable, e.g. "google,mock", and each is
+	// configured by OIDC_<NAME>_ISSUER, _CLIENT_ID and _CLIENT_SECRET
+	oidcProvidersEnv = "OIDC_PROVIDERS"
 
 	dbHost = "localhost"
 	dbPort = 5433
@@ -34,6 +50,17 @@ const (
 )
 
 func main() {
+	shutdownTracing, err := tracing.Init("auth-service")
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Tracer provider shutdown failed: %v", err)
+		}
+	}()
 
 	dbConn := openDBConnection()
 	redisConn := openRedisConnection()
@@ -41,11 +68,33 @@ func main() {
 	tokenCache := intRedis.NewRefreshToken(redisConn)
 
 	userRepo := repository.NewUserRepo(dbConn)
-	userService := service.NewUserService(userRepo, secretKey, tokenCache)
-	userHandler := handler.NewHandler(userService, redisConn)
+	keys, err := jwt.LoadKeyRing(keyDir)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
 
-	r := gin.Default()
+	mailer, err := mail.NewFileMailer(mailDir)
+	if err != nil {
+		log.Fatalf("Failed to create mailer: %v", err)
+	}
 
+	userService := service.NewUserService(
+		userRepo,
+		keys,
+		tokenCache,
+		mailer,
+		appURL,
+		discoverOIDCProviders(),
+		intRedis.NewOIDCState(redisConn),
+		intRedis.NewMFAChallenge(redisConn),
+	)
+	userHandler := handler.NewHandler(userService, keys, redisConn)
+
+	r := gin.Default()
+	if err := middleware.SetTrustedProxies(r); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	r.Use(middleware.TracingMiddleware("auth-service"))
 	userHandler.RegisterRoutes(r)
 
 	srv := &http.Server{Addr: ":" + httpPort, Handler: r}
@@ -111,3 +160,35 @@ func openRedisConnection() *redis.Client {
 	}
 	return client
 }
+
+// discoverOIDCProviders sets up the providers named in OIDC_PROVIDERS. A provider
+// that cannot be discovered is left out, so social login being down does not take
+// password login with it.
+func discoverOIDCProviders() []*oidc.Provider {
+	var providers []*oidc.Provider
+	for _, name := range strings.Split(os.Getenv(oidcProvidersEnv), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		env := "OIDC_" + strings.ToUpper(name) + "_"
+		cfg := oidc.Config{
+			Name:         name,
+			Issuer:       os.Getenv(env + "ISSUER"),
+			ClientID:     os.Getenv(env + "CLIENT_ID"),
+			ClientSecret: os.Getenv(env + "CLIENT_SECRET"),
+			RedirectURL:  appURL + "/auth/callback/" + name,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		provider, err := oidc.Discover(ctx, cfg)
+		cancel()
+		if err != nil {
+			log.Printf("OIDC provider %s disabled: %v", name, err)
+			continue
+		}
+		log.Printf("OIDC provider %s enabled", name)
+		providers = append(providers, provider)
+	}
+	return providers
+}
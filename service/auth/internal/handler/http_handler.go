@@ -1,35 +1,95 @@
 package handler
 
 import (
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strconv"
+	"ticket-tix/common/pkg/jwt"
 	"ticket-tix/common/pkg/middleware"
 	"ticket-tix/service/auth/internal/model"
+	"ticket-tix/service/auth/internal/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
+// loginLockout locks an account for 30s after 5 failed logins, doubling with every
+// further failure up to an hour. Failures are forgotten after a day without one.
+var loginLockout = middleware.LockoutConfig{
+	Name:      "login",
+	Threshold: 5,
+	Base:      30 * time.Second,
+	Max:       time.Hour,
+	Window:    24 * time.Hour,
+}
+
 const (
-	secretKey = "sudo-secret-key"
+	// oidcStateCookie ties a social login to the browser that started it. Without it a
+	// callback with someone else's code and state would log the victim into the
+	// attacker's account. It lives as long as the login state in Redis.
+	oidcStateCookie    = "oidc_state"
+	oidcStateCookieAge = 10 * time.Minute
+	oidcCookiePath     = "/auth/oidc"
 )
 
 type Handler struct {
 	service     model.UserService
+	keys        *jwt.KeyRing
 	redisClient *redis.Client
 }
 
-func NewHandler(service model.UserService, redisClient *redis.Client) *Handler {
-	return &Handler{service: service, redisClient: redisClient}
+func NewHandler(service model.UserService, keys *jwt.KeyRing, redisClient *redis.Client) *Handler {
+	return &Handler{service: service, keys: keys, redisClient: redisClient}
 }
 
 func (h *Handler) RegisterRoutes(r gin.IRouter) {
-	r.POST("/auth/register", h.Register)
-	r.POST("/auth/login", h.Login)
-	r.POST("/auth/refresh", h.RefreshToken)
+	limiter := middleware.NewRateLimiter(h.redisClient)
+
+	r.POST("/auth/register", limiter.Limit("register-ip", 10, time.Hour, middleware.KeyByIP), h.Register)
+	r.POST("/auth/login",
+		limiter.Limit("login-ip", 20, time.Minute, middleware.KeyByIP),
+		limiter.Limit("login-email", 10, 15*time.Minute, middleware.KeyByEmail),
+		limiter.Lockout(loginLockout, middleware.KeyByEmail),
+		h.Login,
+	)
+	r.POST("/auth/refresh", limiter.Limit("refresh-ip", 60, time.Minute, middleware.KeyByIP), h.RefreshToken)
+	r.POST("/auth/verify-email", limiter.Limit("verify-email-ip", 20, 15*time.Minute, middleware.KeyByIP), h.VerifyEmail)
+	r.POST("/auth/forgot-password",
+		limiter.Limit("forgot-password-ip", 10, time.Hour, middleware.KeyByIP),
+		limiter.Limit("forgot-password-email", 3, time.Hour, middleware.KeyByEmail),
+		h.ForgotPassword,
+	)
+	r.POST("/auth/reset-password", limiter.Limit("reset-password-ip", 10, 15*time.Minute, middleware.KeyByIP), h.ResetPassword)
+	r.GET("/auth/oidc/:provider/login", limiter.Limit("oidc-ip", 30, time.Minute, middleware.KeyByIP), h.OIDCLogin)
+	r.POST("/auth/oidc/:provider/callback", limiter.Limit("oidc-ip", 30, time.Minute, middleware.KeyByIP), h.OIDCCallback)
+	r.POST("/auth/mfa/verify", limiter.Limit("mfa-verify-ip", 20, time.Minute, middleware.KeyByIP), h.VerifyMFA)
+	r.GET("/.well-known/jwks.json", h.JWKS)
 
 	auth := r.Group("/user")
-	auth.Use(middleware.AuthMiddleware(secretKey, h.redisClient))
+	auth.Use(middleware.AuthMiddleware(h.keys, h.redisClient))
 	auth.POST("/logout", h.LogOut)
+	auth.POST("/verify-email/resend",
+		limiter.Limit("resend-verification-user", 3, time.Hour, middleware.KeyByUser),
+		h.ResendVerificationEmail,
+	)
+	auth.GET("/sessions", h.ListSessions)
+	auth.DELETE("/sessions/:id", h.RevokeSession)
+	auth.POST("/mfa/enroll", h.EnrollMFA)
+	auth.POST("/mfa/confirm", limiter.Limit("mfa-confirm-user", 10, 15*time.Minute, middleware.KeyByUser), h.ConfirmMFA)
+	auth.POST("/mfa/disable", h.DisableMFA)
+	auth.POST("/mfa/recovery-codes", h.RegenerateRecoveryCodes)
+
+	admin := r.Group("/admin")
+	admin.Use(
+		middleware.AuthMiddleware(h.keys, h.redisClient),
+		middleware.RequireRole(jwt.RoleAdmin),
+	)
+	admin.PUT("/users/:id/role", h.SetUserRole)
+	admin.POST("/organizations", h.CreateOrganization)
+	admin.POST("/organizations/:id/members", h.AddOrganizationMember)
+	admin.POST("/keys/rotate", h.RotateKey)
 }
 
 func (h *Handler) Login(c *gin.Context) {
@@ -41,7 +101,12 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	resp, loginErr := h.service.Login(ctx, req.Email, req.Password)
+	resp, loginErr := h.service.Login(ctx, req.Email, req.Password, deviceFrom(c))
+	if errors.Is(loginErr, service.ErrInvalidCredential) {
+		// 401 is what counts as a failed attempt for loginLockout
+		c.JSON(http.StatusUnauthorized, gin.H{"error": loginErr.Error()})
+		return
+	}
 	if loginErr != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": loginErr.Error()})
 		return
@@ -50,6 +115,49 @@ func (h *Handler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+// OIDCLogin redirects the browser to the provider's sign in page.
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	authURL, state, err := h.service.OIDCAuthURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setOIDCStateCookie(c, state, int(oidcStateCookieAge.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback completes a social login. The provider redirects to the frontend,
+// which posts the code and state it received here, from the browser that holds the
+// state cookie of OIDCLogin.
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	var req struct {
+		Code  string `json:"code" binding:"required"`
+		State string `json:"state" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bound, err := c.Cookie(oidcStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(bound), []byte(req.State)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": service.ErrInvalidLoginState.Error()})
+		return
+	}
+	setOIDCStateCookie(c, "", -1)
+
+	resp, err := h.service.OIDCLogin(c.Request.Context(), c.Param("provider"), req.Code, req.State, deviceFrom(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
 func (h *Handler) Register(c *gin.Context) {
 	var req model.LoginRequest
 
@@ -78,7 +186,7 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	token, err := h.service.RefreshToken(ctx, req.RefreshToken)
+	token, err := h.service.RefreshToken(ctx, req.RefreshToken, deviceFrom(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -86,6 +194,63 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": token})
 }
 
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+func (h *Handler) ResendVerificationEmail(c *gin.Context) {
+	if err := h.service.SendVerificationEmail(c.Request.Context(), c.GetInt32("userID")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "verification email sent"})
+}
+
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "if the address is registered, a reset link has been sent"})
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password updated"})
+}
+
 func (h *Handler) LogOut(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refresh_token" binding:"required"`
@@ -97,10 +262,221 @@ func (h *Handler) LogOut(c *gin.Context) {
 	}
 
 	userID := c.GetInt32("userID")
+	access := model.AccessToken{
+		ID:        c.GetString("tokenID"),
+		ExpiresAt: c.GetTime("tokenExpiresAt"),
+	}
 
-	if err := h.service.LogOut(c.Request.Context(), userID, req.RefreshToken, req.AllDevices); err != nil {
+	if err := h.service.LogOut(c.Request.Context(), userID, access, req.RefreshToken, req.AllDevices); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
 }
+
+func (h *Handler) ListSessions(c *gin.Context) {
+	sessions, err := h.service.ListSessions(c.Request.Context(), c.GetInt32("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+func (h *Handler) RevokeSession(c *gin.Context) {
+	err := h.service.RevokeSession(c.Request.Context(), c.GetInt32("userID"), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// VerifyMFA finishes a login that answered with an MFA challenge.
+func (h *Handler) VerifyMFA(c *gin.Context) {
+	var req struct {
+		MFAToken string `json:"mfa_token" binding:"required"`
+		Code     string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.service.VerifyMFA(c.Request.Context(), req.MFAToken, req.Code, deviceFrom(c))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+func (h *Handler) EnrollMFA(c *gin.Context) {
+	enrollment, err := h.service.EnrollMFA(c.Request.Context(), c.GetInt32("userID"))
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": enrollment})
+}
+
+// ConfirmMFA enables two-factor authentication and answers with the recovery codes.
+func (h *Handler) ConfirmMFA(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.service.ConfirmMFA(c.Request.Context(), c.GetInt32("userID"), req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"recovery_codes": codes}})
+}
+
+func (h *Handler) DisableMFA(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DisableMFA(c.Request.Context(), c.GetInt32("userID"), req.Code); err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "two-factor authentication disabled"})
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), c.GetInt32("userID"), req.Code)
+	if err != nil {
+		c.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"recovery_codes": codes}})
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrInvalidMFAChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrTooManyMFAFailures):
+		return http.StatusTooManyRequests
+	case errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrMFANotEnabled),
+		errors.Is(err, service.ErrMFANotEnrolled):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// setOIDCStateCookie sets the state cookie, or deletes it when maxAge is negative.
+// It is only sent to the OIDC routes and never readable from scripts.
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcCookiePath, "", c.Request.TLS != nil, true)
+}
+
+// deviceFrom describes the client making the request.
+func deviceFrom(c *gin.Context) model.Device {
+	return model.Device{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+func (h *Handler) SetUserRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.SetUserRole(c.Request.Context(), int32(id), req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		Slug string `json:"slug" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org, err := h.service.CreateOrganization(c.Request.Context(), req.Name, req.Slug)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": org})
+}
+
+func (h *Handler) AddOrganizationMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	var req struct {
+		UserID int32 `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.AddOrganizationMember(c.Request.Context(), int32(id), req.UserID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "member added"})
+}
+
+// JWKS publishes the public signing keys so other services can verify tokens.
+func (h *Handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
+
+// RotateKey starts signing with a new key. Tokens signed with older keys stay valid.
+func (h *Handler) RotateKey(c *gin.Context) {
+	kid, err := h.keys.Rotate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"kid": kid}})
+}
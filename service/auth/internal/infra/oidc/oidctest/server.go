@@ -0,0 +1,176 @@
+// Package oidctest runs an OpenID Connect provider for tests of the social login.
+package oidctest
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"ticket-tix/common/pkg/jwt"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// ClientID and RedirectURL are what the provider expects of its client.
+const (
+	ClientID    = "ticket-tix"
+	RedirectURL = "http://localhost:5173/auth/callback"
+)
+
+// Claims are the claims of the ID tokens the server issues.
+type Claims struct {
+	Nonce         string `json:"nonce,omitempty"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	gojwt.RegisteredClaims
+}
+
+// Server signs in whoever Authorize is called for. Its discovery document, JWKS and
+// token endpoint are served over HTTP; the sign in page is skipped.
+type Server struct {
+	URL  string
+	Keys *jwt.KeyRing
+	// Tamper, if set, changes the claims of an ID token before it is signed.
+	Tamper func(*Claims)
+	// Sign, if set, replaces signing the ID token with Keys.
+	Sign func(Claims) (string, error)
+
+	t      *testing.T
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+type grant struct {
+	query         url.Values
+	subject       string
+	email         string
+	emailVerified bool
+}
+
+// NewServer starts a provider that is closed when the test ends.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	keys, err := jwt.LoadKeyRing(t.TempDir())
+	if err != nil {
+		t.Fatalf("create provider key: %v", err)
+	}
+	s := &Server{Keys: keys, t: t, grants: make(map[string]grant)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Discovery(s.URL))
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Keys.JWKS())
+	})
+	mux.HandleFunc("POST /token", s.token)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	s.URL = srv.URL
+	return s
+}
+
+// Discovery is the discovery document of the provider, naming issuer as its issuer.
+func (s *Server) Discovery(issuer string) map[string]string {
+	return map[string]string{
+		"issuer":                 issuer,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	}
+}
+
+// Authorize plays the user with subject and email signing in at authURL, and returns
+// the code the provider redirects back with. It fails the test if authURL is not an
+// authorization code request with PKCE for this provider.
+func (s *Server) Authorize(authURL, subject, email string, emailVerified bool) string {
+	s.t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		s.t.Fatalf("parse auth url: %v", err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != s.URL+"/authorize" {
+		s.t.Fatalf("auth url points to %s, want the authorization endpoint", got)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             ClientID,
+		"redirect_uri":          RedirectURL,
+		"code_challenge_method": "S256",
+	} {
+		if q.Get(key) != want {
+			s.t.Fatalf("auth url %s = %q, want %q", key, q.Get(key), want)
+		}
+	}
+	if q.Get("state") == "" || q.Get("nonce") == "" || q.Get("code_challenge") == "" {
+		s.t.Fatalf("auth url lacks state, nonce or code_challenge: %s", authURL)
+	}
+
+	code := jwt.NewTokenID()
+	s.mu.Lock()
+	s.grants[code] = grant{query: q, subject: subject, email: email, emailVerified: emailVerified}
+	s.mu.Unlock()
+	return code
+}
+
+// token exchanges a code for an ID token once, for the client and redirect URI it
+// was issued to and the PKCE verifier of its challenge.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok ||
+		r.PostForm.Get("client_id") != g.query.Get("client_id") ||
+		r.PostForm.Get("redirect_uri") != g.query.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != g.query.Get("code_challenge") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Nonce:         g.query.Get("nonce"),
+		Email:         g.email,
+		EmailVerified: g.emailVerified,
+		RegisteredClaims: gojwt.RegisteredClaims{
+			Issuer:    s.URL,
+			Subject:   g.subject,
+			Audience:  gojwt.ClaimStrings{g.query.Get("client_id")},
+			IssuedAt:  gojwt.NewNumericDate(now),
+			ExpiresAt: gojwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	if s.Tamper != nil {
+		s.Tamper(&claims)
+	}
+	sign := func(c Claims) (string, error) { return s.Keys.Sign(c) }
+	if s.Sign != nil {
+		sign = s.Sign
+	}
+	idToken, err := sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
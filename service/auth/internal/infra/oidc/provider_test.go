@@ -0,0 +1,173 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/service/auth/internal/infra/oidc/oidctest"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+func discover(t *testing.T, srv *oidctest.Server) *Provider {
+	t.Helper()
+	provider, err := Discover(context.Background(), Config{
+		Name:         "test",
+		Issuer:       srv.URL,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: "secret",
+		RedirectURL:  oidctest.RedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("Discover() = %v", err)
+	}
+	return provider
+}
+
+func TestDiscover(t *testing.T) {
+	srv := oidctest.NewServer(t)
+	provider := discover(t, srv)
+	if provider.meta.TokenEndpoint != srv.URL+"/token" || provider.meta.JWKSURI != srv.URL+"/jwks" {
+		t.Fatalf("discovered %+v", provider.meta)
+	}
+
+	if _, err := Discover(context.Background(), Config{Name: "test", Issuer: srv.URL + "/missing"}); err == nil {
+		t.Fatal("Discover() without a discovery document succeeded")
+	}
+
+	// a document naming another issuer is refused
+	impostor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(srv.Discovery(srv.URL))
+	}))
+	defer impostor.Close()
+	_, err := Discover(context.Background(), Config{Name: "test", Issuer: impostor.URL})
+	if err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("Discover() of another issuer's document = %v, want issuer mismatch", err)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	srv := oidctest.NewServer(t)
+	provider := discover(t, srv)
+
+	verifier := jwt.NewTokenID() + jwt.NewTokenID()
+	code := srv.Authorize(provider.AuthURL("state", "nonce", verifier), "alice", "Alice@Example.com", true)
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce")
+	if err != nil {
+		t.Fatalf("Exchange() = %v", err)
+	}
+	want := Identity{Subject: "alice", Email: "alice@example.com", EmailVerified: true}
+	if identity != want {
+		t.Fatalf("Exchange() = %+v, want %+v", identity, want)
+	}
+
+	// a code is only exchanged once
+	if _, err := provider.Exchange(context.Background(), code, verifier, "nonce"); err == nil {
+		t.Fatal("second Exchange() of the same code succeeded")
+	}
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	srv := oidctest.NewServer(t)
+	provider := discover(t, srv)
+
+	code := srv.Authorize(provider.AuthURL("state", "nonce", "verifier"), "alice", "alice@example.com", true)
+	if _, err := provider.Exchange(context.Background(), code, "another verifier", "nonce"); err == nil {
+		t.Fatal("Exchange() with the wrong PKCE verifier succeeded")
+	}
+}
+
+func TestExchangeRejectsIDToken(t *testing.T) {
+	otherKeys, err := jwt.LoadKeyRing(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadKeyRing() = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(*oidctest.Claims)
+		sign   func(srv *oidctest.Server, c oidctest.Claims) (string, error)
+		nonce  string
+	}{
+		{
+			name:   "wrong issuer",
+			tamper: func(c *oidctest.Claims) { c.Issuer = "https://evil.example.com" },
+		},
+		{
+			name:   "wrong audience",
+			tamper: func(c *oidctest.Claims) { c.Audience = gojwt.ClaimStrings{"another-client"} },
+		},
+		{
+			name:   "expired",
+			tamper: func(c *oidctest.Claims) { c.ExpiresAt = gojwt.NewNumericDate(time.Now().Add(-2 * time.Minute)) },
+		},
+		{
+			name:   "no expiry",
+			tamper: func(c *oidctest.Claims) { c.ExpiresAt = nil },
+		},
+		{
+			name:  "wrong nonce",
+			nonce: "another nonce",
+		},
+		{
+			name:   "no subject",
+			tamper: func(c *oidctest.Claims) { c.Subject = "" },
+		},
+		{
+			name: "unknown signing key",
+			sign: func(_ *oidctest.Server, c oidctest.Claims) (string, error) { return otherKeys.Sign(c) },
+		},
+		{
+			name: "forged signature",
+			sign: func(srv *oidctest.Server, c oidctest.Claims) (string, error) {
+				token, err := srv.Keys.Sign(c)
+				if err != nil {
+					return "", err
+				}
+				// keep the header and signature, swap in other claims
+				c.Subject = "mallory"
+				forged, err := srv.Keys.Sign(c)
+				if err != nil {
+					return "", err
+				}
+				parts := strings.Split(token, ".")
+				parts[1] = strings.Split(forged, ".")[1]
+				return strings.Join(parts, "."), nil
+			},
+		},
+		{
+			name: "unsigned",
+			sign: func(_ *oidctest.Server, c oidctest.Claims) (string, error) {
+				return gojwt.NewWithClaims(gojwt.SigningMethodNone, c).SignedString(gojwt.UnsafeAllowNoneSignatureType)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := oidctest.NewServer(t)
+			srv.Tamper = tt.tamper
+			if tt.sign != nil {
+				srv.Sign = func(c oidctest.Claims) (string, error) { return tt.sign(srv, c) }
+			}
+			provider := discover(t, srv)
+
+			nonce := tt.nonce
+			if nonce == "" {
+				nonce = "nonce"
+			}
+			code := srv.Authorize(provider.AuthURL("state", "nonce", "verifier"), "alice", "alice@example.com", true)
+			_, err := provider.Exchange(context.Background(), code, "verifier", nonce)
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("Exchange() = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
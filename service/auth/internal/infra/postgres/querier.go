@@ -9,8 +9,30 @@ import (
 )
 
 type Querier interface {
+	ClaimUnverifiedUser(ctx context.Context, id int32) (User, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int32) error
+	DeleteUserMFA(ctx context.Context, userID int32) error
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (int32, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int32) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	GetUserMFA(ctx context.Context, userID int32) (UserMfa, error)
+	GetUserOrganizationID(ctx context.Context, userID int32) (int32, error)
+	InsertEmailVerificationToken(ctx context.Context, arg InsertEmailVerificationTokenParams) error
+	InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error)
+	InsertPasswordResetToken(ctx context.Context, arg InsertPasswordResetTokenParams) error
+	InsertPasswordlessUser(ctx context.Context, email string) (User, error)
+	InsertRecoveryCode(ctx context.Context, arg InsertRecoveryCodeParams) error
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
+	InsertUserIdentity(ctx context.Context, arg InsertUserIdentityParams) (UserIdentity, error)
+	InvalidatePasswordResetTokens(ctx context.Context, userID int32) error
+	ResetPasswordWithToken(ctx context.Context, arg ResetPasswordWithTokenParams) (int32, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertOrganizationMember(ctx context.Context, arg UpsertOrganizationMemberParams) (OrganizationMember, error)
+	UpsertUserMFASecret(ctx context.Context, arg UpsertUserMFASecretParams) (UserMfa, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int32, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int32, error)
+	VerifyEmailWithToken(ctx context.Context, tokenHash string) (int32, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -7,10 +7,71 @@ package authDB
 
 import (
 	"context"
+	"database/sql"
 )
 
+const claimUnverifiedUser = `-- name: ClaimUnverifiedUser :one
+UPDATE users
+SET email_verified_at = NOW(), password_hash = NULL
+WHERE id = $1 AND email_verified_at IS NULL
+RETURNING id, email, password_hash, created_at, role, email_verified_at
+`
+
+func (q *Queries) ClaimUnverifiedUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, claimUnverifiedUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteUserMFA = `-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserMFA(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserMFA, userID)
+	return err
+}
+
+const enableUserMFA = `-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET enabled_at = NOW(), last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL
+RETURNING user_id
+`
+
+type EnableUserMFAParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, enableUserMFA, arg.UserID, arg.LastUsedStep)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, created_at
+SELECT id, email, password_hash, created_at, role, email_verified_at
 FROM users
 WHERE email = $1
 `
@@ -23,19 +84,188 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, email, password_hash, created_at, role, email_verified_at
+FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT id, user_id, provider, subject, email, created_at
+FROM user_identities
+WHERE provider = $1 AND subject = $2
+`
+
+type GetUserIdentityParams struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentity, arg.Provider, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserMFA = `-- name: GetUserMFA :one
+SELECT user_id, secret, last_used_step, enabled_at, created_at
+FROM user_mfa
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserMFA(ctx context.Context, userID int32) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, getUserMFA, userID)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.LastUsedStep,
+		&i.EnabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserOrganizationID = `-- name: GetUserOrganizationID :one
+SELECT organization_id
+FROM organization_members
+WHERE user_id = $1
+`
+
+func (q *Queries) GetUserOrganizationID(ctx context.Context, userID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getUserOrganizationID, userID)
+	var organization_id int32
+	err := row.Scan(&organization_id)
+	return organization_id, err
+}
+
+const insertEmailVerificationToken = `-- name: InsertEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+VALUES ($1, $2, NOW() + $3::int * INTERVAL '1 second')
+`
+
+type InsertEmailVerificationTokenParams struct {
+	UserID     int32  `json:"user_id"`
+	TokenHash  string `json:"token_hash"`
+	TtlSeconds int32  `json:"ttl_seconds"`
+}
+
+func (q *Queries) InsertEmailVerificationToken(ctx context.Context, arg InsertEmailVerificationTokenParams) error {
+	_, err := q.db.ExecContext(ctx, insertEmailVerificationToken, arg.UserID, arg.TokenHash, arg.TtlSeconds)
+	return err
+}
+
+const insertOrganization = `-- name: InsertOrganization :one
+INSERT INTO organizations (name, slug)
+VALUES ($1, $2)
+RETURNING id, name, slug, created_at
+`
+
+type InsertOrganizationParams struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func (q *Queries) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganization, arg.Name, arg.Slug)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Slug,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const insertPasswordResetToken = `-- name: InsertPasswordResetToken :exec
+INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+VALUES ($1, $2, NOW() + $3::int * INTERVAL '1 second')
+`
+
+type InsertPasswordResetTokenParams struct {
+	UserID     int32  `json:"user_id"`
+	TokenHash  string `json:"token_hash"`
+	TtlSeconds int32  `json:"ttl_seconds"`
+}
+
+func (q *Queries) InsertPasswordResetToken(ctx context.Context, arg InsertPasswordResetTokenParams) error {
+	_, err := q.db.ExecContext(ctx, insertPasswordResetToken, arg.UserID, arg.TokenHash, arg.TtlSeconds)
+	return err
+}
+
+const insertPasswordlessUser = `-- name: InsertPasswordlessUser :one
+INSERT INTO users (email, email_verified_at)
+VALUES ($1, NOW())
+RETURNING id, email, password_hash, created_at, role, email_verified_at
+`
+
+func (q *Queries) InsertPasswordlessUser(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertPasswordlessUser, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const insertRecoveryCode = `-- name: InsertRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (user_id, code_hash)
+VALUES ($1, $2)
+`
+
+type InsertRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) InsertRecoveryCode(ctx context.Context, arg InsertRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, insertRecoveryCode, arg.UserID, arg.CodeHash)
+	return err
+}
+
 const insertUser = `-- name: InsertUser :one
 INSERT INTO users (email, password_hash)
 VALUES ($1, $2)
-RETURNING id, email, password_hash, created_at
+RETURNING id, email, password_hash, created_at, role, email_verified_at
 `
 
 type InsertUserParams struct {
-	Email        string `json:"email"`
-	PasswordHash string `json:"password_hash"`
+	Email        string         `json:"email"`
+	PasswordHash sql.NullString `json:"password_hash"`
 }
 
 func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
@@ -46,6 +276,212 @@ func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, e
 		&i.Email,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const insertUserIdentity = `-- name: InsertUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, provider, subject, email, created_at
+`
+
+type InsertUserIdentityParams struct {
+	UserID   int32  `json:"user_id"`
+	Provider string `json:"provider"`
+	Subject  string `json:"subject"`
+	Email    string `json:"email"`
+}
+
+func (q *Queries) InsertUserIdentity(ctx context.Context, arg InsertUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, insertUserIdentity,
+		arg.UserID,
+		arg.Provider,
+		arg.Subject,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
 	)
 	return i, err
 }
+
+const invalidatePasswordResetTokens = `-- name: InvalidatePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) InvalidatePasswordResetTokens(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, invalidatePasswordResetTokens, userID)
+	return err
+}
+
+const resetPasswordWithToken = `-- name: ResetPasswordWithToken :one
+WITH used AS (
+    UPDATE password_reset_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET password_hash = $2
+FROM used
+WHERE users.id = used.user_id
+RETURNING users.id
+`
+
+type ResetPasswordWithTokenParams struct {
+	TokenHash    string         `json:"token_hash"`
+	PasswordHash sql.NullString `json:"password_hash"`
+}
+
+func (q *Queries) ResetPasswordWithToken(ctx context.Context, arg ResetPasswordWithTokenParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, resetPasswordWithToken, arg.TokenHash, arg.PasswordHash)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, email, password_hash, created_at, role, email_verified_at
+`
+
+type UpdateUserRoleParams struct {
+	ID   int32  `json:"id"`
+	Role string `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const upsertOrganizationMember = `-- name: UpsertOrganizationMember :one
+INSERT INTO organization_members (user_id, organization_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET organization_id = EXCLUDED.organization_id
+RETURNING user_id, organization_id, created_at
+`
+
+type UpsertOrganizationMemberParams struct {
+	UserID         int32 `json:"user_id"`
+	OrganizationID int32 `json:"organization_id"`
+}
+
+func (q *Queries) UpsertOrganizationMember(ctx context.Context, arg UpsertOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationMember, arg.UserID, arg.OrganizationID)
+	var i OrganizationMember
+	err := row.Scan(
+		&i.UserID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertUserMFASecret = `-- name: UpsertUserMFASecret :one
+INSERT INTO user_mfa (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+WHERE user_mfa.enabled_at IS NULL
+RETURNING user_id, secret, last_used_step, enabled_at, created_at
+`
+
+type UpsertUserMFASecretParams struct {
+	UserID int32  `json:"user_id"`
+	Secret string `json:"secret"`
+}
+
+func (q *Queries) UpsertUserMFASecret(ctx context.Context, arg UpsertUserMFASecretParams) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserMFASecret, arg.UserID, arg.Secret)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.LastUsedStep,
+		&i.EnabledAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING id
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :one
+UPDATE user_mfa
+SET last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2
+RETURNING user_id
+`
+
+type UseTOTPStepParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, useTOTPStep, arg.UserID, arg.LastUsedStep)
+	var user_id int32
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const verifyEmailWithToken = `-- name: VerifyEmailWithToken :one
+WITH used AS (
+    UPDATE email_verification_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET email_verified_at = COALESCE(users.email_verified_at, NOW())
+FROM used
+WHERE users.id = used.user_id
+RETURNING users.id
+`
+
+func (q *Queries) VerifyEmailWithToken(ctx context.Context, tokenHash string) (int32, error) {
+	row := q.db.QueryRowContext(ctx, verifyEmailWithToken, tokenHash)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
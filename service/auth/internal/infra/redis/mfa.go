@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrMFAChallengeNotFound means the challenge token is unknown, expired or used.
+	ErrMFAChallengeNotFound = errors.New("mfa challenge not found")
+	// ErrTooManyMFAFailures means the user entered too many wrong codes recently.
+	ErrTooManyMFAFailures = errors.New("too many mfa failures")
+)
+
+const (
+	// mfaChallengeExpiry is how long a user has to enter their code after the password.
+	mfaChallengeExpiry = 5 * time.Minute
+	// maxMFAFailures wrong codes within mfaFailureWindow block the second step, since
+	// a six digit code can be guessed with enough attempts.
+	maxMFAFailures   = 10
+	mfaFailureWindow = time.Hour
+)
+
+// MFAChallenge keeps the logins waiting for a second factor, and counts wrong codes.
+//
+//	mfa:challenge:<token>  user id, until the code is entered or it expires
+//	mfa:failures:<id>      wrong codes of the user within mfaFailureWindow
+type MFAChallenge struct {
+	client *redis.Client
+}
+
+func NewMFAChallenge(client *redis.Client) MFAChallenge {
+	return MFAChallenge{client: client}
+}
+
+func (m *MFAChallenge) generateKey(token string) string {
+	return fmt.Sprintf("mfa:challenge:%s", token)
+}
+
+func (m *MFAChallenge) generateFailuresKey(userID int32) string {
+	return fmt.Sprintf("mfa:failures:%d", userID)
+}
+
+// Create records that userID passed the first factor, under token.
+func (m *MFAChallenge) Create(ctx context.Context, token string, userID int32) error {
+	return m.client.Set(ctx, m.generateKey(token), userID, mfaChallengeExpiry).Err()
+}
+
+// User returns who the challenge is for. The challenge stays until Complete, so the
+// user can retry a mistyped code.
+func (m *MFAChallenge) User(ctx context.Context, token string) (int32, error) {
+	id, err := m.client.Get(ctx, m.generateKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrMFAChallengeNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	userID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("decode mfa challenge: %w", err)
+	}
+	return int32(userID), nil
+}
+
+// Complete deletes the challenge once its code was accepted. Of two requests
+// completing the same challenge only one succeeds.
+func (m *MFAChallenge) Complete(ctx context.Context, token string) error {
+	n, err := m.client.Del(ctx, m.generateKey(token)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMFAChallengeNotFound
+	}
+	return nil
+}
+
+// CheckFailures returns ErrTooManyMFAFailures while the user may not try codes.
+func (m *MFAChallenge) CheckFailures(ctx context.Context, userID int32) error {
+	n, err := m.client.Get(ctx, m.generateFailuresKey(userID)).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if n >= maxMFAFailures {
+		return ErrTooManyMFAFailures
+	}
+	return nil
+}
+
+// Fail counts a wrong code against the user.
+func (m *MFAChallenge) Fail(ctx context.Context, userID int32) error {
+	key := m.generateFailuresKey(userID)
+	pipe := m.client.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, mfaFailureWindow)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ResetFailures forgets the user's wrong codes after a right one.
+func (m *MFAChallenge) ResetFailures(ctx context.Context, userID int32) error {
+	return m.client.Del(ctx, m.generateFailuresKey(userID)).Err()
+}
@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLoginStateNotFound means the state of a callback is unknown, expired or used.
+var ErrLoginStateNotFound = errors.New("login state not found")
+
+// oidcStateExpiry is how long a user has to sign in at the provider.
+const oidcStateExpiry = 10 * time.Minute
+
+// LoginState is what an OIDC callback needs from the request that started the login.
+type LoginState struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+type OIDCState struct {
+	client *redis.Client
+}
+
+func NewOIDCState(client *redis.Client) OIDCState {
+	return OIDCState{client: client}
+}
+
+func (s *OIDCState) generateKey(state string) string {
+	return fmt.Sprintf("oidc:state:%s", state)
+}
+
+func (s *OIDCState) Save(ctx context.Context, state string, login LoginState) error {
+	data, err := json.Marshal(login)
+	if err != nil {
+		return fmt.Errorf("encode login state: %w", err)
+	}
+	return s.client.Set(ctx, s.generateKey(state), data, oidcStateExpiry).Err()
+}
+
+// Take returns the login state and deletes it, so a callback cannot be replayed.
+func (s *OIDCState) Take(ctx context.Context, state string) (LoginState, error) {
+	data, err := s.client.GetDel(ctx, s.generateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return LoginState{}, ErrLoginStateNotFound
+	}
+	if err != nil {
+		return LoginState{}, err
+	}
+
+	var login LoginState
+	if err := json.Unmarshal(data, &login); err != nil {
+		return LoginState{}, fmt.Errorf("decode login state: %w", err)
+	}
+	return login, nil
+}
@@ -4,11 +4,51 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"ticket-tix/common/pkg/jwt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// ErrRefreshTokenRevoked means the token's family no longer exists: it was logged
+	// out, revoked or expired.
+	ErrRefreshTokenRevoked = errors.New("refresh token revoked")
+	// ErrRefreshTokenReused means a token that was already rotated was presented
+	// again. The family has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrSessionNotFound means the user has no session with that ID.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// Device describes where a session was started or last used from.
+type Device struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is a token family as shown to its user: one login on one device.
+type Session struct {
+	ID         string
+	Device     Device
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// A token family is the chain of refresh tokens issued from one login, and is what
+// users see as a session. Only the newest token of a family is valid; presenting an
+// older one means it was copied, so the whole family is revoked.
+//
+//	refresh-family:<fid>       hash {user_id, current, user_agent, ip, created_at,
+//	                           last_used_at}, expires with the newest token
+//	user:refresh-families:<id> set of the user's fids
+//
+// Access tokens cannot be deleted, so they are revoked by keys AuthMiddleware checks:
+//
+//	blacklist:access:<jti>        until the token expires
+//	user:tokens-valid-after:<id>  unix time; tokens issued earlier are rejected
 type RefreshToken struct {
 	client *redis.Client
 }
@@ -17,48 +57,178 @@ func NewRefreshToken(client *redis.Client) RefreshToken {
 	return RefreshToken{client: client}
 }
 
-func (r *RefreshToken) generateRefreshTokenKey(userID int32, token string) string {
-	return fmt.Sprintf("user:refresh-token:%d:%s", userID, token)
+func (r *RefreshToken) generateFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh-family:%s", familyID)
+}
+
+func (r *RefreshToken) generateValidAfterKey(userID int32) string {
+	return fmt.Sprintf("user:tokens-valid-after:%d", userID)
 }
 
-func (r *RefreshToken) generateAccessTokenKey(userID int32, token string) string {
-	return fmt.Sprintf("user:access-token:%d:%s", userID, token)
+func (r *RefreshToken) generateUserFamiliesKey(userID int32) string {
+	return fmt.Sprintf("user:refresh-families:%d", userID)
 }
 
-func (r *RefreshToken) SaveRefreshToken(ctx context.Context, userID int32, token string) error {
-	key := r.generateRefreshTokenKey(userID, token)
-	return r.client.Set(ctx, key, true, jwt.RefreshTokenExpiry).Err()
+// StartFamily records tokenID as the first token of a new family.
+func (r *RefreshToken) StartFamily(ctx context.Context, userID int32, familyID, tokenID string, device Device) error {
+	familyKey := r.generateFamilyKey(familyID)
+	userKey := r.generateUserFamiliesKey(userID)
+	now := time.Now().Unix()
+
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, familyKey,
+		"user_id", userID,
+		"current", tokenID,
+		"user_agent", device.UserAgent,
+		"ip", device.IP,
+		"created_at", now,
+		"last_used_at", now,
+	)
+	pipe.Expire(ctx, familyKey, jwt.RefreshTokenExpiry)
+	pipe.SAdd(ctx, userKey, familyID)
+	pipe.Expire(ctx, userKey, jwt.RefreshTokenExpiry)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-func (r *RefreshToken) ValidateRefreshToken(ctx context.Context, userID int32, token string) (bool, error) {
-	key := r.generateRefreshTokenKey(userID, token)
-	tokenErr := r.client.Get(ctx, key).Err()
+// rotateScript swaps the family's current token for a new one when the presented
+// token is the current one, and deletes the family when it is an older one.
+// Returns 1 on rotation, 0 when the family is gone, -1 on reuse.
+var rotateScript = redis.NewScript(`
+local family = redis.call('HMGET', KEYS[1], 'user_id', 'current')
+if not family[1] or family[1] ~= ARGV[1] then
+	return 0
+end
+if family[2] ~= ARGV[2] then
+	redis.call('DEL', KEYS[1])
+	redis.call('SREM', KEYS[2], ARGV[4])
+	return -1
+end
+redis.call('HSET', KEYS[1], 'current', ARGV[3], 'ip', ARGV[6], 'last_used_at', ARGV[7])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+return 1
+`)
 
-	if errors.Is(tokenErr, redis.Nil) {
-		return false, nil
-	} else if tokenErr != nil {
-		return false, tokenErr
+// RotateRefreshToken makes newTokenID the valid token of the family in place of
+// oldTokenID and records ip as where the session was last used.
+func (r *RefreshToken) RotateRefreshToken(ctx context.Context, userID int32, familyID, oldTokenID, newTokenID, ip string) error {
+	keys := []string{r.generateFamilyKey(familyID), r.generateUserFamiliesKey(userID)}
+	res, err := rotateScript.Run(ctx, r.client, keys,
+		strconv.Itoa(int(userID)), oldTokenID, newTokenID, familyID, jwt.RefreshTokenExpiry.Milliseconds(),
+		ip, time.Now().Unix(),
+	).Int()
+	if err != nil {
+		return fmt.Errorf("rotate refresh token: %w", err)
 	}
-	return true, nil
+
+	switch res {
+	case 1:
+		return nil
+	case -1:
+		return ErrRefreshTokenReused
+	}
+	return ErrRefreshTokenRevoked
 }
 
-func (r *RefreshToken) RevokeUser(ctx context.Context, userID int32) error {
-	pattern := fmt.Sprintf("user:refresh-token:%d:*", userID)
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
-			return err
+// ListSessions returns the user's live sessions, most recently used first. Families
+// that expired are dropped from the user's set on the way.
+func (r *RefreshToken) ListSessions(ctx context.Context, userID int32) ([]Session, error) {
+	userKey := r.generateUserFamiliesKey(userID)
+	families, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(families))
+	for i, familyID := range families {
+		cmds[i] = pipe.HGetAll(ctx, r.generateFamilyKey(familyID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(families))
+	var expired []interface{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if fields["user_id"] != strconv.Itoa(int(userID)) {
+			expired = append(expired, families[i])
+			continue
 		}
+		sessions = append(sessions, Session{
+			ID:         families[i],
+			Device:     Device{UserAgent: fields["user_agent"], IP: fields["ip"]},
+			CreatedAt:  unixField(fields["created_at"]),
+			LastUsedAt: unixField(fields["last_used_at"]),
+		})
 	}
-	return iter.Err()
+	if len(expired) > 0 {
+		r.client.SRem(ctx, userKey, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// RevokeSession ends one of the user's sessions. Sessions of other users are
+// reported as not found.
+func (r *RefreshToken) RevokeSession(ctx context.Context, userID int32, sessionID string) error {
+	owner, err := r.client.HGet(ctx, r.generateFamilyKey(sessionID), "user_id").Result()
+	if errors.Is(err, redis.Nil) || (err == nil && owner != strconv.Itoa(int(userID))) {
+		return ErrSessionNotFound
+	} else if err != nil {
+		return err
+	}
+	return r.RevokeFamily(ctx, userID, sessionID)
 }
 
-func (r *RefreshToken) RevokeRefreshToken(ctx context.Context, userID int32, token string) error {
-	key := r.generateRefreshTokenKey(userID, token)
-	return r.client.Del(ctx, key).Err()
+// RevokeFamily invalidates every token of the family, i.e. logs out one device.
+func (r *RefreshToken) RevokeFamily(ctx context.Context, userID int32, familyID string) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, r.generateFamilyKey(familyID))
+	pipe.SRem(ctx, r.generateUserFamiliesKey(userID), familyID)
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
-func (r *RefreshToken) BlackListAccessToken(ctx context.Context, token string) error {
-	key := fmt.Sprintf("blacklist:access:%s", token)
-	return r.client.Set(ctx, key, "1", jwt.AccessTokenExpiry).Err()
+// RevokeUser invalidates every token the user holds. Access tokens are cut off by
+// their issue time; once the newest of them has expired the cutoff is not needed.
+func (r *RefreshToken) RevokeUser(ctx context.Context, userID int32) error {
+	validAfter := r.generateValidAfterKey(userID)
+	if err := r.client.Set(ctx, validAfter, time.Now().Unix(), jwt.AccessTokenExpiry).Err(); err != nil {
+		return err
+	}
+
+	userKey := r.generateUserFamiliesKey(userID)
+	families, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(families)+1)
+	for _, familyID := range families {
+		keys = append(keys, r.generateFamilyKey(familyID))
+	}
+	keys = append(keys, userKey)
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// BlackListAccessToken rejects access token tokenID until it expires at expiresAt.
+func (r *RefreshToken) BlackListAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if tokenID == "" || ttl <= 0 {
+		return nil
+	}
+	key := fmt.Sprintf("blacklist:access:%s", tokenID)
+	return r.client.Set(ctx, key, "1", ttl).Err()
+}
+
+func unixField(v string) time.Time {
+	sec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
 }
@@ -1,15 +1,55 @@
 package model
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type UserRepo interface {
 	InsertUser(ctx context.Context, email string, password string) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int32) (User, error)
+	UpdateUserRole(ctx context.Context, id int32, role string) (User, error)
+	GetUserOrganizationID(ctx context.Context, userID int32) (int32, error)
+	InsertOrganization(ctx context.Context, name, slug string) (Organization, error)
+	SetOrganizationMember(ctx context.Context, orgID, userID int32) error
+	InsertEmailVerificationToken(ctx context.Context, userID int32, tokenHash string, ttl time.Duration) error
+	VerifyEmail(ctx context.Context, tokenHash string) (int32, error)
+	InsertPasswordResetToken(ctx context.Context, userID int32, tokenHash string, ttl time.Duration) error
+	ResetPassword(ctx context.Context, tokenHash, passwordHash string) (int32, error)
+	InvalidatePasswordResetTokens(ctx context.Context, userID int32) error
+	GetIdentityUserID(ctx context.Context, provider, subject string) (int32, error)
+	LinkIdentity(ctx context.Context, userID int32, identity Identity) error
+	InsertUserWithIdentity(ctx context.Context, identity Identity) (User, error)
+	ClaimUnverifiedUser(ctx context.Context, id int32) (User, error)
+	SaveMFASecret(ctx context.Context, userID int32, secret string) error
+	GetMFA(ctx context.Context, userID int32) (MFA, error)
+	EnableMFA(ctx context.Context, userID int32, step int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID int32, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int32, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int32, codeHashes []string) error
+	DeleteMFA(ctx context.Context, userID int32) error
 }
 
 type UserService interface {
 	RegisterUser(ctx context.Context, email, password string) (User, error)
-	Login(ctx context.Context, email, password string) (LoginResponse, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)
-	LogOut(ctx context.Context, userId int32, token string, allDevices bool) error
+	Login(ctx context.Context, email, password string, device Device) (LoginResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string, device Device) (TokenPair, error)
+	LogOut(ctx context.Context, userId int32, access AccessToken, refreshToken string, allDevices bool) error
+	OIDCAuthURL(ctx context.Context, provider string) (authURL, state string, err error)
+	OIDCLogin(ctx context.Context, provider, code, state string, device Device) (LoginResponse, error)
+	VerifyMFA(ctx context.Context, mfaToken, code string, device Device) (LoginResponse, error)
+	EnrollMFA(ctx context.Context, userID int32) (MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID int32, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID int32, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int32, code string) ([]string, error)
+	SendVerificationEmail(ctx context.Context, userID int32) error
+	VerifyEmail(ctx context.Context, token string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	ListSessions(ctx context.Context, userID int32) ([]Session, error)
+	RevokeSession(ctx context.Context, userID int32, sessionID string) error
+	SetUserRole(ctx context.Context, userID int32, role string) (User, error)
+	CreateOrganization(ctx context.Context, name, slug string) (Organization, error)
+	AddOrganizationMember(ctx context.Context, orgID, userID int32) error
 }
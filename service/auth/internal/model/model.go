@@ -1,15 +1,82 @@
 package model
 
+import "time"
+
 type User struct {
 	ID           int32
 	Email        string
 	PasswordHash string
+	Role         string
+	OrgID        int32
+	// EmailVerified is set once the user followed the link sent on registration.
+	EmailVerified bool
+}
+
+type Organization struct {
+	ID   int32
+	Name string
+	Slug string
 }
 
+// LoginResponse carries the tokens of the new session, or, for users with two-factor
+// authentication, only MFAToken to complete the login with a code.
 type LoginResponse struct {
 	User         User
 	RefreshToken string
 	AccessToken  string
+	MFARequired  bool
+	MFAToken     string
+}
+
+// TokenPair is what a refresh returns: the refresh token replaces the one presented.
+type TokenPair struct {
+	RefreshToken string
+	AccessToken  string
+}
+
+// Identity is a user's account at an OIDC provider, as stated by its ID token.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// MFA is a user's TOTP authenticator. It is enabled once the user confirmed a code
+// from it.
+type MFA struct {
+	Secret  string
+	Enabled bool
+}
+
+// MFAEnrollment is what the user adds to their authenticator app: the secret to type
+// in, or the otpauth URL as a QR code to scan.
+type MFAEnrollment struct {
+	Secret string
+	URL    string
+	// QRCode is a PNG data URL.
+	QRCode string
+}
+
+// Device is what a client is known by in the session list.
+type Device struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is one login of a user, kept alive by refreshing its refresh token.
+type Session struct {
+	ID         string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+}
+
+// AccessToken identifies the access token a request was made with.
+type AccessToken struct {
+	ID        string
+	ExpiresAt time.Time
 }
 
 type LoginRequest struct {
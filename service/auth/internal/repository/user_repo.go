@@ -3,34 +3,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	authDB "ticket-tix/service/auth/internal/infra/postgres"
 	"ticket-tix/service/auth/internal/model"
+	"time"
 )
 
 type userRepo struct {
-	db *authDB.Queries
+	db    *authDB.Queries
+	rawDB *sql.DB
 }
 
 func NewUserRepo(db *sql.DB) model.UserRepo {
 	return &userRepo{
-		db: authDB.New(db),
+		db:    authDB.New(db),
+		rawDB: db,
 	}
 }
 
 func (r *userRepo) InsertUser(ctx context.Context, email string, password string) (model.User, error) {
 	user, err := r.db.InsertUser(ctx, authDB.InsertUserParams{
 		Email:        email,
-		PasswordHash: password,
+		PasswordHash: sql.NullString{String: password, Valid: true},
 	})
 
 	if err != nil {
 		return model.User{}, err
 	}
-	return model.User{
-		ID:           user.ID,
-		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-	}, nil
+	return toModelUser(user), nil
 }
 
 func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
@@ -38,10 +39,317 @@ func (r *userRepo) GetUserByEmail(ctx context.Context, email string) (model.User
 	if err != nil {
 		return model.User{}, err
 	}
+	return toModelUser(user), nil
+}
+
+func (r *userRepo) GetUserByID(ctx context.Context, id int32) (model.User, error) {
+	user, err := r.db.GetUserByID(ctx, id)
+	if err != nil {
+		return model.User{}, err
+	}
+	return toModelUser(user), nil
+}
+
+func (r *userRepo) UpdateUserRole(ctx context.Context, id int32, role string) (model.User, error) {
+	user, err := r.db.UpdateUserRole(ctx, authDB.UpdateUserRoleParams{
+		ID:   id,
+		Role: role,
+	})
+	if err != nil {
+		return model.User{}, err
+	}
+	return toModelUser(user), nil
+}
+
+// GetUserOrganizationID returns the user's organization, or 0 when they have none.
+func (r *userRepo) GetUserOrganizationID(ctx context.Context, userID int32) (int32, error) {
+	orgID, err := r.db.GetUserOrganizationID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get user organization: %w", err)
+	}
+	return orgID, nil
+}
+
+func (r *userRepo) InsertOrganization(ctx context.Context, name, slug string) (model.Organization, error) {
+	org, err := r.db.InsertOrganization(ctx, authDB.InsertOrganizationParams{
+		Name: name,
+		Slug: slug,
+	})
+	if err != nil {
+		return model.Organization{}, fmt.Errorf("insert organization: %w", err)
+	}
+	return model.Organization{ID: org.ID, Name: org.Name, Slug: org.Slug}, nil
+}
+
+func (r *userRepo) SetOrganizationMember(ctx context.Context, orgID, userID int32) error {
+	_, err := r.db.UpsertOrganizationMember(ctx, authDB.UpsertOrganizationMemberParams{
+		UserID:         userID,
+		OrganizationID: orgID,
+	})
+	if err != nil {
+		return fmt.Errorf("set organization member: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) InsertEmailVerificationToken(ctx context.Context, userID int32, tokenHash string, ttl time.Duration) error {
+	err := r.db.InsertEmailVerificationToken(ctx, authDB.InsertEmailVerificationTokenParams{
+		UserID:     userID,
+		TokenHash:  tokenHash,
+		TtlSeconds: int32(ttl.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("insert email verification token: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail uses up the token and marks its user verified. It returns sql.ErrNoRows
+// when the token is unknown, used or expired.
+func (r *userRepo) VerifyEmail(ctx context.Context, tokenHash string) (int32, error) {
+	userID, err := r.db.VerifyEmailWithToken(ctx, tokenHash)
+	if err != nil {
+		return 0, fmt.Errorf("verify email: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *userRepo) InsertPasswordResetToken(ctx context.Context, userID int32, tokenHash string, ttl time.Duration) error {
+	err := r.db.InsertPasswordResetToken(ctx, authDB.InsertPasswordResetTokenParams{
+		UserID:     userID,
+		TokenHash:  tokenHash,
+		TtlSeconds: int32(ttl.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("insert password reset token: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword uses up the token and sets its user's password in one statement. It
+// returns sql.ErrNoRows when the token is unknown, used or expired.
+func (r *userRepo) ResetPassword(ctx context.Context, tokenHash, passwordHash string) (int32, error) {
+	userID, err := r.db.ResetPasswordWithToken(ctx, authDB.ResetPasswordWithTokenParams{
+		TokenHash:    tokenHash,
+		PasswordHash: sql.NullString{String: passwordHash, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("reset password: %w", err)
+	}
+	return userID, nil
+}
+
+func (r *userRepo) InvalidatePasswordResetTokens(ctx context.Context, userID int32) error {
+	if err := r.db.InvalidatePasswordResetTokens(ctx, userID); err != nil {
+		return fmt.Errorf("invalidate password reset tokens: %w", err)
+	}
+	return nil
+}
+
+// GetIdentityUserID returns the user the provider account is linked to.
+func (r *userRepo) GetIdentityUserID(ctx context.Context, provider, subject string) (int32, error) {
+	identity, err := r.db.GetUserIdentity(ctx, authDB.GetUserIdentityParams{
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("get user identity: %w", err)
+	}
+	return identity.UserID, nil
+}
+
+func (r *userRepo) LinkIdentity(ctx context.Context, userID int32, identity model.Identity) error {
+	_, err := r.db.InsertUserIdentity(ctx, authDB.InsertUserIdentityParams{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
+
+// InsertUserWithIdentity creates a verified user without a password, linked to the
+// provider account.
+func (r *userRepo) InsertUserWithIdentity(ctx context.Context, identity model.Identity) (model.User, error) {
+	tx, err := r.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return model.User{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
 
+	user, err := q.InsertPasswordlessUser(ctx, identity.Email)
+	if err != nil {
+		return model.User{}, fmt.Errorf("insert user: %w", err)
+	}
+	_, err = q.InsertUserIdentity(ctx, authDB.InsertUserIdentityParams{
+		UserID:   user.ID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return model.User{}, fmt.Errorf("link identity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.User{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return toModelUser(user), nil
+}
+
+// ClaimUnverifiedUser marks the user verified and removes their password. It returns
+// sql.ErrNoRows when the user was verified already.
+func (r *userRepo) ClaimUnverifiedUser(ctx context.Context, id int32) (model.User, error) {
+	user, err := r.db.ClaimUnverifiedUser(ctx, id)
+	if err != nil {
+		return model.User{}, fmt.Errorf("claim unverified user: %w", err)
+	}
+	return toModelUser(user), nil
+}
+
+// SaveMFASecret stores the secret of an enrollment that is not confirmed yet,
+// replacing an earlier one. It returns sql.ErrNoRows when MFA is enabled already.
+func (r *userRepo) SaveMFASecret(ctx context.Context, userID int32, secret string) error {
+	_, err := r.db.UpsertUserMFASecret(ctx, authDB.UpsertUserMFASecretParams{
+		UserID: userID,
+		Secret: secret,
+	})
+	if err != nil {
+		return fmt.Errorf("save mfa secret: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) GetMFA(ctx context.Context, userID int32) (model.MFA, error) {
+	mfa, err := r.db.GetUserMFA(ctx, userID)
+	if err != nil {
+		return model.MFA{}, fmt.Errorf("get mfa: %w", err)
+	}
+	return model.MFA{Secret: mfa.Secret, Enabled: mfa.EnabledAt.Valid}, nil
+}
+
+// EnableMFA enables the enrolled secret, whose code of step was just used, together
+// with the user's recovery codes. It returns sql.ErrNoRows when MFA is enabled
+// already.
+func (r *userRepo) EnableMFA(ctx context.Context, userID int32, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	_, err = q.EnableUserMFA(ctx, authDB.EnableUserMFAParams{
+		UserID:       userID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return fmt.Errorf("enable mfa: %w", err)
+	}
+	if err := replaceRecoveryCodes(ctx, q, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep records that the code of step was used. It returns sql.ErrNoRows when
+// that or a later code was used before, so a code works once.
+func (r *userRepo) UseTOTPStep(ctx context.Context, userID int32, step int64) error {
+	_, err := r.db.UseTOTPStep(ctx, authDB.UseTOTPStepParams{
+		UserID:       userID,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return fmt.Errorf("use totp step: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode uses up the recovery code. It returns sql.ErrNoRows when the user
+// has no such unused code.
+func (r *userRepo) UseRecoveryCode(ctx context.Context, userID int32, codeHash string) error {
+	_, err := r.db.UseRecoveryCode(ctx, authDB.UseRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return fmt.Errorf("use recovery code: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) ReplaceRecoveryCodes(ctx context.Context, userID int32, codeHashes []string) error {
+	tx, err := r.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, r.db.WithTx(tx), userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func (r *userRepo) DeleteMFA(ctx context.Context, userID int32) error {
+	tx, err := r.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	if err := q.DeleteUserMFA(ctx, userID); err != nil {
+		return fmt.Errorf("delete mfa: %w", err)
+	}
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, q *authDB.Queries, userID int32, codeHashes []string) error {
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		err := q.InsertRecoveryCode(ctx, authDB.InsertRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hash,
+		})
+		if err != nil {
+			return fmt.Errorf("insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+func toModelUser(user authDB.User) model.User {
 	return model.User{
 		ID:           user.ID,
 		Email:        user.Email,
-		PasswordHash: user.PasswordHash,
-	}, nil
+		PasswordHash: user.PasswordHash.String,
+		Role:         user.Role,
+		// verified users keep the flag; it is never cleared
+		EmailVerified: user.EmailVerifiedAt.Valid,
+	}
 }
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"ticket-tix/common/pkg/mail"
+	"ticket-tix/service/auth/internal/model"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationExpiry = 24 * time.Hour
+	passwordResetExpiry     = time.Hour
+)
+
+var (
+	ErrInvalidAccountToken = errors.New("invalid or expired link")
+	ErrAlreadyVerified     = errors.New("email already verified")
+)
+
+// SendVerificationEmail sends the user a new verification link. Earlier links keep
+// working until they expire.
+func (s *userService) SendVerificationEmail(ctx context.Context, userID int32) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.EmailVerified {
+		return ErrAlreadyVerified
+	}
+	return s.sendVerification(ctx, user)
+}
+
+func (s *userService) sendVerification(ctx context.Context, user model.User) error {
+	token, hash, err := newAccountToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.InsertEmailVerificationToken(ctx, user.ID, hash, emailVerificationExpiry); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Welcome to TicketTix!\n\nConfirm your email address to start booking:\n%s\n\n"+
+			"The link expires in %s.\n", link, emailVerificationExpiry),
+	})
+}
+
+// VerifyEmail marks the token's user verified. Access tokens carry the flag from the
+// next refresh or login.
+func (s *userService) VerifyEmail(ctx context.Context, token string) error {
+	if _, err := s.repo.VerifyEmail(ctx, hashAccountToken(token)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAccountToken
+		}
+		return err
+	}
+	return nil
+}
+
+// ForgotPassword emails a reset link when the address belongs to a user. It reports
+// success for unknown addresses too, so it cannot be used to find accounts.
+func (s *userService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// only the newest link works
+	if err := s.repo.InvalidatePasswordResetTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	token, hash, err := newAccountToken()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.InsertPasswordResetToken(ctx, user.ID, hash, passwordResetExpiry); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of your TicketTix account.\n\n"+
+			"Choose a new password here:\n%s\n\nThe link expires in %s. If it was not you, ignore this email.\n",
+			link, passwordResetExpiry),
+	})
+}
+
+// ResetPassword sets a new password with a reset token, which can be used once, and
+// logs the user out everywhere.
+func (s *userService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hashPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.repo.ResetPassword(ctx, hashAccountToken(token), string(hashPassword))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAccountToken
+		}
+		return err
+	}
+
+	if err := s.repo.InvalidatePasswordResetTokens(ctx, userID); err != nil {
+		log.Printf("invalidate reset tokens of user %d: %v", userID, err)
+	}
+	return s.tokenCache.RevokeUser(ctx, userID)
+}
+
+// newAccountToken returns a random token for an email link and the hash that is stored.
+func newAccountToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("generate token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashAccountToken(token), nil
+}
+
+func hashAccountToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
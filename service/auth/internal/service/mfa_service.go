@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/png"
+	"log"
+	"strings"
+	"ticket-tix/service/auth/internal/infra/redis"
+	"ticket-tix/service/auth/internal/model"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// mfaIssuer names the account in authenticator apps.
+	mfaIssuer = "TicketTix"
+	// totpPeriod and totpSkew are the RFC 6238 defaults: a code changes every 30s, and
+	// the previous and next codes are accepted too for clocks that drift.
+	totpPeriod = 30
+	totpSkew   = 1
+	// recoveryCodeCount codes are issued at a time, each working once.
+	recoveryCodeCount = 10
+)
+
+var (
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication not enabled")
+	ErrMFANotEnrolled      = errors.New("start two-factor enrollment first")
+	ErrInvalidMFACode      = errors.New("invalid authentication code")
+	ErrInvalidMFAChallenge = errors.New("login expired or already completed")
+	ErrTooManyMFAFailures  = errors.New("too many invalid codes, try again later")
+)
+
+var totpOpts = totp.ValidateOpts{
+	Period:    totpPeriod,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// EnrollMFA creates a new TOTP secret for the user to add to their authenticator.
+// It is not used before ConfirmMFA, and enrolling again replaces it.
+func (s *userService) EnrollMFA(ctx context.Context, userID int32) (model.MFAEnrollment, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.MFAEnrollment{}, ErrUserNotFound
+		}
+		return model.MFAEnrollment{}, err
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      mfaIssuer,
+		AccountName: user.Email,
+		Period:      totpPeriod,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return model.MFAEnrollment{}, fmt.Errorf("generate totp secret: %w", err)
+	}
+
+	if err := s.repo.SaveMFASecret(ctx, userID, key.Secret()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.MFAEnrollment{}, ErrMFAAlreadyEnabled
+		}
+		return model.MFAEnrollment{}, err
+	}
+
+	img, err := key.Image(256, 256)
+	if err != nil {
+		return model.MFAEnrollment{}, fmt.Errorf("render qr code: %w", err)
+	}
+	var qr bytes.Buffer
+	if err := png.Encode(&qr, img); err != nil {
+		return model.MFAEnrollment{}, fmt.Errorf("encode qr code: %w", err)
+	}
+
+	return model.MFAEnrollment{
+		Secret: key.Secret(),
+		URL:    key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qr.Bytes()),
+	}, nil
+}
+
+// ConfirmMFA enables two-factor authentication once the user entered a code of the
+// enrolled secret, and returns their recovery codes. They are not shown again.
+// Sessions started before keep working without a second factor until they end.
+func (s *userService) ConfirmMFA(ctx context.Context, userID int32, code string) ([]string, error) {
+	mfa, err := s.repo.GetMFA(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := matchTOTP(mfa.Secret, normalizeMFACode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.EnableMFA(ctx, userID, step, hashes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA turns two-factor authentication off after checking a code, and logs
+// the user out everywhere, since their sessions were started with it.
+func (s *userService) DisableMFA(ctx context.Context, userID int32, code string) error {
+	if err := s.checkMFACode(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMFA(ctx, userID); err != nil {
+		return err
+	}
+	return s.tokenCache.RevokeUser(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a code.
+func (s *userService) RegenerateRecoveryCodes(ctx context.Context, userID int32, code string) ([]string, error) {
+	if err := s.checkMFACode(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyMFA finishes a login that returned an MFA challenge, with a code from the
+// authenticator or a recovery code.
+func (s *userService) VerifyMFA(ctx context.Context, mfaToken, code string, device model.Device) (model.LoginResponse, error) {
+	userID, err := s.mfaChallenge.User(ctx, mfaToken)
+	if err != nil {
+		if errors.Is(err, redis.ErrMFAChallengeNotFound) {
+			return model.LoginResponse{}, ErrInvalidMFAChallenge
+		}
+		return model.LoginResponse{}, err
+	}
+
+	if err := s.checkMFACode(ctx, userID, code); err != nil {
+		return model.LoginResponse{}, err
+	}
+	if err := s.mfaChallenge.Complete(ctx, mfaToken); err != nil {
+		if errors.Is(err, redis.ErrMFAChallengeNotFound) {
+			return model.LoginResponse{}, ErrInvalidMFAChallenge
+		}
+		return model.LoginResponse{}, err
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.LoginResponse{}, ErrUserNotFound
+		}
+		return model.LoginResponse{}, err
+	}
+	return s.startSession(ctx, user, device, true)
+}
+
+// checkMFACode accepts a TOTP code or an unused recovery code of the user, each once.
+// Wrong codes count towards locking the user out of trying more.
+func (s *userService) checkMFACode(ctx context.Context, userID int32, code string) error {
+	if err := s.mfaChallenge.CheckFailures(ctx, userID); err != nil {
+		if errors.Is(err, redis.ErrTooManyMFAFailures) {
+			return ErrTooManyMFAFailures
+		}
+		return err
+	}
+
+	mfa, err := s.repo.GetMFA(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !mfa.Enabled) {
+		return ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+
+	code = normalizeMFACode(code)
+	if step, ok := matchTOTP(mfa.Secret, code, time.Now()); ok {
+		err = s.repo.UseTOTPStep(ctx, userID, step)
+	} else {
+		err = s.repo.UseRecoveryCode(ctx, userID, hashAccountToken(code))
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := s.mfaChallenge.Fail(ctx, userID); err != nil {
+			log.Printf("count mfa failure of user %d: %v", userID, err)
+		}
+		return ErrInvalidMFACode
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.mfaChallenge.ResetFailures(ctx, userID); err != nil {
+		log.Printf("reset mfa failures of user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// matchTOTP returns the time step whose code is code, within totpSkew steps of now.
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totpOpts)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeMFACode drops the spaces and dashes users type codes with.
+func normalizeMFACode(code string) string {
+	code = strings.NewReplacer(" ", "", "-", "").Replace(code)
+	return strings.ToLower(code)
+}
+
+// newRecoveryCodes returns recovery codes formatted like "abcde-fghij" and the hashes
+// that are stored.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashAccountToken(code))
+	}
+	return codes, hashes, nil
+}
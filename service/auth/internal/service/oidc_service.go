@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/service/auth/internal/infra/redis"
+	"ticket-tix/service/auth/internal/model"
+)
+
+var (
+	ErrUnknownProvider    = errors.New("unknown login provider")
+	ErrInvalidLoginState  = errors.New("login expired or already completed")
+	ErrEmailNotVerified   = errors.New("provider did not verify the email address")
+	ErrSocialLoginFailure = errors.New("social login failed")
+)
+
+// OIDCAuthURL starts a login at the provider and returns where to send the user, and
+// the state the callback has to present. The caller binds the state to the browser
+// that started the login, so a callback cannot be completed in another one.
+func (s *userService) OIDCAuthURL(ctx context.Context, provider string) (authURL, state string, err error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	login := redis.LoginState{
+		Provider: provider,
+		Nonce:    jwt.NewTokenID(),
+		Verifier: jwt.NewTokenID() + jwt.NewTokenID(),
+	}
+	state = jwt.NewTokenID()
+	if err := s.loginState.Save(ctx, state, login); err != nil {
+		return "", "", err
+	}
+	return p.AuthURL(state, login.Nonce, login.Verifier), state, nil
+}
+
+// OIDCLogin completes a login from the provider's callback. A provider account that
+// was seen before logs in its user. A new one is linked to the user with the same
+// email, or gets a new passwordless user, but only when the provider verified the
+// email.
+func (s *userService) OIDCLogin(ctx context.Context, provider, code, state string, device model.Device) (model.LoginResponse, error) {
+	login, err := s.loginState.Take(ctx, state)
+	if err != nil {
+		if errors.Is(err, redis.ErrLoginStateNotFound) {
+			return model.LoginResponse{}, ErrInvalidLoginState
+		}
+		return model.LoginResponse{}, err
+	}
+	p, ok := s.providers[provider]
+	if !ok || login.Provider != provider {
+		return model.LoginResponse{}, ErrInvalidLoginState
+	}
+
+	claims, err := p.Exchange(ctx, code, login.Verifier, login.Nonce)
+	if err != nil {
+		log.Printf("oidc login with %s: %v", provider, err)
+		return model.LoginResponse{}, ErrSocialLoginFailure
+	}
+	identity := model.Identity{
+		Provider:      provider,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+	}
+
+	user, err := s.identityUser(ctx, identity)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+	return s.beginSession(ctx, user, device)
+}
+
+// identityUser finds or creates the user a provider account belongs to.
+func (s *userService) identityUser(ctx context.Context, identity model.Identity) (model.User, error) {
+	userID, err := s.repo.GetIdentityUserID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return s.repo.GetUserByID(ctx, userID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, err
+	}
+
+	// linking by email is only safe when the provider vouches for it
+	if !identity.EmailVerified || identity.Email == "" {
+		return model.User{}, ErrEmailNotVerified
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, identity.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.repo.InsertUserWithIdentity(ctx, identity)
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if !user.EmailVerified {
+		// whoever registered this address never proved they own it, so their password
+		// and sessions go; the provider's user takes the account over
+		if user, err = s.repo.ClaimUnverifiedUser(ctx, user.ID); err != nil {
+			return model.User{}, err
+		}
+		if err := s.tokenCache.RevokeUser(ctx, user.ID); err != nil {
+			return model.User{}, fmt.Errorf("revoke sessions of claimed user: %w", err)
+		}
+	}
+	if err := s.repo.LinkIdentity(ctx, user.ID, identity); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/service/auth/internal/infra/oidc"
+	"ticket-tix/service/auth/internal/infra/oidc/oidctest"
+	"ticket-tix/service/auth/internal/infra/redis"
+	"ticket-tix/service/auth/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const testProvider = "test"
+
+func TestOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	srv, svc, repo := newOIDCTestService(t)
+	repo.addUser(model.User{ID: 1, Email: "alice@example.com", PasswordHash: "hash", Role: jwt.RoleCustomer, EmailVerified: true})
+	ctx := context.Background()
+
+	code, state := startOIDCLogin(t, srv, svc, "google-alice", "alice@example.com", true)
+	resp, err := svc.OIDCLogin(ctx, testProvider, code, state, model.Device{})
+	if err != nil {
+		t.Fatalf("OIDCLogin() = %v", err)
+	}
+	if resp.User.ID != 1 || resp.AccessToken == "" || resp.RefreshToken == "" {
+		t.Fatalf("OIDCLogin() = %+v, want a session of user 1", resp)
+	}
+	if got := repo.identityOwner("google-alice"); got != 1 {
+		t.Fatalf("identity linked to user %d, want 1", got)
+	}
+	if repo.claimed || repo.inserted {
+		t.Fatalf("verified user was claimed (%v) or a new user inserted (%v)", repo.claimed, repo.inserted)
+	}
+
+	// the state is used up
+	if _, err := svc.OIDCLogin(ctx, testProvider, code, state, model.Device{}); !errors.Is(err, ErrInvalidLoginState) {
+		t.Fatalf("replayed OIDCLogin() = %v, want ErrInvalidLoginState", err)
+	}
+
+	// the linked account logs in by subject even after its email changed
+	code, state = startOIDCLogin(t, srv, svc, "google-alice", "alice@new.example.com", true)
+	resp, err = svc.OIDCLogin(ctx, testProvider, code, state, model.Device{})
+	if err != nil || resp.User.ID != 1 {
+		t.Fatalf("OIDCLogin() of linked identity = %+v, %v, want user 1", resp.User, err)
+	}
+}
+
+func TestOIDCLoginRefusesUnverifiedEmail(t *testing.T) {
+	srv, svc, repo := newOIDCTestService(t)
+	repo.addUser(model.User{ID: 1, Email: "alice@example.com", PasswordHash: "hash", Role: jwt.RoleCustomer, EmailVerified: true})
+
+	code, state := startOIDCLogin(t, srv, svc, "mallory", "alice@example.com", false)
+	if _, err := svc.OIDCLogin(context.Background(), testProvider, code, state, model.Device{}); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("OIDCLogin() = %v, want ErrEmailNotVerified", err)
+	}
+	if got := repo.identityOwner("mallory"); got != 0 {
+		t.Fatalf("unverified identity linked to user %d", got)
+	}
+}
+
+func TestOIDCLoginRejectsOtherProviderState(t *testing.T) {
+	srv, svc, _ := newOIDCTestService(t)
+
+	code, state := startOIDCLogin(t, srv, svc, "alice", "alice@example.com", true)
+	if _, err := svc.OIDCLogin(context.Background(), "other", code, state, model.Device{}); !errors.Is(err, ErrInvalidLoginState) {
+		t.Fatalf("OIDCLogin() at another provider = %v, want ErrInvalidLoginState", err)
+	}
+}
+
+func newOIDCTestService(t *testing.T) (*oidctest.Server, model.UserService, *fakeUserRepo) {
+	t.Helper()
+	srv := oidctest.NewServer(t)
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Name:        testProvider,
+		Issuer:      srv.URL,
+		ClientID:    oidctest.ClientID,
+		RedirectURL: oidctest.RedirectURL,
+	})
+	if err != nil {
+		t.Fatalf("Discover() = %v", err)
+	}
+	keys, err := jwt.LoadKeyRing(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadKeyRing() = %v", err)
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	repo := newFakeUserRepo()
+	svc := NewUserService(
+		repo,
+		keys,
+		redis.NewRefreshToken(client),
+		nil,
+		"http://localhost:5173",
+		[]*oidc.Provider{provider},
+		redis.NewOIDCState(client),
+		redis.NewMFAChallenge(client),
+	)
+	return srv, svc, repo
+}
+
+// startOIDCLogin starts a login and signs in at the provider as subject, returning
+// the code and state the frontend would post to the callback.
+func startOIDCLogin(t *testing.T, srv *oidctest.Server, svc model.UserService, subject, email string, verified bool) (code, state string) {
+	t.Helper()
+	authURL, state, err := svc.OIDCAuthURL(context.Background(), testProvider)
+	if err != nil {
+		t.Fatalf("OIDCAuthURL() = %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil || u.Query().Get("state") != state {
+		t.Fatalf("auth url %s does not carry state %q", authURL, state)
+	}
+	return srv.Authorize(authURL, subject, email, verified), state
+}
+
+// fakeUserRepo keeps users and identities in memory. Methods the OIDC login does not
+// use panic through the nil embedded interface.
+type fakeUserRepo struct {
+	model.UserRepo
+
+	mu         sync.Mutex
+	users      map[int32]model.User
+	identities map[string]int32
+	inserted   bool
+	claimed    bool
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: make(map[int32]model.User), identities: make(map[string]int32)}
+}
+
+func (r *fakeUserRepo) addUser(u model.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[u.ID] = u
+}
+
+func (r *fakeUserRepo) identityOwner(subject string) int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.identities[testProvider+":"+subject]
+}
+
+func (r *fakeUserRepo) GetUserByID(_ context.Context, id int32) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return model.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r *fakeUserRepo) GetUserByEmail(_ context.Context, email string) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return model.User{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) GetIdentityUserID(_ context.Context, provider, subject string) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.identities[provider+":"+subject]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+func (r *fakeUserRepo) LinkIdentity(_ context.Context, userID int32, identity model.Identity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.identities[identity.Provider+":"+identity.Subject] = userID
+	return nil
+}
+
+func (r *fakeUserRepo) InsertUserWithIdentity(_ context.Context, identity model.Identity) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inserted = true
+	u := model.User{ID: int32(len(r.users) + 1), Email: identity.Email, Role: jwt.RoleCustomer, EmailVerified: true}
+	r.users[u.ID] = u
+	r.identities[identity.Provider+":"+identity.Subject] = u.ID
+	return u, nil
+}
+
+func (r *fakeUserRepo) ClaimUnverifiedUser(_ context.Context, id int32) (model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.claimed = true
+	u := r.users[id]
+	u.PasswordHash, u.EmailVerified = "", true
+	r.users[id] = u
+	return u, nil
+}
+
+func (r *fakeUserRepo) GetMFA(context.Context, int32) (model.MFA, error) {
+	return model.MFA{}, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) GetUserOrganizationID(context.Context, int32) (int32, error) {
+	return 0, nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"log"
 	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/common/pkg/mail"
+	"ticket-tix/service/auth/internal/infra/oidc"
 	"ticket-tix/service/auth/internal/infra/redis"
 	"ticket-tix/service/auth/internal/model"
 
@@ -17,19 +20,45 @@ var (
 	ErrLoginFailed       = errors.New("login failed")
 	ErrInvalidCredential = errors.New("invalid email or password")
 	ErrInvalidToken      = errors.New("invalid or revoked refresh token")
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrSessionNotFound   = errors.New("session not found")
 )
 
 type userService struct {
 	repo       model.UserRepo
 	tokenCache redis.RefreshToken
-	secretKey  string
+	keys       *jwt.KeyRing
+	mailer     mail.Mailer
+	// appURL is the frontend the links in emails point to.
+	appURL       string
+	providers    map[string]*oidc.Provider
+	loginState   redis.OIDCState
+	mfaChallenge redis.MFAChallenge
 }
 
-func NewUserService(repo model.UserRepo, secretKey string, tokenCache redis.RefreshToken) model.UserService {
+func NewUserService(
+	repo model.UserRepo,
+	keys *jwt.KeyRing,
+	tokenCache redis.RefreshToken,
+	mailer mail.Mailer,
+	appURL string,
+	providers []*oidc.Provider,
+	loginState redis.OIDCState,
+	mfaChallenge redis.MFAChallenge,
+) model.UserService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
 	return &userService{
-		repo:       repo,
-		secretKey:  secretKey,
-		tokenCache: tokenCache,
+		repo:         repo,
+		keys:         keys,
+		tokenCache:   tokenCache,
+		mailer:       mailer,
+		appURL:       appURL,
+		providers:    byName,
+		loginState:   loginState,
+		mfaChallenge: mfaChallenge,
 	}
 }
 
@@ -53,10 +82,15 @@ func (s *userService) RegisterUser(ctx context.Context, email, password string)
 	if err != nil {
 		return model.User{}, err
 	}
-	return model.User{ID: user.ID, Email: user.Email}, err
+
+	// the account exists either way; the user can ask for another email
+	if err := s.sendVerification(ctx, user); err != nil {
+		log.Printf("send verification email to user %d: %v", user.ID, err)
+	}
+	return model.User{ID: user.ID, Email: user.Email, Role: user.Role}, err
 }
 
-func (s *userService) Login(ctx context.Context, email, password string) (model.LoginResponse, error) {
+func (s *userService) Login(ctx context.Context, email, password string, device model.Device) (model.LoginResponse, error) {
 	userDetail, err := s.repo.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -65,7 +99,8 @@ func (s *userService) Login(ctx context.Context, email, password string) (model.
 		return model.LoginResponse{}, err
 	}
 
-	if userDetail.ID == 0 {
+	// users who signed up through a provider have no password to log in with
+	if userDetail.ID == 0 || userDetail.PasswordHash == "" {
 		return model.LoginResponse{}, ErrInvalidCredential
 	}
 
@@ -73,54 +108,205 @@ func (s *userService) Login(ctx context.Context, email, password string) (model.
 		return model.LoginResponse{}, ErrInvalidCredential
 	}
 
+	return s.beginSession(ctx, userDetail, device)
+}
+
+// beginSession starts the session of a user who passed the first factor. Users with
+// two-factor authentication get an MFA challenge instead, to finish with VerifyMFA.
+func (s *userService) beginSession(ctx context.Context, userDetail model.User, device model.Device) (model.LoginResponse, error) {
+	mfa, err := s.repo.GetMFA(ctx, userDetail.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return model.LoginResponse{}, err
+	}
+	if !mfa.Enabled {
+		return s.startSession(ctx, userDetail, device, false)
+	}
+
+	mfaToken := jwt.NewTokenID()
+	if err := s.mfaChallenge.Create(ctx, mfaToken, userDetail.ID); err != nil {
+		return model.LoginResponse{}, err
+	}
+	return model.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+}
+
+// startSession issues the tokens of a new session for an authenticated user. mfa
+// tells whether they passed a second factor.
+func (s *userService) startSession(ctx context.Context, userDetail model.User, device model.Device, mfa bool) (model.LoginResponse, error) {
 	// Generate Tokens
-	refreshToken, err := jwt.GenerateRefreshToken(userDetail.ID, s.secretKey)
+	familyID, tokenID := jwt.NewTokenID(), jwt.NewTokenID()
+	refreshToken, err := jwt.GenerateRefreshToken(userDetail.ID, mfa, familyID, tokenID, s.keys)
 	if err != nil {
 		return model.LoginResponse{}, err
 	}
 
-	accessToken, err := jwt.GenerateAccessToken(userDetail.ID, s.secretKey)
+	orgID, err := s.repo.GetUserOrganizationID(ctx, userDetail.ID)
 	if err != nil {
 		return model.LoginResponse{}, err
 	}
 
-	err = s.tokenCache.SaveRefreshToken(ctx, userDetail.ID, refreshToken)
+	accessToken, err := jwt.GenerateAccessToken(jwt.Subject{
+		UserID:        userDetail.ID,
+		Role:          userDetail.Role,
+		OrgID:         orgID,
+		EmailVerified: userDetail.EmailVerified,
+		MFA:           mfa,
+	}, s.keys)
+	if err != nil {
+		return model.LoginResponse{}, err
+	}
+
+	err = s.tokenCache.StartFamily(ctx, userDetail.ID, familyID, tokenID, redis.Device(device))
 	if err != nil {
 		return model.LoginResponse{}, err
 	}
 
 	return model.LoginResponse{
 		User: model.User{
-			ID:    userDetail.ID,
-			Email: userDetail.Email,
+			ID:            userDetail.ID,
+			Email:         userDetail.Email,
+			Role:          userDetail.Role,
+			OrgID:         orgID,
+			EmailVerified: userDetail.EmailVerified,
 		},
 		RefreshToken: refreshToken,
 		AccessToken:  accessToken,
 	}, nil
 }
 
-func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	claims, err := jwt.ParseToken(refreshToken, s.secretKey)
+// RefreshToken rotates refreshToken: it returns a new refresh token of the same family
+// and the old one stops working. Presenting a token that was already rotated revokes
+// the family, since only a copy of it can still be in use.
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string, device model.Device) (model.TokenPair, error) {
+	claims, err := jwt.ParseToken(refreshToken, s.keys)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	if claims.Type != jwt.RefreshType || claims.FamilyID == "" {
+		return model.TokenPair{}, ErrInvalidToken
+	}
+
+	// role, organization and verification are read again so changes apply from the
+	// next refresh
+	user, err := s.repo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.TokenPair{}, ErrUserNotFound
+		}
+		return model.TokenPair{}, err
+	}
+
+	orgID, err := s.repo.GetUserOrganizationID(ctx, user.ID)
 	if err != nil {
-		return "", err
+		return model.TokenPair{}, err
 	}
 
-	if claims.Type != jwt.RefreshType {
-		return "", ErrInvalidToken
+	tokenID := jwt.NewTokenID()
+	newRefreshToken, err := jwt.GenerateRefreshToken(user.ID, claims.MFA, claims.FamilyID, tokenID, s.keys)
+	if err != nil {
+		return model.TokenPair{}, err
 	}
 
-	isValid, err := s.tokenCache.ValidateRefreshToken(ctx, claims.UserID, refreshToken)
-	if err != nil || !isValid {
-		return "", ErrInvalidToken
+	accessToken, err := jwt.GenerateAccessToken(jwt.Subject{
+		UserID:        user.ID,
+		Role:          user.Role,
+		OrgID:         orgID,
+		EmailVerified: user.EmailVerified,
+		MFA:           claims.MFA,
+	}, s.keys)
+	if err != nil {
+		return model.TokenPair{}, err
 	}
 
-	return jwt.GenerateAccessToken(claims.UserID, s.secretKey)
+	err = s.tokenCache.RotateRefreshToken(ctx, user.ID, claims.FamilyID, claims.ID, tokenID, device.IP)
+	if err != nil {
+		if errors.Is(err, redis.ErrRefreshTokenReused) {
+			log.Printf("refresh token reuse for user %d, revoked family %s", user.ID, claims.FamilyID)
+		}
+		if errors.Is(err, redis.ErrRefreshTokenReused) || errors.Is(err, redis.ErrRefreshTokenRevoked) {
+			return model.TokenPair{}, ErrInvalidToken
+		}
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{RefreshToken: newRefreshToken, AccessToken: accessToken}, nil
 }
 
-func (s *userService) LogOut(ctx context.Context, userId int32, token string, allDevices bool) error {
+func (s *userService) LogOut(ctx context.Context, userId int32, access model.AccessToken, refreshToken string, allDevices bool) error {
 	if allDevices {
 		return s.tokenCache.RevokeUser(ctx, userId)
 	}
-	// single device — revoke only this token
-	return s.tokenCache.RevokeRefreshToken(ctx, userId, token)
+
+	// single device — revoke only this token's family and the access token in use
+	claims, err := jwt.ParseToken(refreshToken, s.keys)
+	if err != nil || claims.Type != jwt.RefreshType || claims.UserID != userId {
+		return ErrInvalidToken
+	}
+	if err := s.tokenCache.RevokeFamily(ctx, userId, claims.FamilyID); err != nil {
+		return err
+	}
+	return s.tokenCache.BlackListAccessToken(ctx, access.ID, access.ExpiresAt)
+}
+
+func (s *userService) ListSessions(ctx context.Context, userID int32) ([]model.Session, error) {
+	sessions, err := s.tokenCache.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Session, len(sessions))
+	for i, session := range sessions {
+		result[i] = model.Session{
+			ID:         session.ID,
+			UserAgent:  session.Device.UserAgent,
+			IP:         session.Device.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+		}
+	}
+	return result, nil
+}
+
+// RevokeSession logs the session out. Its access tokens stay valid until they expire.
+func (s *userService) RevokeSession(ctx context.Context, userID int32, sessionID string) error {
+	if err := s.tokenCache.RevokeSession(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, redis.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *userService) SetUserRole(ctx context.Context, userID int32, role string) (model.User, error) {
+	switch role {
+	case jwt.RoleCustomer, jwt.RoleOrganizer, jwt.RoleAdmin, jwt.RoleGateStaff:
+	default:
+		return model.User{}, ErrInvalidRole
+	}
+
+	user, err := s.repo.UpdateUserRole(ctx, userID, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+	return model.User{ID: user.ID, Email: user.Email, Role: user.Role}, nil
+}
+
+func (s *userService) CreateOrganization(ctx context.Context, name, slug string) (model.Organization, error) {
+	return s.repo.InsertOrganization(ctx, name, slug)
+}
+
+// AddOrganizationMember moves the user into the organization. It takes effect in the
+// user's next access token.
+func (s *userService) AddOrganizationMember(ctx context.Context, orgID, userID int32) error {
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.repo.SetOrganizationMember(ctx, orgID, userID)
 }
@@ -6,4 +6,134 @@ RETURNING *;
 -- name: GetUserByEmail :one
 SELECT *
 FROM users
-WHERE email = $1;
\ No newline at end of file
+WHERE email = $1;
+
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: InsertOrganization :one
+INSERT INTO organizations (name, slug)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: UpsertOrganizationMember :one
+INSERT INTO organization_members (user_id, organization_id)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET organization_id = EXCLUDED.organization_id
+RETURNING *;
+
+-- name: GetUserOrganizationID :one
+SELECT organization_id
+FROM organization_members
+WHERE user_id = $1;
+
+-- name: InsertEmailVerificationToken :exec
+INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+VALUES ($1, $2, NOW() + @ttl_seconds::int * INTERVAL '1 second');
+
+-- name: VerifyEmailWithToken :one
+WITH used AS (
+    UPDATE email_verification_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET email_verified_at = COALESCE(users.email_verified_at, NOW())
+FROM used
+WHERE users.id = used.user_id
+RETURNING users.id;
+
+-- name: InsertPasswordResetToken :exec
+INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+VALUES ($1, $2, NOW() + @ttl_seconds::int * INTERVAL '1 second');
+
+-- name: InvalidatePasswordResetTokens :exec
+UPDATE password_reset_tokens
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: ResetPasswordWithToken :one
+WITH used AS (
+    UPDATE password_reset_tokens
+    SET used_at = NOW()
+    WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET password_hash = $2
+FROM used
+WHERE users.id = used.user_id
+RETURNING users.id;
+
+-- name: GetUserIdentity :one
+SELECT *
+FROM user_identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: InsertUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: InsertPasswordlessUser :one
+INSERT INTO users (email, email_verified_at)
+VALUES ($1, NOW())
+RETURNING *;
+
+-- name: ClaimUnverifiedUser :one
+UPDATE users
+SET email_verified_at = NOW(), password_hash = NULL
+WHERE id = $1 AND email_verified_at IS NULL
+RETURNING *;
+
+-- name: UpsertUserMFASecret :one
+INSERT INTO user_mfa (user_id, secret)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE
+SET secret = EXCLUDED.secret, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+WHERE user_mfa.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetUserMFA :one
+SELECT *
+FROM user_mfa
+WHERE user_id = $1;
+
+-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET enabled_at = NOW(), last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL
+RETURNING user_id;
+
+-- name: UseTOTPStep :one
+UPDATE user_mfa
+SET last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NOT NULL AND last_used_step < $2
+RETURNING user_id;
+
+-- name: DeleteUserMFA :exec
+DELETE FROM user_mfa
+WHERE user_id = $1;
+
+-- name: InsertRecoveryCode :exec
+INSERT INTO mfa_recovery_codes (user_id, code_hash)
+VALUES ($1, $2);
+
+-- name: UseRecoveryCode :one
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING id;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE user_id = $1;
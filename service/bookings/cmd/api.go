@@ -10,7 +10,10 @@ import (
 	"syscall"
 	"ticket-tix/common/pkg/db"
 	"ticket-tix/common/pkg/events"
+	"ticket-tix/common/pkg/jwt"
 	"ticket-tix/common/pkg/lock"
+	"ticket-tix/common/pkg/middleware"
+	"ticket-tix/common/pkg/tracing"
 	"ticket-tix/service/bookings/internal/handler"
 	"ticket-tix/service/bookings/internal/repository"
 	"ticket-tix/service/bookings/internal/service"
@@ -38,11 +41,27 @@ const (
 	// rpc
 	ticketRPCAddr = "localhost:40061"
 
+	// auth
+	authJWKSURL = "http://localhost:50061/.well-known/jwks.json"
+	jwksTTL     = 5 * time.Minute
+
 	// kafka
 	kafkaAddr = "localhost:9092"
 )
 
 func main() {
+	shutdownTracing, err := tracing.Init("booking-service")
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Tracer provider shutdown failed: %v", err)
+		}
+	}()
+
 	bookDB := openDBConnection()
 
 	ticketConn, err := grpc.NewClient(ticketRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -64,9 +83,13 @@ func main() {
 	}
 
 	ticketService := service.NewBookingService(repo, ticketClient, redLock, producer)
-	httpHandler := handler.NewHandler(ticketService, redisClient)
+	httpHandler := handler.NewHandler(ticketService, jwt.NewJWKSCache(authJWKSURL, jwksTTL), redisClient)
 
 	r := gin.Default()
+	if err := middleware.SetTrustedProxies(r); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	r.Use(middleware.TracingMiddleware("booking-service"))
 	httpHandler.RegisterRoutes(r)
 
 	srv := &http.Server{
@@ -1,25 +1,25 @@
 package handler
 
 import (
+	"ticket-tix/common/pkg/jwt"
 	"ticket-tix/common/pkg/middleware"
 	"ticket-tix/service/bookings/internal/model"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
-const (
-	secretKey = "sudo-secret-key"
-)
-
 type Handler struct {
 	service     model.BookingService
+	keys        jwt.KeySource
 	redisClient *redis.Client
 }
 
-func NewHandler(service model.BookingService, rdsClient *redis.Client) *Handler {
+func NewHandler(service model.BookingService, keys jwt.KeySource, rdsClient *redis.Client) *Handler {
 	return &Handler{
 		service:     service,
+		keys:        keys,
 		redisClient: rdsClient,
 	}
 }
@@ -32,9 +32,17 @@ func (h *Handler) RegisterRoutes(r gin.IRouter) {
 	auth := r.Group("/bookings")
 	auth.Use(
 		middleware.TimeoutMiddleware(5),
-		middleware.AuthMiddleware(secretKey, h.redisClient),
+		middleware.AuthMiddleware(h.keys, h.redisClient),
+		middleware.RequireRole(jwt.RoleCustomer, jwt.RoleAdmin),
+		middleware.RequireVerifiedEmail(),
+	)
+	// per-user quotas slow down bots buying up inventory
+	limiter := middleware.NewRateLimiter(h.redisClient)
+	auth.POST("/create",
+		limiter.Limit("booking-user-minute", 5, time.Minute, middleware.KeyByUser),
+		limiter.Limit("booking-user-hour", 30, time.Hour, middleware.KeyByUser),
+		h.CreateBooking,
 	)
-	auth.POST("/create", h.CreateBooking)
 }
 
 func (h *Handler) CreateBooking(c *gin.Context) {
@@ -54,6 +62,7 @@ func (h *Handler) CreateBooking(c *gin.Context) {
 		req.SeatID,
 		req.BookType,
 		req.CategoryType,
+		req.Accessible,
 	); err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
@@ -6,9 +6,12 @@ package bookingDB
 
 import (
 	"context"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	CancelBooking(ctx context.Context, id uuid.UUID) error
 	CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error)
 }
 
@@ -8,8 +8,21 @@ package bookingDB
 import (
 	"context"
 	"database/sql"
+
+	"github.com/google/uuid"
 )
 
+const cancelBooking = `-- name: CancelBooking :exec
+UPDATE bookings
+SET status = 'CANCELLED'
+WHERE id = $1
+`
+
+func (q *Queries) CancelBooking(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, cancelBooking, id)
+	return err
+}
+
 const createBooking = `-- name: CreateBooking :one
 INSERT INTO bookings (ticket_id, status, user_id, event_category_id, event_id, seat_number)
 VALUES ($1, $2, $3, $4, $5, $6)
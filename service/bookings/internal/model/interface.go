@@ -4,10 +4,12 @@ import "context"
 
 type BookingRepo interface {
 	CreateBooking(ctx context.Context, bookingDetail CreateBooking) (CreateBooking, error)
+	CreateBookings(ctx context.Context, bookings []CreateBooking) ([]CreateBooking, error)
+	CancelBooking(ctx context.Context, bookingID string) error
 }
 
 type BookingService interface {
-	CreateBooking(ctx context.Context, userID int32, eventID, eventCat int32, seatID, bookType, categoryType string) error
+	CreateBooking(ctx context.Context, userID int32, eventID, eventCat int32, seatID, bookType, categoryType string, accessible bool) error
 }
 
 type CreateBooking struct {
@@ -26,4 +28,5 @@ type BookingRequest struct {
 	SeatID       string `json:"seat_id"`                          // empty for FLEXIBLE and STANDING
 	BookType     string `json:"book_type"     binding:"required"` // "FIXED" or "FLEXIBLE"
 	CategoryType string `json:"category_type" binding:"required"` // "SEATED" or "STANDING"
+	Accessible   bool   `json:"accessible"`                       // wheelchair seat + companion, SEATED only
 }
@@ -3,21 +3,66 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	bookingDB "ticket-tix/service/bookings/internal/infra/postgres"
 	"ticket-tix/service/bookings/internal/model"
+
+	"github.com/google/uuid"
 )
 
 type bookingRepo struct {
-	db *bookingDB.Queries
+	db    *bookingDB.Queries
+	rawDB *sql.DB
 }
 
 func NewBookingRepo(db *sql.DB) model.BookingRepo {
 	return &bookingRepo{
-		db: bookingDB.New(db),
+		db:    bookingDB.New(db),
+		rawDB: db,
 	}
 }
 
 func (r *bookingRepo) CreateBooking(ctx context.Context, bookingDetail model.CreateBooking) (model.CreateBooking, error) {
+	return createBooking(ctx, r.db, bookingDetail)
+}
+
+// CreateBookings inserts the bookings in one transaction, so either all of them exist
+// or none does.
+func (r *bookingRepo) CreateBookings(ctx context.Context, bookings []model.CreateBooking) ([]model.CreateBooking, error) {
+	tx, err := r.rawDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	q := r.db.WithTx(tx)
+
+	created := make([]model.CreateBooking, 0, len(bookings))
+	for _, booking := range bookings {
+		createdBooking, err := createBooking(ctx, q, booking)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, createdBooking)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return created, nil
+}
+
+func (r *bookingRepo) CancelBooking(ctx context.Context, bookingID string) error {
+	id, err := uuid.Parse(bookingID)
+	if err != nil {
+		return fmt.Errorf("parse booking id: %w", err)
+	}
+	if err := r.db.CancelBooking(ctx, id); err != nil {
+		return fmt.Errorf("cancel booking: %w", err)
+	}
+	return nil
+}
+
+func createBooking(ctx context.Context, q *bookingDB.Queries, bookingDetail model.CreateBooking) (model.CreateBooking, error) {
 	var ticketIDNullInt sql.NullInt32
 	var seatNumberNullString sql.NullString
 
@@ -36,7 +81,7 @@ func (r *bookingRepo) CreateBooking(ctx context.Context, bookingDetail model.Cre
 	// FIX: EventID was missing from CreateBookingParams despite being NOT NULL in the DB schema.
 	// This would cause a runtime DB error on every standing-ticket booking and any
 	// seated booking where EventID wasn't threaded through correctly.
-	createdBooking, err := r.db.CreateBooking(ctx, bookingDB.CreateBookingParams{
+	createdBooking, err := q.CreateBooking(ctx, bookingDB.CreateBookingParams{
 		TicketID:        ticketIDNullInt,
 		UserID:          bookingDetail.UserID,
 		EventCategoryID: bookingDetail.EventType,
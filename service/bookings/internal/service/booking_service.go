@@ -3,7 +3,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	ticketRPC "ticket-tix/common/gen/ticket/v1"
@@ -11,10 +10,19 @@ import (
 	"ticket-tix/common/pkg/lock"
 	"ticket-tix/service/bookings/internal/model"
 	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	bookingCreatedTopic = "booking.created"
+	producerName = "booking-service"
+
+	// a standing hold is confirmed this many times, waiting attempt * confirmStockBackoff
+	// in between, before the booking is given up
+	confirmStockAttempts = 3
+	confirmStockBackoff  = 200 * time.Millisecond
 )
 
 type bookingService struct {
@@ -28,7 +36,14 @@ func NewBookingService(repo model.BookingRepo, ticketSvc ticketRPC.TicketService
 	return &bookingService{repo: repo, ticketSVC: ticketSvc, lock: lock, producer: producer}
 }
 
-func (s *bookingService) CreateBooking(ctx context.Context, userID int32, eventID, eventCat int32, seatID, bookType, categoryType string) error {
+func (s *bookingService) CreateBooking(ctx context.Context, userID int32, eventID, eventCat int32, seatID, bookType, categoryType string, accessible bool) error {
+	if accessible {
+		if categoryType != "SEATED" {
+			return fmt.Errorf("accessible seating is only available for SEATED categories")
+		}
+		return s.bookAccessiblePair(ctx, userID, eventID, eventCat)
+	}
+
 	switch categoryType {
 	case "STANDING":
 		return s.bookStanding(ctx, userID, eventID, eventCat)
@@ -116,11 +131,68 @@ func (s *bookingService) bookSeatedFlexible(ctx context.Context, userID, eventID
 	return nil
 }
 
-// STANDING: no seat, just check there's capacity
+// SEATED + accessible: the ticket service hands out a wheelchair seat together with its
+// companion seat, so the user ends up with two bookings. They are created together, so
+// the user never holds only one of the pair; if they cannot be, both seats are released.
+func (s *bookingService) bookAccessiblePair(ctx context.Context, userID, eventID, eventCat int32) error {
+	resp, err := s.ticketSVC.ReserveAccessibleSeat(ctx, &ticketRPC.ReserveAccessibleSeatRequest{
+		EventCategoryId: eventCat,
+		EventId:         eventID,
+	})
+	if err != nil {
+		return fmt.Errorf("reserve accessible seat: %w", err)
+	}
+
+	seats := []struct {
+		ticketID   int32
+		seatNumber string
+	}{
+		{resp.GetTicketId(), resp.GetSeatNumber()},
+		{resp.GetCompanionTicketId(), resp.GetCompanionSeatNumber()},
+	}
+
+	bookings := make([]model.CreateBooking, len(seats))
+	for i, seat := range seats {
+		bookings[i] = model.CreateBooking{
+			EventID:    eventID,
+			EventType:  eventCat,
+			TicketID:   seat.ticketID,
+			UserID:     userID,
+			SeatNumber: seat.seatNumber,
+			Status:     "CONFIRMED",
+		}
+	}
+
+	created, err := s.repo.CreateBookings(ctx, bookings)
+	if err != nil {
+		// the pair is already sold; without its bookings nobody would ever hold it
+		_, releaseErr := s.ticketSVC.ReleaseAccessibleSeat(ctx, &ticketRPC.ReleaseAccessibleSeatRequest{
+			EventCategoryId:   eventCat,
+			TicketId:          resp.GetTicketId(),
+			CompanionTicketId: resp.GetCompanionTicketId(),
+		})
+		if releaseErr != nil {
+			log.Printf("failed to release accessible seats %s and %s: %v",
+				resp.GetSeatNumber(), resp.GetCompanionSeatNumber(), releaseErr)
+		}
+		return fmt.Errorf("create accessible bookings for seats %s and %s: %w",
+			resp.GetSeatNumber(), resp.GetCompanionSeatNumber(), err)
+	}
+	for _, bookingData := range created {
+		s.publishBookingCreated(ctx, bookingData, "SEATED_ACCESSIBLE")
+	}
+	return nil
+}
+
+// STANDING: no seat, just check there's capacity. The stock is held under a
+// reservation ID and only confirmed once the booking row exists; if anything fails in
+// between the hold is released and the booking cancelled.
 func (s *bookingService) bookStanding(ctx context.Context, userID, eventID, eventCat int32) error {
+	reservationID := uuid.New().String()
 	_, err := s.ticketSVC.DecreaseTicket(ctx, &ticketRPC.DecreaseTicketRequest{
 		EventCategoryId: eventCat,
 		DecreaseBy:      1,
+		ReservationId:   reservationID,
 	})
 
 	if err != nil {
@@ -138,15 +210,62 @@ func (s *bookingService) bookStanding(ctx context.Context, userID, eventID, even
 		_, incrErr := s.ticketSVC.IncreaseTicket(ctx, &ticketRPC.IncreaseTicketRequest{
 			EventCategoryId: eventCat,
 			IncreaseBy:      1,
+			ReservationId:   reservationID,
 		})
 		log.Println("incrErr", incrErr)
 		return fmt.Errorf("create standing booking: %w", err)
 	}
 
+	if confirmErr := s.confirmStandingStock(ctx, eventCat, reservationID); confirmErr != nil {
+		// an unconfirmed hold expires and its unit goes back on sale, so the booking
+		// cannot stand: give the hold back now and cancel the booking
+		_, incrErr := s.ticketSVC.IncreaseTicket(ctx, &ticketRPC.IncreaseTicketRequest{
+			EventCategoryId: eventCat,
+			IncreaseBy:      1,
+			ReservationId:   reservationID,
+		})
+		if incrErr != nil {
+			log.Printf("failed to release stock reservation %s: %v", reservationID, incrErr)
+		}
+		if cancelErr := s.repo.CancelBooking(ctx, bookingData.ID); cancelErr != nil {
+			log.Printf("failed to cancel booking %s after unconfirmed stock: %v", bookingData.ID, cancelErr)
+		}
+		return fmt.Errorf("confirm standing stock: %w", confirmErr)
+	}
+
 	s.publishBookingCreated(ctx, bookingData, "STANDING")
 	return nil
 }
 
+// confirmStandingStock turns the hold into a sale, retrying transient failures. A hold
+// that is gone, e.g. because it expired, is not retried.
+func (s *bookingService) confirmStandingStock(ctx context.Context, eventCat int32, reservationID string) error {
+	var err error
+	for attempt := 1; attempt <= confirmStockAttempts; attempt++ {
+		_, err = s.ticketSVC.ConfirmTicketStock(ctx, &ticketRPC.ConfirmTicketStockRequest{
+			EventCategoryId: eventCat,
+			ReservationId:   reservationID,
+		})
+		if err == nil {
+			return nil
+		}
+		switch status.Code(err) {
+		case codes.FailedPrecondition, codes.InvalidArgument:
+			return err
+		}
+		log.Printf("confirm stock reservation %s, attempt %d: %v", reservationID, attempt, err)
+
+		if attempt < confirmStockAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * confirmStockBackoff):
+			}
+		}
+	}
+	return err
+}
+
 func (s *bookingService) seatLockKey(eventCatID int32, seatID string) string {
 	return fmt.Sprintf("lock:seat:%d:%s", eventCatID, seatID)
 }
@@ -162,24 +281,7 @@ func (s *bookingService) publishBookingCreated(ctx context.Context, booking mode
 		OccurredAt:   time.Now(),
 	}
 
-	payload, err := json.Marshal(event)
-	if err != nil {
-		log.Printf("failed to marshal booking event: %v", err)
-		return
-	}
-
-	msg := events.Message{
-		Key:   []byte(booking.ID),
-		Value: payload,
-		Headers: map[string]string{
-			"event-type":     "booking.created",
-			"content-type":   "application/json",
-			"source":         "booking-service",
-			"correlation-id": booking.ID,
-		},
-	}
-
-	if err := s.producer.Publish(ctx, bookingCreatedTopic, msg); err != nil {
+	if err := events.Publish(ctx, s.producer, producerName, booking.ID, event); err != nil {
 		log.Printf("failed to publish booking.created event: %v", err)
 	}
 }
@@ -1,4 +1,9 @@
 -- name: CreateBooking :one
 INSERT INTO bookings (ticket_id, status, user_id, event_category_id, event_id, seat_number)
 VALUES ($1, $2, $3, $4, $5, $6)
-    RETURNING *;
\ No newline at end of file
+    RETURNING *;
+
+-- name: CancelBooking :exec
+UPDATE bookings
+SET status = 'CANCELLED'
+WHERE id = $1;
@@ -2,23 +2,43 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"ticket-tix/common/pkg/events"
+	"ticket-tix/common/pkg/tracing"
 	"ticket-tix/service/fullfilement/internal/handler"
 	"ticket-tix/service/fullfilement/internal/repo"
 	"ticket-tix/service/fullfilement/internal/servcie"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const metricsAddr = ":50064"
+
 func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
+	shutdownTracing, err := tracing.Init("fulfillment-service")
+	if err != nil {
+		logger.Error("failed to set up tracing", "err", err)
+		os.Exit(1)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to shut down tracer provider", "err", err)
+		}
+	}()
+
 	startupCtx, startupCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer startupCancel()
 
@@ -49,19 +69,36 @@ func main() {
 	db := client.Database("ticket-tix")
 	collection := db.Collection("fulfillments")
 
+	if err := repo.EnsureIndexes(startupCtx, collection, logger); err != nil {
+		logger.Error("failed to create fulfillment indexes", "err", err)
+		os.Exit(1)
+	}
+
+	dedupStore, err := events.NewMongoDedupStore(startupCtx, db.Collection("processed_messages"), 7*24*time.Hour)
+	if err != nil {
+		logger.Error("failed to create dedup store", "err", err)
+		os.Exit(1)
+	}
+
 	nosqlDB := repo.NewRepository(collection)
 	fulfillmentService := servcie.NewService(nosqlDB)
 	h := handler.NewHandler(logger, fulfillmentService)
 
+	metrics := events.NewMetrics(prometheus.DefaultRegisterer)
+
 	router := events.NewRouter()
 	router.Handle(
 		"booking.created",
-		h.HandleOrderCreated,
+		events.Handle(h.HandleOrderCreated),
+		events.WithTracing(nil),
+		events.WithMetrics(metrics),
 		events.WithLogging(logger),
-		events.WithRetry(3, 500*time.Millisecond),
+		events.WithDeduplication(dedupStore),
+		events.WithRetryTopics(events.DefaultRetryTiers...),
 	)
 
 	consumerCfg := events.DefaultConsumerConfig([]string{"localhost:9092"}, "fulfillment")
+	consumerCfg.Metrics = metrics
 	consumer, err := events.NewConsumer(consumerCfg, router, logger)
 	if err != nil {
 		logger.Error("failed to create consumer", "err", err)
@@ -69,6 +106,14 @@ func main() {
 	}
 	defer consumer.Close()
 
+	metricsSrv := &http.Server{Addr: metricsAddr, Handler: promhttp.Handler()}
+	go func() {
+		if err := metricsSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", "err", err)
+		}
+	}()
+	defer metricsSrv.Close()
+
 	logger.Info("fulfillment service started")
 	if err := consumer.Start(appCtx); err != nil {
 		logger.Error("consumer exited with error", "err", err)
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"strconv"
 	"ticket-tix/common/pkg/events"
@@ -25,12 +24,8 @@ func NewHandler(logger *slog.Logger, service model.FulfillmentService) *Handler
 	}
 }
 
-func (h *Handler) HandleOrderCreated(ctx context.Context, msg events.Message) error {
-	var request events.BookingCreatedEvent
-	if err := json.Unmarshal(msg.Value, &request); err != nil {
-		h.logger.Error("failed to unmarshal booking created event", "error", err)
-		return err
-	}
+func (h *Handler) HandleOrderCreated(ctx context.Context, env events.Envelope[events.BookingCreatedEvent]) error {
+	request := env.Data
 	bookingData := h.toBooking(request)
 	err := h.service.InsertFulfillment(ctx, bookingData)
 	if err != nil {
@@ -38,7 +33,7 @@ func (h *Handler) HandleOrderCreated(ctx context.Context, msg events.Message) er
 		return err
 	}
 
-	h.logger.Info("successfully processed booking created event", "booking_id", request.BookingID)
+	h.logger.Info("successfully processed booking created event", "booking_id", request.BookingID, "event_id", env.ID)
 	return nil
 }
 
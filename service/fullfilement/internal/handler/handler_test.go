@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"ticket-tix/common/pkg/events"
+	"ticket-tix/service/fullfilement/internal/model"
+	"ticket-tix/service/fullfilement/internal/servcie"
+	"time"
+)
+
+const (
+	bookingTopic = "booking.created"
+	group        = "fulfillment"
+)
+
+var errMongoDown = errors.New("mongo unavailable")
+
+// TestBookingCreatedFlow runs booking.created events through the broker, the router
+// with the middleware of cmd/api.go and the fulfillment handler.
+func TestBookingCreatedFlow(t *testing.T) {
+	// retry topics are named in whole seconds, so these are the shortest distinct tiers
+	tiers := []time.Duration{time.Second, 2 * time.Second}
+	tier1 := events.RetryTopic(bookingTopic, tiers[0])
+	tier2 := events.RetryTopic(bookingTopic, tiers[1])
+	dlq := bookingTopic + ".dlq"
+
+	repo := newFakeRepo(map[string]int{
+		// fails on the main topic, then succeeds on the first retry topic
+		"b-retry": 1,
+		// never succeeds and ends up in the DLQ
+		"b-dead": -1,
+	})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := NewHandler(logger, servcie.NewService(repo))
+
+	router := events.NewRouter()
+	router.Handle(
+		bookingTopic,
+		events.Handle(h.HandleOrderCreated),
+		events.WithDeduplication(newMemoryDedupStore()),
+		events.WithRetryTopics(tiers...),
+	)
+
+	broker := events.NewMemoryBroker()
+	ctx := context.Background()
+	paid := encodeBooking(t, "b-paid")
+	for _, msg := range []events.Message{
+		paid,
+		paid, // redelivered after a crash, same event id
+		encodeBooking(t, "b-retry"),
+		encodeBooking(t, "b-dead"),
+	} {
+		if err := broker.Publish(ctx, bookingTopic, msg); err != nil {
+			t.Fatalf("Publish() = %v", err)
+		}
+	}
+
+	runConsumer(t, broker, router, func() bool {
+		return broker.Committed(group, bookingTopic) == 4 &&
+			broker.Committed(group, tier1) == 2 &&
+			broker.Committed(group, tier2) == 1
+	})
+
+	for booking, want := range map[string]int{"b-paid": 1, "b-retry": 1, "b-dead": 0} {
+		if got := repo.inserted(booking); got != want {
+			t.Errorf("fulfillments of %s = %d, want %d", booking, got, want)
+		}
+	}
+
+	retried := broker.Messages(tier1)
+	if len(retried) != 2 {
+		t.Fatalf("%s has %d messages, want 2", tier1, len(retried))
+	}
+	for _, msg := range retried {
+		if msg.Headers[events.HeaderRetryAttempt] != "1" {
+			t.Errorf("%s retry-attempt = %q, want 1", msg.Key, msg.Headers[events.HeaderRetryAttempt])
+		}
+		if msg.Headers[events.HeaderRetryError] != errMongoDown.Error() {
+			t.Errorf("%s retry-error = %q, want %q", msg.Key, msg.Headers[events.HeaderRetryError], errMongoDown)
+		}
+		if msg.Headers[events.HeaderNotBefore] == "" {
+			t.Errorf("%s has no not-before header", msg.Key)
+		}
+		if msg.Headers[events.HeaderEventID] == "" {
+			t.Errorf("%s lost its event-id", msg.Key)
+		}
+	}
+	if got := broker.Messages(tier2); len(got) != 1 || got[0].Headers[events.HeaderRetryAttempt] != "2" {
+		t.Fatalf("%s = %v, want b-dead on attempt 2", tier2, got)
+	}
+
+	dead := broker.Messages(dlq)
+	if len(dead) != 1 || string(dead[0].Key) != "b-dead" {
+		t.Fatalf("%s = %v, want only b-dead", dlq, dead)
+	}
+	headers := dead[0].Headers
+	if headers[events.HeaderOriginalTopic] != bookingTopic {
+		t.Errorf("original-topic = %q, want %q", headers[events.HeaderOriginalTopic], bookingTopic)
+	}
+	if headers[events.HeaderOriginalPartition] != "0" || headers[events.HeaderOriginalOffset] == "" {
+		t.Errorf("original partition/offset = %q/%q", headers[events.HeaderOriginalPartition], headers[events.HeaderOriginalOffset])
+	}
+	if headers[events.HeaderError] != errMongoDown.Error() {
+		t.Errorf("error = %q, want %q", headers[events.HeaderError], errMongoDown)
+	}
+	if _, ok := headers[events.HeaderRetryAttempt]; ok {
+		t.Errorf("DLQ record kept retry headers: %v", headers)
+	}
+
+	// a restarted consumer resumes from the committed offsets and handles nothing again
+	if err := broker.Publish(ctx, bookingTopic, paid); err != nil {
+		t.Fatalf("Publish() = %v", err)
+	}
+	runConsumer(t, broker, router, func() bool {
+		return broker.Committed(group, bookingTopic) == 5
+	})
+	if got := repo.inserted("b-paid"); got != 1 {
+		t.Errorf("fulfillments of b-paid after restart = %d, want 1", got)
+	}
+	if got := repo.inserted("b-retry"); got != 1 {
+		t.Errorf("fulfillments of b-retry after restart = %d, want 1", got)
+	}
+	if got := len(broker.Messages(dlq)); got != 1 {
+		t.Errorf("%s has %d messages after restart, want 1", dlq, got)
+	}
+}
+
+func encodeBooking(t *testing.T, bookingID string) events.Message {
+	t.Helper()
+	msg, err := events.Encode("booking-service", bookingID, events.BookingCreatedEvent{
+		BookingID:    bookingID,
+		UserID:       7,
+		EventID:      1,
+		EventCatID:   2,
+		SeatNumber:   "A1",
+		CategoryType: "SEATED",
+		OccurredAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Encode() = %v", err)
+	}
+	return msg
+}
+
+// runConsumer consumes from broker until done reports true, then stops the consumer.
+func runConsumer(t *testing.T, broker *events.MemoryBroker, router *events.Router, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	consumer := broker.Consumer(events.ConsumerConfig{GroupID: group}, router, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	stopped := make(chan error, 1)
+	go func() { stopped <- consumer.Start(ctx) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("consumer did not finish in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	consumer.Close()
+	if err := <-stopped; err != nil {
+		t.Fatalf("consumer Start() = %v", err)
+	}
+}
+
+// fakeRepo counts fulfillments per booking. A booking fails the given number of
+// times before it is stored, or always when the number is negative.
+type fakeRepo struct {
+	mu       sync.Mutex
+	failures map[string]int
+	rows     map[string]int
+}
+
+func newFakeRepo(failures map[string]int) *fakeRepo {
+	return &fakeRepo{failures: failures, rows: make(map[string]int)}
+}
+
+func (r *fakeRepo) CreateFulfillment(_ context.Context, fulfillment model.Booking) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := r.failures[fulfillment.BookingID]; n != 0 {
+		if n > 0 {
+			r.failures[fulfillment.BookingID] = n - 1
+		}
+		return errMongoDown
+	}
+	if fulfillment.Status != confirmedStatus || fulfillment.UserID != strconv.Itoa(7) {
+		return errors.New("unexpected fulfillment " + fulfillment.BookingID)
+	}
+	r.rows[fulfillment.BookingID]++
+	return nil
+}
+
+func (r *fakeRepo) CancelFulfillment(context.Context, string) error {
+	return nil
+}
+
+func (r *fakeRepo) inserted(bookingID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rows[bookingID]
+}
+
+type memoryDedupStore struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func newMemoryDedupStore() *memoryDedupStore {
+	return &memoryDedupStore{keys: make(map[string]bool)}
+}
+
+func (s *memoryDedupStore) Seen(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[key], nil
+}
+
+func (s *memoryDedupStore) MarkProcessed(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key] = true
+	return nil
+}
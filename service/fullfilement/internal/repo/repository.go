@@ -3,10 +3,14 @@ package repo
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
 	"ticket-tix/service/fullfilement/internal/model"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
@@ -23,8 +27,78 @@ func NewRepository(collection *mongo.Collection) model.FulfillmentRepo {
 	}
 }
 
+// EnsureIndexes creates the unique booking_id index that backs idempotent inserts.
+// Fulfillments inserted twice before the index existed would make it fail, so they
+// are removed first.
+func EnsureIndexes(ctx context.Context, collection *mongo.Collection, logger *slog.Logger) error {
+	if err := removeDuplicates(ctx, collection, logger); err != nil {
+		return err
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "booking_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("create booking_id index: %w", err)
+	}
+	return nil
+}
+
+type duplicateFulfillments struct {
+	BookingID string               `bson:"_id"`
+	IDs       []primitive.ObjectID `bson:"ids"`
+	Statuses  []string             `bson:"statuses"`
+}
+
+// removeDuplicates keeps the first fulfillment of every booking and deletes the
+// others. A booking cancelled on any of its copies stays cancelled, as
+// CancelFulfillment only updated one of them.
+func removeDuplicates(ctx context.Context, collection *mongo.Collection, logger *slog.Logger) error {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$booking_id"},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			{Key: "statuses", Value: bson.D{{Key: "$push", Value: "$status"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("find duplicate fulfillments: %w", err)
+	}
+	var duplicates []duplicateFulfillments
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return fmt.Errorf("find duplicate fulfillments: %w", err)
+	}
+
+	for _, dup := range duplicates {
+		logger.Warn("duplicate fulfillments block the booking_id index, keeping the first",
+			"booking_id", dup.BookingID, "count", len(dup.IDs))
+
+		kept := dup.IDs[0]
+		if slices.Contains(dup.Statuses, CancelledStatus) {
+			_, err := collection.UpdateByID(ctx, kept, bson.M{"$set": bson.M{"status": CancelledStatus}})
+			if err != nil {
+				return fmt.Errorf("cancel kept fulfillment of booking %s: %w", dup.BookingID, err)
+			}
+		}
+		_, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": dup.IDs[1:]}})
+		if err != nil {
+			return fmt.Errorf("delete duplicate fulfillments of booking %s: %w", dup.BookingID, err)
+		}
+	}
+	if len(duplicates) > 0 {
+		logger.Info("removed duplicate fulfillments", "bookings", len(duplicates))
+	}
+	return nil
+}
+
 func (m *mongoFulfillmentRepository) CreateFulfillment(ctx context.Context, fulfillment model.Booking) error {
 	_, err := m.collection.InsertOne(ctx, fulfillment)
+	if mongo.IsDuplicateKeyError(err) {
+		// already fulfilled by an earlier delivery of the same booking
+		return nil
+	}
 	if err != nil {
 		fmt.Println("failed to insert fulfillment:", err)
 		return err
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -11,7 +12,13 @@ import (
 	"sync"
 	"syscall"
 	"ticket-tix/common/pkg/db"
+	"ticket-tix/common/pkg/events"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/common/pkg/lock"
+	"ticket-tix/common/pkg/middleware"
+	"ticket-tix/common/pkg/scheduler"
 	"ticket-tix/common/pkg/storage"
+	"ticket-tix/common/pkg/tracing"
 	"ticket-tix/service/ticket/cmd/jobs"
 	"ticket-tix/service/ticket/internal/handler"
 	intRedis "ticket-tix/service/ticket/internal/infra/redis"
@@ -22,6 +29,7 @@ import (
 	ticketRPC "ticket-tix/common/gen/ticket/v1"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
@@ -47,10 +55,35 @@ const (
 
 	// rpc
 	rpcAddr = "40061"
+
+	// auth
+	authJWKSURL = "http://localhost:50061/.well-known/jwks.json"
+	jwksTTL     = 5 * time.Minute
+
+	// kafka
+	kafkaAddr = "localhost:9092"
+
+	// background jobs
+	stockReconcileSchedule = "@every 1m"
+	// which side the stock reconciliation overwrites: "redis" (default), "postgres" or "none"
+	stockRepairDirectionEnv = "STOCK_REPAIR_DIRECTION"
+	jobHistorySize          = 200
 )
 
 func main() {
 	log.Println("ticket-tix start")
+	shutdownTracing, err := tracing.Init("ticket-service")
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("tracer provider shutdown failed: %v", err)
+		}
+	}()
+
 	// centralized signal handling
 	backgroundCtx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -65,9 +98,15 @@ func main() {
 
 	stockCounter := intRedis.NewStockCounter(redisClient)
 
+	producer, producerErr := events.NewProducer(events.GetDefaultConfig([]string{kafkaAddr}))
+	if producerErr != nil {
+		log.Fatalf("failed to create producer: %v", producerErr)
+	}
+	defer producer.Close()
+
 	ticketRepo := repository.NewTicketRepo(ticketDB)
 	ticketService := service.NewTicketService(ticketDB, minioStorage, ticketRepo)
-	ticketHandler := handler.NewTicketHandler(ticketService)
+	ticketHandler := handler.NewTicketHandler(ticketService, jwt.NewJWKSCache(authJWKSURL, jwksTTL), redisClient)
 
 	grpcServer := grpc.NewServer()
 	rpcHandler := handler.NewRPCHandler(ticketService, stockCounter)
@@ -75,20 +114,45 @@ func main() {
 	ticketRPC.RegisterTicketServiceServer(grpcServer, rpcHandler)
 	reflection.Register(grpcServer)
 
-	stockSynchronization := jobs.NewStockSyncJob(&stockCounter, ticketRepo)
-	if syncErr := stockSynchronization.SeedAll(backgroundCtx); syncErr != nil {
-		log.Fatalf("failed to seed stock counter: %v", syncErr)
+	stockRepairDirection := jobs.RepairRedis
+	if env := os.Getenv(stockRepairDirectionEnv); env != "" {
+		if stockRepairDirection, err = jobs.ParseRepairDirection(env); err != nil {
+			log.Fatalf("failed to read %s: %v", stockRepairDirectionEnv, err)
+		}
+	}
+	stockReconciliation := jobs.NewStockReconcileJob(&stockCounter, ticketRepo, jobs.StockReconcileConfig{
+		Direction:  stockRepairDirection,
+		NumWorkers: 5,
+	})
+	if seedErr := stockReconciliation.SeedAll(backgroundCtx); seedErr != nil {
+		log.Fatalf("failed to seed stock counter: %v", seedErr)
 	}
 
-	seatExpiringJob := jobs.NewExpireReservedSeatJob(ticketRepo)
-	go stockSynchronization.SyncEventStockJob(backgroundCtx)
-	go seatExpiringJob.Start(backgroundCtx)
+	seatExpiringJob := jobs.NewExpireReservedSeatJob(ticketService)
+	outboxRelayJob := jobs.NewOutboxRelayJob(ticketRepo, producer)
+	stockHoldExpiringJob := jobs.NewExpireStockHoldJob(&stockCounter, ticketRepo)
+
+	jobScheduler := scheduler.New(
+		lock.NewSetNXLock(redisClient),
+		scheduler.NewRedisHistory(redisClient, jobHistorySize),
+		slog.Default(),
+	)
+	registerJobs(jobScheduler, []scheduler.Job{
+		{Name: "stock-reconcile", Schedule: stockReconcileSchedule, Timeout: 2 * time.Minute, Run: stockReconciliation.RunOnce},
+		{Name: "expire-reserved-seats", Schedule: "@every 1m", Timeout: time.Minute, Run: seatExpiringJob.RunOnce},
+		{Name: "expire-stock-holds", Schedule: "@every 30s", Timeout: 30 * time.Second, Run: stockHoldExpiringJob.RunOnce},
+		{Name: "outbox-relay", Schedule: "@every 5s", Timeout: 30 * time.Second, Run: outboxRelayJob.RunOnce},
+	})
+	go jobScheduler.Start(backgroundCtx)
 	defer cancel()
 
 	r := gin.Default()
+	if err := middleware.SetTrustedProxies(r); err != nil {
+		log.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, Authorization")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -96,7 +160,10 @@ func main() {
 		}
 		c.Next()
 	})
+	r.Use(middleware.TracingMiddleware("ticket-service"))
 	ticketHandler.RegisterRoutes(r)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	jobScheduler.RegisterRoutes(ticketHandler.AdminGroup(r))
 
 	var wg sync.WaitGroup
 	httpServer := spinUpHTTPServer(r, &wg)
@@ -118,6 +185,14 @@ func main() {
 	log.Println("all servers stopped, bye!")
 }
 
+func registerJobs(s *scheduler.Scheduler, jobList []scheduler.Job) {
+	for _, job := range jobList {
+		if err := s.Register(job); err != nil {
+			log.Fatalf("failed to register job: %v", err)
+		}
+	}
+}
+
 func openRedisConnection() *redis.Client {
 	log.Println("opening redis connection")
 	redisClient := redis.NewClient(&redis.Options{
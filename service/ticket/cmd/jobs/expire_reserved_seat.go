@@ -2,42 +2,29 @@ package jobs
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"ticket-tix/service/ticket/internal/model"
-	"time"
 )
 
 type ExpireReservedSeatJob struct {
-	repo model.TicketRepo
+	svc model.TicketService
 }
 
-func NewExpireReservedSeatJob(repo model.TicketRepo) *ExpireReservedSeatJob {
-	return &ExpireReservedSeatJob{repo: repo}
+func NewExpireReservedSeatJob(svc model.TicketService) *ExpireReservedSeatJob {
+	return &ExpireReservedSeatJob{svc: svc}
 }
 
-func (e *ExpireReservedSeatJob) Start(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("context canceled, exiting expireReservedSeatJob")
-			return
-		case <-ticker.C:
-			log.Println("starting expireReservedSeatJob")
-			e.processExpireSeats(ctx)
-		}
-	}
-}
-
-func (e *ExpireReservedSeatJob) processExpireSeats(ctx context.Context) {
-	expiredSeats, err := e.repo.ExpireReservedSeats(ctx)
+// RunOnce releases reserved seats whose hold has run out. The matching
+// ticket.reservation.expired events are picked up by the outbox relay.
+func (e *ExpireReservedSeatJob) RunOnce(ctx context.Context) (int, error) {
+	expiredSeats, err := e.svc.ExpireReservedSeats(ctx)
 	if err != nil {
-		log.Printf("failed to expire reserved seats: %v", err)
-		return
+		return 0, fmt.Errorf("expire reserved seats: %w", err)
 	}
 
 	for _, seat := range expiredSeats {
 		log.Printf("expired reserved \nid: %d \nseat: %s \nevent category %d", seat.TicketID, seat.SeatNumber, seat.EventCategoryID)
 	}
+	return len(expiredSeats), nil
 }
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"ticket-tix/service/ticket/internal/infra/redis"
+	"ticket-tix/service/ticket/internal/model"
+	"time"
+)
+
+// expireStockHoldBatch caps how many holds a single tick releases.
+const expireStockHoldBatch = 500
+
+// ExpireStockHoldJob returns standing stock from reservations that were never
+// confirmed, the way ExpireReservedSeatJob does for seated tickets.
+type ExpireStockHoldJob struct {
+	counter *redis.StockCounter
+	repo    model.TicketRepo
+}
+
+func NewExpireStockHoldJob(counter *redis.StockCounter, repo model.TicketRepo) *ExpireStockHoldJob {
+	return &ExpireStockHoldJob{counter: counter, repo: repo}
+}
+
+// RunOnce returns the stock of holds that expired before now.
+func (e *ExpireStockHoldJob) RunOnce(ctx context.Context) (int, error) {
+	released, err := e.counter.ReleaseExpired(ctx, time.Now(), expireStockHoldBatch)
+
+	for _, hold := range released {
+		log.Printf("expired stock hold %s on event category %d, returned %d", hold.ReservationID, hold.EventCatID, hold.Qty)
+		movErr := e.repo.InsertStockMovement(ctx, model.StockMovement{
+			EventCatID: hold.EventCatID,
+			Delta:      hold.Qty,
+			Reason:     model.StockMovementExpired,
+			Reference:  hold.ReservationID,
+		})
+		if movErr != nil {
+			log.Printf("failed to record expired stock hold %s: %v", hold.ReservationID, movErr)
+		}
+	}
+
+	if err != nil {
+		return len(released), fmt.Errorf("release expired stock holds: %w", err)
+	}
+	return len(released), nil
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"ticket-tix/common/pkg/events"
+	"ticket-tix/service/ticket/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// outboxRelayBatch caps how many outbox rows a single run publishes.
+	outboxRelayBatch = 100
+	// outboxMaxAttempts is how often a row may fail to publish before it is dead
+	// lettered and the relay moves on to the rows behind it.
+	outboxMaxAttempts = 10
+)
+
+var outboxDeadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "ticket_outbox_dead_lettered_total",
+	Help: "Number of outbox rows dead lettered after failing to publish too often, by topic.",
+}, []string{"topic"})
+
+func init() {
+	prometheus.MustRegister(outboxDeadLettered)
+}
+
+// OutboxRelayJob publishes pending outbox rows to Kafka in insertion order. Delivery is
+// at-least-once: a crash between publishing and marking the row publishes it again.
+// A row that fails outboxMaxAttempts times is dead lettered: it keeps its last error
+// in the table and is skipped from then on.
+type OutboxRelayJob struct {
+	repo     model.TicketRepo
+	producer events.Producer
+}
+
+func NewOutboxRelayJob(repo model.TicketRepo, producer events.Producer) *OutboxRelayJob {
+	return &OutboxRelayJob{repo: repo, producer: producer}
+}
+
+func (o *OutboxRelayJob) RunOnce(ctx context.Context) (int, error) {
+	pending, err := o.repo.GetPendingOutboxMessages(ctx, outboxRelayBatch)
+	if err != nil {
+		return 0, fmt.Errorf("fetch outbox: %w", err)
+	}
+
+	published := 0
+	for _, msg := range pending {
+		pubErr := o.producer.Publish(ctx, msg.Topic, events.Message{
+			Key:     []byte(msg.Key),
+			Value:   msg.Payload,
+			Headers: msg.Headers,
+		})
+		if pubErr != nil && msg.Attempts+1 >= outboxMaxAttempts {
+			// give up on this row, so it does not hold up every row behind it forever
+			if err := o.repo.MarkOutboxMessageDeadLettered(ctx, msg.ID, pubErr.Error()); err != nil {
+				return published, fmt.Errorf("dead letter outbox message %d: %w", msg.ID, err)
+			}
+			outboxDeadLettered.WithLabelValues(msg.Topic).Inc()
+			log.Printf("dead lettered outbox message %d to %s after %d attempts: %v", msg.ID, msg.Topic, msg.Attempts+1, pubErr)
+			continue
+		}
+		if pubErr != nil {
+			if err := o.repo.MarkOutboxMessageFailed(ctx, msg.ID, pubErr.Error()); err != nil {
+				log.Printf("failed to mark outbox message %d as failed: %v", msg.ID, err)
+			}
+			// stop here so later events are not published ahead of this one
+			return published, fmt.Errorf("publish outbox message %d: %w", msg.ID, pubErr)
+		}
+
+		if err := o.repo.MarkOutboxMessagePublished(ctx, msg.ID); err != nil {
+			return published, fmt.Errorf("mark outbox message %d published: %w", msg.ID, err)
+		}
+		published++
+	}
+	return published, nil
+}
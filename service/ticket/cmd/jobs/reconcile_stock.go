@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"ticket-tix/service/ticket/internal/infra/redis"
+	"ticket-tix/service/ticket/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RepairDirection decides which side wins when Redis and Postgres disagree.
+type RepairDirection string
+
+const (
+	// RepairNone only reports drift.
+	RepairNone RepairDirection = "none"
+	// RepairRedis treats Postgres as the source of truth and overwrites Redis.
+	RepairRedis RepairDirection = "redis"
+	// RepairPostgres treats Redis as the source of truth and overwrites the capacity
+	// and available_stock the expected stock is computed from.
+	RepairPostgres RepairDirection = "postgres"
+)
+
+// ParseRepairDirection reads a RepairDirection from configuration.
+func ParseRepairDirection(s string) (RepairDirection, error) {
+	switch d := RepairDirection(s); d {
+	case RepairNone, RepairRedis, RepairPostgres:
+		return d, nil
+	}
+	return "", fmt.Errorf("unknown stock repair direction %q, want %q, %q or %q", s, RepairNone, RepairRedis, RepairPostgres)
+}
+
+type StockReconcileConfig struct {
+	Direction  RepairDirection
+	NumWorkers int
+}
+
+var (
+	stockDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ticket_stock_drift",
+		Help: "Redis stock plus open holds minus expected stock (capacity - confirmed standing bookings) per event category.",
+	}, []string{"event_category_id"})
+	stockReconcileRuns = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ticket_stock_reconcile_runs_total",
+		Help: "Number of completed stock reconciliation runs.",
+	})
+	stockReconcileRepairs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ticket_stock_reconcile_repairs_total",
+		Help: "Number of stock repairs applied, by the side that was overwritten.",
+	}, []string{"target"})
+	stockReconcileErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ticket_stock_reconcile_errors_total",
+		Help: "Number of event categories that could not be reconciled.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(stockDrift, stockReconcileRuns, stockReconcileRepairs, stockReconcileErrors)
+}
+
+// StockReconcileJob compares the Redis standing stock counters with what Postgres says
+// they should be and reports or repairs the difference.
+type StockReconcileJob struct {
+	counter *redis.StockCounter
+	repo    model.TicketRepo
+	cfg     StockReconcileConfig
+
+	// lastDrift holds the drift seen on the previous run. A repair only happens when
+	// the same drift is observed twice in a row, so bookings that are between the
+	// Redis decrement and the Postgres insert are not "fixed" away.
+	mu        sync.Mutex
+	lastDrift map[int32]int64
+}
+
+func NewStockReconcileJob(counter *redis.StockCounter, repo model.TicketRepo, cfg StockReconcileConfig) *StockReconcileJob {
+	if cfg.NumWorkers <= 0 {
+		cfg.NumWorkers = 5
+	}
+	if cfg.Direction == "" {
+		cfg.Direction = RepairNone
+	}
+	return &StockReconcileJob{
+		counter:   counter,
+		repo:      repo,
+		cfg:       cfg,
+		lastDrift: make(map[int32]int64),
+	}
+}
+
+// SeedAll initialises missing Redis counters from Postgres. Existing counters are left
+// alone; any disagreement is handled by the reconciliation loop.
+func (s *StockReconcileJob) SeedAll(ctx context.Context) error {
+	stocks, err := s.repo.GetStandingStockExpectations(ctx)
+	if err != nil {
+		return fmt.Errorf("get standing stock: %w", err)
+	}
+
+	for _, stock := range stocks {
+		if err := s.counter.Seed(ctx, stock.EventCatID, stock.Expected()); err != nil {
+			return fmt.Errorf("seed category %d: %w", stock.EventCatID, err)
+		}
+	}
+	return nil
+}
+
+// RunOnce reconciles every standing category once.
+func (s *StockReconcileJob) RunOnce(ctx context.Context) (int, error) {
+	stocks, err := s.repo.GetStandingStockExpectations(ctx)
+	if err != nil {
+		stockReconcileErrors.Inc()
+		return 0, fmt.Errorf("fetch standing stock: %w", err)
+	}
+	s.runWorkerPool(ctx, stocks)
+	stockReconcileRuns.Inc()
+	return len(stocks), nil
+}
+
+func (s *StockReconcileJob) runWorkerPool(ctx context.Context, jobs []model.StandingStock) {
+	var wg sync.WaitGroup
+	jobChan := make(chan model.StandingStock, s.cfg.NumWorkers)
+
+	for i := 0; i < s.cfg.NumWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobChan {
+				select {
+				case <-ctx.Done():
+					log.Println("context canceled, worker exiting")
+					return
+				default:
+					s.reconcile(ctx, job)
+				}
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		jobChan <- job
+	}
+	close(jobChan)
+	wg.Wait()
+}
+
+func (s *StockReconcileJob) reconcile(ctx context.Context, stock model.StandingStock) {
+	label := fmt.Sprint(stock.EventCatID)
+	expected := stock.Expected()
+
+	actual, err := s.counter.Get(ctx, stock.EventCatID)
+	if errors.Is(err, redis.ErrStockMissing) {
+		// a lost counter can only be rebuilt from Postgres, whatever the direction
+		log.Printf("stock counter for event category %d missing, restoring %d", stock.EventCatID, expected)
+		s.repairRedis(ctx, stock, 0, 0)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to get stock for event category %d: %v", stock.EventCatID, err)
+		stockReconcileErrors.Inc()
+		return
+	}
+
+	// open holds are taken from Redis but not yet booked in Postgres
+	held, err := s.counter.Held(ctx, stock.EventCatID)
+	if err != nil {
+		log.Printf("failed to get held stock for event category %d: %v", stock.EventCatID, err)
+		stockReconcileErrors.Inc()
+		return
+	}
+
+	drift := actual + held - expected
+	stockDrift.WithLabelValues(label).Set(float64(drift))
+
+	confirmed := s.observe(stock.EventCatID, drift)
+	if drift == 0 {
+		// both sides agree; keep the denormalised available_stock column in step
+		if stock.AvailableStock != actual {
+			if err := s.repo.UpdateEventCategoryStock(ctx, stock.EventCatID, actual); err != nil {
+				log.Printf("failed to update stock for event category %d: %v", stock.EventCatID, err)
+				stockReconcileErrors.Inc()
+			}
+		}
+		return
+	}
+
+	log.Printf("stock drift for event category %d: redis=%d held=%d expected=%d", stock.EventCatID, actual, held, expected)
+	if !confirmed {
+		return
+	}
+
+	switch s.cfg.Direction {
+	case RepairRedis:
+		s.repairRedis(ctx, stock, actual, held)
+	case RepairPostgres:
+		s.repairPostgres(ctx, stock, actual, held)
+	}
+}
+
+// observe records the drift for a category and reports whether it matches the
+// drift from the previous run.
+func (s *StockReconcileJob) observe(eventCatID int32, drift int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, seen := s.lastDrift[eventCatID]
+	s.lastDrift[eventCatID] = drift
+	return seen && prev == drift
+}
+
+func (s *StockReconcileJob) repairRedis(ctx context.Context, stock model.StandingStock, actual, held int64) {
+	target := stock.Expected() - held
+	if err := s.counter.Set(ctx, stock.EventCatID, target); err != nil {
+		log.Printf("failed to repair redis stock for event category %d: %v", stock.EventCatID, err)
+		stockReconcileErrors.Inc()
+		return
+	}
+	if err := s.repo.UpdateEventCategoryStock(ctx, stock.EventCatID, target); err != nil {
+		log.Printf("failed to update stock for event category %d: %v", stock.EventCatID, err)
+		stockReconcileErrors.Inc()
+	}
+	s.recordRepair(ctx, stock.EventCatID, target-actual, "redis")
+}
+
+// repairPostgres moves the capacity so that the expected stock matches what Redis
+// holds; writing only available_stock would leave the drift in place for the next run.
+func (s *StockReconcileJob) repairPostgres(ctx context.Context, stock model.StandingStock, actual, held int64) {
+	capacity := actual + held + stock.Confirmed
+	if err := s.repo.UpdateEventCategoryCapacity(ctx, stock.EventCatID, capacity, actual); err != nil {
+		log.Printf("failed to update capacity for event category %d: %v", stock.EventCatID, err)
+		stockReconcileErrors.Inc()
+		return
+	}
+	s.recordRepair(ctx, stock.EventCatID, capacity-stock.TotalCapacity, "postgres")
+}
+
+func (s *StockReconcileJob) recordRepair(ctx context.Context, eventCatID int32, delta int64, target string) {
+	stockReconcileRepairs.WithLabelValues(target).Inc()
+	if delta == 0 {
+		return
+	}
+
+	err := s.repo.InsertStockMovement(ctx, model.StockMovement{
+		EventCatID: eventCatID,
+		Delta:      delta,
+		Reason:     model.StockMovementRepair,
+		Reference:  target,
+	})
+	if err != nil {
+		log.Printf("failed to record stock repair for event category %d: %v", eventCatID, err)
+	}
+}
@@ -3,32 +3,85 @@ package handler
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"ticket-tix/common/pkg/jwt"
+	"ticket-tix/common/pkg/middleware"
 	"ticket-tix/service/ticket/internal/model"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
 type TicketHandler struct {
-	service model.TicketService
+	service     model.TicketService
+	keys        jwt.KeySource
+	redisClient *redis.Client
 }
 
-func NewTicketHandler(svc model.TicketService) *TicketHandler {
-	return &TicketHandler{service: svc}
+func NewTicketHandler(svc model.TicketService, keys jwt.KeySource, redisClient *redis.Client) *TicketHandler {
+	return &TicketHandler{service: svc, keys: keys, redisClient: redisClient}
 }
 
 func (h *TicketHandler) RegisterRoutes(router gin.IRouter) {
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
-	router.POST("/events", h.CreateEvent)
-	router.POST("/events/:id/images", h.UploadImage)
-	router.DELETE("/events/:id/images/:imageID", h.DeleteImage)
 	router.GET("/event/:id", h.GetEvent)
 	router.GET("/events", h.BrowseEvents)
+	router.GET("/event/:id/seating-chart", h.GetEventSeatingChart)
+	router.GET("/venues/:id/chart", h.GetVenueChart)
+
+	organizer := router.Group("")
+	organizer.Use(
+		middleware.AuthMiddleware(h.keys, h.redisClient),
+		middleware.RequireRole(jwt.RoleOrganizer, jwt.RoleAdmin),
+	)
+	organizer.POST("/events", h.CreateEvent)
+	organizer.POST("/events/:id/images", h.UploadImage)
+	organizer.DELETE("/events/:id/images/:imageID", h.DeleteImage)
+	organizer.POST("/events/:id/layout", h.ApplyEventLayout)
+	organizer.GET("/organizer/events", h.BrowseOrganizerEvents)
+	organizer.POST("/venues", h.CreateVenue)
+}
+
+// AdminGroup returns a route group that only admins can reach.
+func (h *TicketHandler) AdminGroup(router gin.IRouter) *gin.RouterGroup {
+	admin := router.Group("/admin")
+	admin.Use(
+		middleware.AuthMiddleware(h.keys, h.redisClient),
+		middleware.RequireRole(jwt.RoleAdmin),
+	)
+	return admin
+}
+
+// actorFrom reads the authenticated user set by AuthMiddleware.
+func actorFrom(c *gin.Context) model.Actor {
+	return model.Actor{
+		UserID: c.GetInt32("userID"),
+		Role:   c.GetString("role"),
+		OrgID:  c.GetInt32("orgID"),
+	}
+}
+
+// errorStatus maps the access and validation errors of the service to HTTP statuses.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, model.ErrEventNotFound),
+		errors.Is(err, model.ErrVenueNotFound),
+		errors.Is(err, model.ErrEventCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, model.ErrInvalidLayout):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrLayoutConflict):
+		return http.StatusConflict
+	}
+	return fallback
 }
 
 type createEventRequest struct {
@@ -37,6 +90,8 @@ type createEventRequest struct {
 	Location    string `form:"location" binding:"required"`
 	StartTime   string `form:"start_time" binding:"required"`
 	EndTime     string `form:"end_time" binding:"required"`
+	// OrganizerID is only honoured for admins; organizers always create for their own org.
+	OrganizerID int32 `form:"organizer_id"`
 }
 
 func (h *TicketHandler) CreateEvent(c *gin.Context) {
@@ -71,13 +126,15 @@ func (h *TicketHandler) CreateEvent(c *gin.Context) {
 			Location:    req.Location,
 			StartTime:   startTime,
 			EndTime:     endTime,
+			CreatedBy:   c.GetInt32("userID"),
+			OrganizerID: req.OrganizerID,
 		},
 		Files: files,
 	}
 
-	result, err := h.service.CreateEvent(c.Request.Context(), serviceReq)
+	result, err := h.service.CreateEvent(c.Request.Context(), actorFrom(c), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -97,8 +154,8 @@ func (h *TicketHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UploadImg(c.Request.Context(), int32(id), files); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.UploadImg(c.Request.Context(), actorFrom(c), int32(id), files); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -106,14 +163,20 @@ func (h *TicketHandler) UploadImage(c *gin.Context) {
 }
 
 func (h *TicketHandler) DeleteImage(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event_id"})
+		return
+	}
+
 	key := c.Param("imageID")
 	if key == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "imageID is required"})
 		return
 	}
 
-	if err := h.service.DeleteImg(c.Request.Context(), key); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.DeleteImg(c.Request.Context(), actorFrom(c), int32(id), key); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -137,12 +200,13 @@ func (h *TicketHandler) GetEvent(c *gin.Context) {
 }
 
 type browseEventsRequest struct {
-	EventName string `form:"event_name"`
-	Location  string `form:"location"`
-	StartDate string `form:"start_date"`
-	EndDate   string `form:"end_date"`
-	Cursor    string `form:"cursor"`
-	Limit     int    `form:"limit,default=20" binding:"min=1,max=100"`
+	EventName   string `form:"event_name"`
+	Location    string `form:"location"`
+	StartDate   string `form:"start_date"`
+	EndDate     string `form:"end_date"`
+	OrganizerID int32  `form:"organizer_id"`
+	Cursor      string `form:"cursor"`
+	Limit       int    `form:"limit,default=20" binding:"min=1,max=100"`
 }
 
 func (h *TicketHandler) BrowseEvents(c *gin.Context) {
@@ -167,11 +231,34 @@ func (h *TicketHandler) BrowseEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *TicketHandler) BrowseOrganizerEvents(c *gin.Context) {
+	var req browseEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := toBrowseFilter(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.BrowseOrganizerEvents(c.Request.Context(), actorFrom(c), filter)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func toBrowseFilter(req browseEventsRequest) (model.BrowseFilter, error) {
 	filter := model.BrowseFilter{
-		EventName: req.EventName,
-		Location:  req.Location,
-		Limit:     req.Limit,
+		EventName:   req.EventName,
+		Location:    req.Location,
+		OrganizerID: req.OrganizerID,
+		Limit:       req.Limit,
 	}
 
 	if req.StartDate != "" {
@@ -2,15 +2,21 @@ package handler
 
 import (
 	"context"
+	"log"
 	"strings"
 	ticketRPC "ticket-tix/common/gen/ticket/v1"
 	"ticket-tix/service/ticket/internal/infra/redis"
 	"ticket-tix/service/ticket/internal/model"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// defaultStockHold is how long a standing stock reservation lives when the caller does
+// not ask for a specific hold.
+const defaultStockHold = 10 * time.Minute
+
 type RPCHandler struct {
 	ticketRPC.UnimplementedTicketServiceServer
 	svc          model.TicketService
@@ -92,19 +98,107 @@ func (h *RPCHandler) ReserveSeat(ctx context.Context, req *ticketRPC.ReserveFlex
 
 func (h *RPCHandler) DecreaseTicket(ctx context.Context, req *ticketRPC.DecreaseTicketRequest) (*ticketRPC.DecreaseTicketResponse, error) {
 	eventCat := req.GetEventCategoryId()
-	//decreaseBy := req.GetDecreaseBy()
-	if err := h.stockCounter.Decrement(ctx, eventCat, 1); err != nil {
+	reservationID := req.GetReservationId()
+	if reservationID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reservation_id is required")
+	}
+
+	qty := req.GetDecreaseBy()
+	if qty <= 0 {
+		qty = 1
+	}
+	hold := time.Duration(req.GetHoldSeconds()) * time.Second
+	if hold <= 0 {
+		hold = defaultStockHold
+	}
+	expiresAt := time.Now().Add(hold)
+
+	remaining, err := h.stockCounter.Reserve(ctx, eventCat, reservationID, qty, expiresAt)
+	if err != nil {
 		return nil, status.Errorf(codes.ResourceExhausted, "failed to decrease ticket stock: %v", err)
 	}
-	return &ticketRPC.DecreaseTicketResponse{}, nil
+	h.recordStockMovement(ctx, eventCat, -qty, model.StockMovementHold, reservationID)
+	return &ticketRPC.DecreaseTicketResponse{
+		Remaining: remaining,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
 }
 
 func (h *RPCHandler) IncreaseTicket(ctx context.Context, req *ticketRPC.IncreaseTicketRequest) (*ticketRPC.IncreaseTicketResponse, error) {
 	eventCat := req.GetEventCategoryId()
-	increaseBy := req.GetIncreaseBy()
+	reservationID := req.GetReservationId()
+	if reservationID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reservation_id is required")
+	}
 
-	if err := h.stockCounter.Increment(ctx, eventCat, increaseBy); err != nil {
-		return nil, status.Errorf(codes.ResourceExhausted, "failed to increase ticket stock: %v", err)
+	released, err := h.stockCounter.Release(ctx, eventCat, reservationID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to increase ticket stock: %v", err)
+	}
+	if released > 0 {
+		h.recordStockMovement(ctx, eventCat, released, model.StockMovementRelease, reservationID)
 	}
 	return &ticketRPC.IncreaseTicketResponse{}, nil
 }
+
+func (h *RPCHandler) ConfirmTicketStock(ctx context.Context, req *ticketRPC.ConfirmTicketStockRequest) (*ticketRPC.ConfirmTicketStockResponse, error) {
+	reservationID := req.GetReservationId()
+	if reservationID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "reservation_id is required")
+	}
+
+	eventCat := req.GetEventCategoryId()
+	committed, err := h.stockCounter.Commit(ctx, eventCat, reservationID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to confirm ticket stock: %v", err)
+	}
+	if committed == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "stock reservation %s not found or expired", reservationID)
+	}
+	// the counter does not move: the held stock becomes sold
+	h.recordStockMovement(ctx, eventCat, committed, model.StockMovementHold, reservationID)
+	h.recordStockMovement(ctx, eventCat, -committed, model.StockMovementBooking, reservationID)
+	return &ticketRPC.ConfirmTicketStockResponse{}, nil
+}
+
+// recordStockMovement writes the ledger entry for a Redis stock change. The ledger is
+// an audit trail for reconciliation, so a failed write is logged instead of failing the RPC.
+func (h *RPCHandler) recordStockMovement(ctx context.Context, eventCat int32, delta int64, reason, reference string) {
+	err := h.svc.RecordStockMovement(ctx, model.StockMovement{
+		EventCatID: eventCat,
+		Delta:      delta,
+		Reason:     reason,
+		Reference:  reference,
+	})
+	if err != nil {
+		log.Printf("failed to record stock movement for event category %d: %v", eventCat, err)
+	}
+}
+
+func (h *RPCHandler) ReserveAccessibleSeat(ctx context.Context, req *ticketRPC.ReserveAccessibleSeatRequest) (*ticketRPC.ReserveAccessibleSeatResponse, error) {
+	pair, err := h.svc.ReserveAccessibleSeatPair(ctx, req.GetEventCategoryId())
+	if err != nil {
+		return nil, status.Errorf(codes.ResourceExhausted, "failed to reserve accessible seat pair: %v", err)
+	}
+	return &ticketRPC.ReserveAccessibleSeatResponse{
+		TicketId:            pair.TicketID,
+		SeatNumber:          pair.SeatNumber,
+		CompanionTicketId:   pair.CompanionTicketID,
+		CompanionSeatNumber: pair.CompanionSeatNumber,
+	}, nil
+}
+
+func (h *RPCHandler) ReleaseAccessibleSeat(ctx context.Context, req *ticketRPC.ReleaseAccessibleSeatRequest) (*ticketRPC.ReleaseAccessibleSeatResponse, error) {
+	if req.GetTicketId() == 0 || req.GetCompanionTicketId() == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "ticket_id and companion_ticket_id are required")
+	}
+
+	err := h.svc.ReleaseAccessibleSeatPair(ctx, req.GetEventCategoryId(), req.GetTicketId(), req.GetCompanionTicketId())
+	if err != nil {
+		if strings.Contains(err.Error(), "is not sold") {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "release accessible seat pair: %v", err)
+	}
+	return &ticketRPC.ReleaseAccessibleSeatResponse{}, nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"ticket-tix/service/ticket/internal/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *TicketHandler) CreateVenue(c *gin.Context) {
+	var req model.VenueChart
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.CreateVenue(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *TicketHandler) GetVenueChart(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid venue_id"})
+		return
+	}
+
+	chart, err := h.service.GetVenueChart(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, chart)
+}
+
+type applyEventLayoutRequest struct {
+	VenueID             int32                          `json:"venue_id" binding:"required"`
+	Sections            []model.SectionCategoryMapping `json:"sections" binding:"required,min=1"`
+	AccessibleReleaseAt *time.Time                     `json:"accessible_release_at"`
+}
+
+func (h *TicketHandler) ApplyEventLayout(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event_id"})
+		return
+	}
+
+	var req applyEventLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.service.ApplyEventLayout(c.Request.Context(), actorFrom(c), model.EventLayoutRequest{
+		EventID:             int32(id),
+		VenueID:             req.VenueID,
+		Sections:            req.Sections,
+		AccessibleReleaseAt: req.AccessibleReleaseAt,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "layout applied", "tickets_created": created})
+}
+
+func (h *TicketHandler) GetEventSeatingChart(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid event_id"})
+		return
+	}
+
+	chart, err := h.service.GetEventSeatingChart(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, chart)
+}
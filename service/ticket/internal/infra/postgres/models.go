@@ -6,6 +6,7 @@ package ticketDB
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +27,15 @@ type CategoryType struct {
 	Name string `json:"name"`
 }
 
+type EmailVerificationToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
 type Event struct {
 	ID          int32          `json:"id"`
 	Name        string         `json:"name"`
@@ -34,17 +44,21 @@ type Event struct {
 	StartTime   time.Time      `json:"start_time"`
 	EndTime     time.Time      `json:"end_time"`
 	CreatedAt   sql.NullTime   `json:"created_at"`
+	VenueID     sql.NullInt32  `json:"venue_id"`
+	CreatedBy   sql.NullInt32  `json:"created_by"`
+	OrganizerID sql.NullInt32  `json:"organizer_id"`
 }
 
 type EventCategory struct {
-	ID             int32          `json:"id"`
-	EventID        int32          `json:"event_id"`
-	Name           string         `json:"name"`
-	CategoryType   sql.NullString `json:"category_type"`
-	Price          string         `json:"price"`
-	BookType       string         `json:"book_type"`
-	TotalCapacity  int32          `json:"total_capacity"`
-	AvailableStock int32          `json:"available_stock"`
+	ID                  int32          `json:"id"`
+	EventID             int32          `json:"event_id"`
+	Name                string         `json:"name"`
+	CategoryType        sql.NullString `json:"category_type"`
+	Price               string         `json:"price"`
+	BookType            string         `json:"book_type"`
+	TotalCapacity       int32          `json:"total_capacity"`
+	AvailableStock      int32          `json:"available_stock"`
+	AccessibleReleaseAt sql.NullTime   `json:"accessible_release_at"`
 }
 
 type EventImage struct {
@@ -56,6 +70,93 @@ type EventImage struct {
 	CreatedAt    sql.NullTime  `json:"created_at"`
 }
 
+type EventSectionCategory struct {
+	EventID         int32 `json:"event_id"`
+	SectionID       int32 `json:"section_id"`
+	EventCategoryID int32 `json:"event_category_id"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Organization struct {
+	ID        int32        `json:"id"`
+	Name      string       `json:"name"`
+	Slug      string       `json:"slug"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type OrganizationMember struct {
+	UserID         int32        `json:"user_id"`
+	OrganizationID int32        `json:"organization_id"`
+	CreatedAt      sql.NullTime `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID             int64           `json:"id"`
+	Topic          string          `json:"topic"`
+	MessageKey     string          `json:"message_key"`
+	Payload        []byte          `json:"payload"`
+	Headers        json.RawMessage `json:"headers"`
+	Attempts       int32           `json:"attempts"`
+	LastError      sql.NullString  `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	PublishedAt    sql.NullTime    `json:"published_at"`
+	DeadLetteredAt sql.NullTime    `json:"dead_lettered_at"`
+}
+
+type PasswordResetToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type Row struct {
+	ID           int32  `json:"id"`
+	SectionID    int32  `json:"section_id"`
+	Label        string `json:"label"`
+	DisplayOrder int32  `json:"display_order"`
+}
+
+type Seat struct {
+	ID               int32         `json:"id"`
+	RowID            int32         `json:"row_id"`
+	Label            string        `json:"label"`
+	PosX             float64       `json:"pos_x"`
+	PosY             float64       `json:"pos_y"`
+	IsAccessible     bool          `json:"is_accessible"`
+	IsObstructedView bool          `json:"is_obstructed_view"`
+	CompanionSeatID  sql.NullInt32 `json:"companion_seat_id"`
+}
+
+type Section struct {
+	ID      int32   `json:"id"`
+	VenueID int32   `json:"venue_id"`
+	Name    string  `json:"name"`
+	Code    string  `json:"code"`
+	PosX    float64 `json:"pos_x"`
+	PosY    float64 `json:"pos_y"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+}
+
+type StockMovement struct {
+	ID              int64          `json:"id"`
+	EventCategoryID int32          `json:"event_category_id"`
+	Delta           int32          `json:"delta"`
+	Reason          string         `json:"reason"`
+	Reference       sql.NullString `json:"reference"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
 type Ticket struct {
 	ID              int32          `json:"id"`
 	EventCategoryID int32          `json:"event_category_id"`
@@ -63,4 +164,38 @@ type Ticket struct {
 	Status          sql.NullString `json:"status"`
 	ReservedUntil   sql.NullTime   `json:"reserved_until"`
 	Version         sql.NullInt32  `json:"version"`
+	SeatID          sql.NullInt32  `json:"seat_id"`
+}
+
+type User struct {
+	ID              int32          `json:"id"`
+	Email           string         `json:"email"`
+	PasswordHash    sql.NullString `json:"password_hash"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	Role            string         `json:"role"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+}
+
+type UserIdentity struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	Provider  string       `json:"provider"`
+	Subject   string       `json:"subject"`
+	Email     string       `json:"email"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type UserMfa struct {
+	UserID       int32        `json:"user_id"`
+	Secret       string       `json:"secret"`
+	LastUsedStep int64        `json:"last_used_step"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
+
+type Venue struct {
+	ID        int32        `json:"id"`
+	Name      string       `json:"name"`
+	Address   string       `json:"address"`
+	CreatedAt sql.NullTime `json:"created_at"`
 }
@@ -6,26 +6,58 @@ package ticketDB
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
 	BrowseEvents(ctx context.Context, arg BrowseEventsParams) ([]BrowseEventsRow, error)
-	DeleteEventImage(ctx context.Context, imageKey string) error
+	CountUnlinkedLayoutSeats(ctx context.Context, eventID int32) (int64, error)
+	DeleteEventImage(ctx context.Context, arg DeleteEventImageParams) error
 	ExpireReservedTickets(ctx context.Context) ([]ExpireReservedTicketsRow, error)
+	GenerateTicketsFromLayout(ctx context.Context, eventID int32) (int64, error)
 	GetAllStandingCategoriesAvailStock(ctx context.Context) ([]GetAllStandingCategoriesAvailStockRow, error)
 	GetEventCategories(ctx context.Context, eventID int32) ([]EventCategory, error)
 	GetEventCategoryById(ctx context.Context, id int32) (EventCategory, error)
+	GetEventCategoryOrganizer(ctx context.Context, id int32) (sql.NullInt32, error)
 	GetEventDetails(ctx context.Context, id int32) (Event, error)
 	GetEventImages(ctx context.Context, eventID int32) ([]EventImage, error)
+	GetEventOrganizer(ctx context.Context, id int32) (sql.NullInt32, error)
+	GetEventSeatingChart(ctx context.Context, id int32) ([]GetEventSeatingChartRow, error)
+	GetPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	GetSeatHold(ctx context.Context, id int32) (GetSeatHoldRow, error)
+	GetStandingStockExpectations(ctx context.Context) ([]GetStandingStockExpectationsRow, error)
 	GetTicketSeatAndEventCat(ctx context.Context, arg GetTicketSeatAndEventCatParams) (Ticket, error)
+	GetVenueByID(ctx context.Context, id int32) (Venue, error)
+	GetVenueChart(ctx context.Context, venueID int32) ([]GetVenueChartRow, error)
 	InsertBooking(ctx context.Context, arg InsertBookingParams) (Booking, error)
 	InsertEvent(ctx context.Context, arg InsertEventParams) (Event, error)
 	InsertEventCategory(ctx context.Context, arg InsertEventCategoryParams) (EventCategory, error)
 	InsertEventImage(ctx context.Context, arg InsertEventImageParams) (EventImage, error)
+	InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error
+	InsertRow(ctx context.Context, arg InsertRowParams) (Row, error)
+	InsertSeat(ctx context.Context, arg InsertSeatParams) (Seat, error)
+	InsertSection(ctx context.Context, arg InsertSectionParams) (Section, error)
+	InsertStockMovement(ctx context.Context, arg InsertStockMovementParams) error
 	InsertTicket(ctx context.Context, arg InsertTicketParams) (Ticket, error)
+	InsertVenue(ctx context.Context, arg InsertVenueParams) (Venue, error)
+	LockAccessibleSeatPair(ctx context.Context, eventCategoryID int32) (LockAccessibleSeatPairRow, error)
+	MarkOutboxEventDeadLettered(ctx context.Context, arg MarkOutboxEventDeadLetteredParams) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MarkTicketSold(ctx context.Context, id int32) error
+	ReleaseSoldTicket(ctx context.Context, arg ReleaseSoldTicketParams) (int64, error)
+	// accessible and companion seats are skipped until the category's accessible_release_at
 	ReserveAvailableSeat(ctx context.Context, eventCategoryID int32) (ReserveAvailableSeatRow, error)
+	SetCategoryAccessibleRelease(ctx context.Context, arg SetCategoryAccessibleReleaseParams) error
+	SetEventVenue(ctx context.Context, arg SetEventVenueParams) error
+	SetSeatCompanion(ctx context.Context, arg SetSeatCompanionParams) error
+	SyncCategoryCapacityFromLayout(ctx context.Context, eventID int32) error
 	UpdateEventCategoryAvailStock(ctx context.Context, arg UpdateEventCategoryAvailStockParams) error
+	UpdateEventCategoryCapacity(ctx context.Context, arg UpdateEventCategoryCapacityParams) error
 	UpdateTicketStatus(ctx context.Context, arg UpdateTicketStatusParams) (int32, error)
+	// mapping a section again to the same category is a no-op; mapping it to another
+	// category affects no row
+	UpsertEventSectionCategory(ctx context.Context, arg UpsertEventSectionCategoryParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
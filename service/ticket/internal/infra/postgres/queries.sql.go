@@ -8,6 +8,7 @@ package ticketDB
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -20,23 +21,25 @@ WHERE
     ($2::text = '' OR e.location ILIKE '%' || $2 || '%') AND
     ($3::timestamp = '0001-01-01 00:00:00' OR e.start_time >= $3) AND
     ($4::timestamp = '0001-01-01 00:00:00' OR e.start_time <= $4) AND
+    ($5::int = 0 OR e.organizer_id = $5) AND
     (
-        $5::timestamp = '0001-01-01 00:00:00' OR
-        e.start_time > $5::timestamp OR
-        (e.start_time = $5::timestamp AND e.id > $6::int)
+        $6::timestamp = '0001-01-01 00:00:00' OR
+        e.start_time > $6::timestamp OR
+        (e.start_time = $6::timestamp AND e.id > $7::int)
         )
 ORDER BY e.start_time ASC, e.id ASC
-    LIMIT $7
+    LIMIT $8
 `
 
 type BrowseEventsParams struct {
-	EventName  string    `json:"event_name"`
-	Location   string    `json:"location"`
-	StartDate  time.Time `json:"start_date"`
-	EndDate    time.Time `json:"end_date"`
-	CursorTime time.Time `json:"cursor_time"`
-	CursorID   int32     `json:"cursor_id"`
-	PageSize   int32     `json:"page_size"`
+	EventName   string    `json:"event_name"`
+	Location    string    `json:"location"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	OrganizerID int32     `json:"organizer_id"`
+	CursorTime  time.Time `json:"cursor_time"`
+	CursorID    int32     `json:"cursor_id"`
+	PageSize    int32     `json:"page_size"`
 }
 
 type BrowseEventsRow struct {
@@ -56,6 +59,7 @@ func (q *Queries) BrowseEvents(ctx context.Context, arg BrowseEventsParams) ([]B
 		arg.Location,
 		arg.StartDate,
 		arg.EndDate,
+		arg.OrganizerID,
 		arg.CursorTime,
 		arg.CursorID,
 		arg.PageSize,
@@ -90,27 +94,53 @@ func (q *Queries) BrowseEvents(ctx context.Context, arg BrowseEventsParams) ([]B
 	return items, nil
 }
 
+const countUnlinkedLayoutSeats = `-- name: CountUnlinkedLayoutSeats :one
+SELECT COUNT(*) FROM event_section_categories esc
+JOIN rows r ON r.section_id = esc.section_id
+JOIN seats s ON s.row_id = r.id
+WHERE esc.event_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM tickets t
+    WHERE t.event_category_id = esc.event_category_id AND t.seat_id = s.id
+)
+`
+
+func (q *Queries) CountUnlinkedLayoutSeats(ctx context.Context, eventID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnlinkedLayoutSeats, eventID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const deleteEventImage = `-- name: DeleteEventImage :exec
 DELETE FROM event_images
-WHERE image_key = $1
+WHERE image_key = $1 AND event_id = $2
 `
 
-func (q *Queries) DeleteEventImage(ctx context.Context, imageKey string) error {
-	_, err := q.db.ExecContext(ctx, deleteEventImage, imageKey)
+type DeleteEventImageParams struct {
+	ImageKey string `json:"image_key"`
+	EventID  int32  `json:"event_id"`
+}
+
+func (q *Queries) DeleteEventImage(ctx context.Context, arg DeleteEventImageParams) error {
+	_, err := q.db.ExecContext(ctx, deleteEventImage, arg.ImageKey, arg.EventID)
 	return err
 }
 
 const expireReservedTickets = `-- name: ExpireReservedTickets :many
-UPDATE tickets
+UPDATE tickets t
 SET status = 'AVAILABLE', reserved_until = NULL
-WHERE status = 'RESERVED' AND reserved_until < NOW()
-RETURNING id, event_category_id, seat_number
+FROM event_categories ec
+WHERE ec.id = t.event_category_id
+  AND t.status = 'RESERVED' AND t.reserved_until < NOW()
+RETURNING t.id, t.event_category_id, t.seat_number, ec.event_id
 `
 
 type ExpireReservedTicketsRow struct {
 	ID              int32          `json:"id"`
 	EventCategoryID int32          `json:"event_category_id"`
 	SeatNumber      sql.NullString `json:"seat_number"`
+	EventID         int32          `json:"event_id"`
 }
 
 func (q *Queries) ExpireReservedTickets(ctx context.Context) ([]ExpireReservedTicketsRow, error) {
@@ -122,7 +152,12 @@ func (q *Queries) ExpireReservedTickets(ctx context.Context) ([]ExpireReservedTi
 	var items []ExpireReservedTicketsRow
 	for rows.Next() {
 		var i ExpireReservedTicketsRow
-		if err := rows.Scan(&i.ID, &i.EventCategoryID, &i.SeatNumber); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventCategoryID,
+			&i.SeatNumber,
+			&i.EventID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -136,6 +171,27 @@ func (q *Queries) ExpireReservedTickets(ctx context.Context) ([]ExpireReservedTi
 	return items, nil
 }
 
+const generateTicketsFromLayout = `-- name: GenerateTicketsFromLayout :execrows
+INSERT INTO tickets (event_category_id, seat_number, status, seat_id)
+SELECT esc.event_category_id, sec.code || '-' || r.label || '-' || s.label, 'AVAILABLE', s.id
+FROM event_section_categories esc
+JOIN sections sec ON sec.id = esc.section_id
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+WHERE esc.event_id = $1
+ON CONFLICT (event_category_id, seat_number) DO UPDATE
+SET seat_id = EXCLUDED.seat_id
+WHERE tickets.seat_id IS NULL
+`
+
+func (q *Queries) GenerateTicketsFromLayout(ctx context.Context, eventID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, generateTicketsFromLayout, eventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getAllStandingCategoriesAvailStock = `-- name: GetAllStandingCategoriesAvailStock :many
 SELECT id, available_stock FROM event_categories
 WHERE category_type = 'STANDING'
@@ -170,7 +226,7 @@ func (q *Queries) GetAllStandingCategoriesAvailStock(ctx context.Context) ([]Get
 }
 
 const getEventCategories = `-- name: GetEventCategories :many
-SELECT id, event_id, name, category_type, price, book_type, total_capacity, available_stock FROM event_categories
+SELECT id, event_id, name, category_type, price, book_type, total_capacity, available_stock, accessible_release_at FROM event_categories
 WHERE event_id = $1
 `
 
@@ -192,6 +248,7 @@ func (q *Queries) GetEventCategories(ctx context.Context, eventID int32) ([]Even
 			&i.BookType,
 			&i.TotalCapacity,
 			&i.AvailableStock,
+			&i.AccessibleReleaseAt,
 		); err != nil {
 			return nil, err
 		}
@@ -207,7 +264,7 @@ func (q *Queries) GetEventCategories(ctx context.Context, eventID int32) ([]Even
 }
 
 const getEventCategoryById = `-- name: GetEventCategoryById :one
-SELECT id, event_id, name, category_type, price, book_type, total_capacity, available_stock FROM event_categories
+SELECT id, event_id, name, category_type, price, book_type, total_capacity, available_stock, accessible_release_at FROM event_categories
 WHERE id = $1
 `
 
@@ -223,12 +280,27 @@ func (q *Queries) GetEventCategoryById(ctx context.Context, id int32) (EventCate
 		&i.BookType,
 		&i.TotalCapacity,
 		&i.AvailableStock,
+		&i.AccessibleReleaseAt,
 	)
 	return i, err
 }
 
+const getEventCategoryOrganizer = `-- name: GetEventCategoryOrganizer :one
+SELECT e.organizer_id
+FROM event_categories ec
+JOIN events e ON e.id = ec.event_id
+WHERE ec.id = $1
+`
+
+func (q *Queries) GetEventCategoryOrganizer(ctx context.Context, id int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getEventCategoryOrganizer, id)
+	var organizer_id sql.NullInt32
+	err := row.Scan(&organizer_id)
+	return organizer_id, err
+}
+
 const getEventDetails = `-- name: GetEventDetails :one
-SELECT id, name, description, location, start_time, end_time, created_at FROM events
+SELECT id, name, description, location, start_time, end_time, created_at, venue_id, created_by, organizer_id FROM events
 WHERE id = $1
 `
 
@@ -243,6 +315,9 @@ func (q *Queries) GetEventDetails(ctx context.Context, id int32) (Event, error)
 		&i.StartTime,
 		&i.EndTime,
 		&i.CreatedAt,
+		&i.VenueID,
+		&i.CreatedBy,
+		&i.OrganizerID,
 	)
 	return i, err
 }
@@ -283,8 +358,216 @@ func (q *Queries) GetEventImages(ctx context.Context, eventID int32) ([]EventIma
 	return items, nil
 }
 
+const getEventOrganizer = `-- name: GetEventOrganizer :one
+SELECT organizer_id FROM events
+WHERE id = $1
+`
+
+func (q *Queries) GetEventOrganizer(ctx context.Context, id int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getEventOrganizer, id)
+	var organizer_id sql.NullInt32
+	err := row.Scan(&organizer_id)
+	return organizer_id, err
+}
+
+const getEventSeatingChart = `-- name: GetEventSeatingChart :many
+SELECT sec.id AS section_id, sec.name AS section_name, sec.code AS section_code,
+       sec.pos_x AS section_pos_x, sec.pos_y AS section_pos_y, sec.width AS section_width, sec.height AS section_height,
+       r.id AS row_id, r.label AS row_label, r.display_order AS row_display_order,
+       s.id AS seat_id, s.label AS seat_label, s.pos_x AS seat_pos_x, s.pos_y AS seat_pos_y,
+       s.is_accessible, s.is_obstructed_view, s.companion_seat_id,
+       esc.event_category_id, t.id AS ticket_id, t.status AS ticket_status
+FROM events e
+JOIN sections sec ON sec.venue_id = e.venue_id
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+LEFT JOIN event_section_categories esc ON esc.section_id = sec.id AND esc.event_id = e.id
+LEFT JOIN tickets t ON t.seat_id = s.id AND t.event_category_id = esc.event_category_id
+WHERE e.id = $1
+ORDER BY sec.id, r.display_order, r.id, s.id
+`
+
+type GetEventSeatingChartRow struct {
+	SectionID        int32          `json:"section_id"`
+	SectionName      string         `json:"section_name"`
+	SectionCode      string         `json:"section_code"`
+	SectionPosX      float64        `json:"section_pos_x"`
+	SectionPosY      float64        `json:"section_pos_y"`
+	SectionWidth     float64        `json:"section_width"`
+	SectionHeight    float64        `json:"section_height"`
+	RowID            int32          `json:"row_id"`
+	RowLabel         string         `json:"row_label"`
+	RowDisplayOrder  int32          `json:"row_display_order"`
+	SeatID           int32          `json:"seat_id"`
+	SeatLabel        string         `json:"seat_label"`
+	SeatPosX         float64        `json:"seat_pos_x"`
+	SeatPosY         float64        `json:"seat_pos_y"`
+	IsAccessible     bool           `json:"is_accessible"`
+	IsObstructedView bool           `json:"is_obstructed_view"`
+	CompanionSeatID  sql.NullInt32  `json:"companion_seat_id"`
+	EventCategoryID  sql.NullInt32  `json:"event_category_id"`
+	TicketID         sql.NullInt32  `json:"ticket_id"`
+	TicketStatus     sql.NullString `json:"ticket_status"`
+}
+
+func (q *Queries) GetEventSeatingChart(ctx context.Context, id int32) ([]GetEventSeatingChartRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventSeatingChart, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventSeatingChartRow
+	for rows.Next() {
+		var i GetEventSeatingChartRow
+		if err := rows.Scan(
+			&i.SectionID,
+			&i.SectionName,
+			&i.SectionCode,
+			&i.SectionPosX,
+			&i.SectionPosY,
+			&i.SectionWidth,
+			&i.SectionHeight,
+			&i.RowID,
+			&i.RowLabel,
+			&i.RowDisplayOrder,
+			&i.SeatID,
+			&i.SeatLabel,
+			&i.SeatPosX,
+			&i.SeatPosY,
+			&i.IsAccessible,
+			&i.IsObstructedView,
+			&i.CompanionSeatID,
+			&i.EventCategoryID,
+			&i.TicketID,
+			&i.TicketStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingOutboxEvents = `-- name: GetPendingOutboxEvents :many
+SELECT id, topic, message_key, payload, headers, attempts, last_error, created_at, published_at, dead_lettered_at FROM outbox_events
+WHERE published_at IS NULL AND dead_lettered_at IS NULL
+ORDER BY id
+LIMIT $1
+`
+
+func (q *Queries) GetPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingOutboxEvents, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboxEvent
+	for rows.Next() {
+		var i OutboxEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.MessageKey,
+			&i.Payload,
+			&i.Headers,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.PublishedAt,
+			&i.DeadLetteredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSeatHold = `-- name: GetSeatHold :one
+SELECT s.is_accessible,
+       EXISTS (SELECT 1 FROM seats a WHERE a.companion_seat_id = s.id) AS is_companion,
+       COALESCE(ec.accessible_release_at <= NOW(), FALSE)::boolean AS is_released
+FROM tickets t
+JOIN event_categories ec ON ec.id = t.event_category_id
+JOIN seats s ON s.id = t.seat_id
+WHERE t.id = $1
+`
+
+type GetSeatHoldRow struct {
+	IsAccessible bool `json:"is_accessible"`
+	IsCompanion  bool `json:"is_companion"`
+	IsReleased   bool `json:"is_released"`
+}
+
+func (q *Queries) GetSeatHold(ctx context.Context, id int32) (GetSeatHoldRow, error) {
+	row := q.db.QueryRowContext(ctx, getSeatHold, id)
+	var i GetSeatHoldRow
+	err := row.Scan(&i.IsAccessible, &i.IsCompanion, &i.IsReleased)
+	return i, err
+}
+
+const getStandingStockExpectations = `-- name: GetStandingStockExpectations :many
+SELECT ec.id, ec.total_capacity, ec.available_stock,
+       COALESCE(b.confirmed, 0)::int AS confirmed
+FROM event_categories ec
+LEFT JOIN (
+    SELECT event_category_id, COUNT(*) AS confirmed
+    FROM bookings
+    WHERE ticket_id IS NULL AND status = 'CONFIRMED'
+    GROUP BY event_category_id
+          ) b ON b.event_category_id = ec.id
+WHERE ec.category_type = 'STANDING'
+`
+
+type GetStandingStockExpectationsRow struct {
+	ID             int32 `json:"id"`
+	TotalCapacity  int32 `json:"total_capacity"`
+	AvailableStock int32 `json:"available_stock"`
+	Confirmed      int32 `json:"confirmed"`
+}
+
+func (q *Queries) GetStandingStockExpectations(ctx context.Context) ([]GetStandingStockExpectationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStandingStockExpectations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStandingStockExpectationsRow
+	for rows.Next() {
+		var i GetStandingStockExpectationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TotalCapacity,
+			&i.AvailableStock,
+			&i.Confirmed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTicketSeatAndEventCat = `-- name: GetTicketSeatAndEventCat :one
-SELECT id, event_category_id, seat_number, status, reserved_until, version FROM tickets
+SELECT id, event_category_id, seat_number, status, reserved_until, version, seat_id FROM tickets
 WHERE event_category_id = $1 AND seat_number = $2
 LIMIT 1
 `
@@ -304,10 +587,102 @@ func (q *Queries) GetTicketSeatAndEventCat(ctx context.Context, arg GetTicketSea
 		&i.Status,
 		&i.ReservedUntil,
 		&i.Version,
+		&i.SeatID,
 	)
 	return i, err
 }
 
+const getVenueByID = `-- name: GetVenueByID :one
+SELECT id, name, address, created_at FROM venues
+WHERE id = $1
+`
+
+func (q *Queries) GetVenueByID(ctx context.Context, id int32) (Venue, error) {
+	row := q.db.QueryRowContext(ctx, getVenueByID, id)
+	var i Venue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getVenueChart = `-- name: GetVenueChart :many
+SELECT sec.id AS section_id, sec.name AS section_name, sec.code AS section_code,
+       sec.pos_x AS section_pos_x, sec.pos_y AS section_pos_y, sec.width AS section_width, sec.height AS section_height,
+       r.id AS row_id, r.label AS row_label, r.display_order AS row_display_order,
+       s.id AS seat_id, s.label AS seat_label, s.pos_x AS seat_pos_x, s.pos_y AS seat_pos_y,
+       s.is_accessible, s.is_obstructed_view, s.companion_seat_id
+FROM sections sec
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+WHERE sec.venue_id = $1
+ORDER BY sec.id, r.display_order, r.id, s.id
+`
+
+type GetVenueChartRow struct {
+	SectionID        int32         `json:"section_id"`
+	SectionName      string        `json:"section_name"`
+	SectionCode      string        `json:"section_code"`
+	SectionPosX      float64       `json:"section_pos_x"`
+	SectionPosY      float64       `json:"section_pos_y"`
+	SectionWidth     float64       `json:"section_width"`
+	SectionHeight    float64       `json:"section_height"`
+	RowID            int32         `json:"row_id"`
+	RowLabel         string        `json:"row_label"`
+	RowDisplayOrder  int32         `json:"row_display_order"`
+	SeatID           int32         `json:"seat_id"`
+	SeatLabel        string        `json:"seat_label"`
+	SeatPosX         float64       `json:"seat_pos_x"`
+	SeatPosY         float64       `json:"seat_pos_y"`
+	IsAccessible     bool          `json:"is_accessible"`
+	IsObstructedView bool          `json:"is_obstructed_view"`
+	CompanionSeatID  sql.NullInt32 `json:"companion_seat_id"`
+}
+
+func (q *Queries) GetVenueChart(ctx context.Context, venueID int32) ([]GetVenueChartRow, error) {
+	rows, err := q.db.QueryContext(ctx, getVenueChart, venueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetVenueChartRow
+	for rows.Next() {
+		var i GetVenueChartRow
+		if err := rows.Scan(
+			&i.SectionID,
+			&i.SectionName,
+			&i.SectionCode,
+			&i.SectionPosX,
+			&i.SectionPosY,
+			&i.SectionWidth,
+			&i.SectionHeight,
+			&i.RowID,
+			&i.RowLabel,
+			&i.RowDisplayOrder,
+			&i.SeatID,
+			&i.SeatLabel,
+			&i.SeatPosX,
+			&i.SeatPosY,
+			&i.IsAccessible,
+			&i.IsObstructedView,
+			&i.CompanionSeatID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertBooking = `-- name: InsertBooking :one
 INSERT INTO bookings (ticket_id, status)
 VALUES ($1, $2)
@@ -336,9 +711,9 @@ func (q *Queries) InsertBooking(ctx context.Context, arg InsertBookingParams) (B
 }
 
 const insertEvent = `-- name: InsertEvent :one
-INSERT INTO events (name, description, location, start_time, end_time)
-VALUES ($1, $2, $3, $4, $5)
-    RETURNING id, name, description, location, start_time, end_time, created_at
+INSERT INTO events (name, description, location, start_time, end_time, created_by, organizer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id, name, description, location, start_time, end_time, created_at, venue_id, created_by, organizer_id
 `
 
 type InsertEventParams struct {
@@ -347,6 +722,8 @@ type InsertEventParams struct {
 	Location    string         `json:"location"`
 	StartTime   time.Time      `json:"start_time"`
 	EndTime     time.Time      `json:"end_time"`
+	CreatedBy   sql.NullInt32  `json:"created_by"`
+	OrganizerID sql.NullInt32  `json:"organizer_id"`
 }
 
 func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event, error) {
@@ -356,6 +733,8 @@ func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event
 		arg.Location,
 		arg.StartTime,
 		arg.EndTime,
+		arg.CreatedBy,
+		arg.OrganizerID,
 	)
 	var i Event
 	err := row.Scan(
@@ -366,6 +745,9 @@ func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event
 		&i.StartTime,
 		&i.EndTime,
 		&i.CreatedAt,
+		&i.VenueID,
+		&i.CreatedBy,
+		&i.OrganizerID,
 	)
 	return i, err
 }
@@ -373,7 +755,7 @@ func (q *Queries) InsertEvent(ctx context.Context, arg InsertEventParams) (Event
 const insertEventCategory = `-- name: InsertEventCategory :one
 INSERT INTO event_categories (event_id, name, category_type, price, book_type, total_capacity, available_stock)
 VALUES ($1, $2, $3, $4, $5, $6, $7)
-    RETURNING id, event_id, name, category_type, price, book_type, total_capacity, available_stock
+    RETURNING id, event_id, name, category_type, price, book_type, total_capacity, available_stock, accessible_release_at
 `
 
 type InsertEventCategoryParams struct {
@@ -406,6 +788,7 @@ func (q *Queries) InsertEventCategory(ctx context.Context, arg InsertEventCatego
 		&i.BookType,
 		&i.TotalCapacity,
 		&i.AvailableStock,
+		&i.AccessibleReleaseAt,
 	)
 	return i, err
 }
@@ -442,10 +825,157 @@ func (q *Queries) InsertEventImage(ctx context.Context, arg InsertEventImagePara
 	return i, err
 }
 
+const insertOutboxEvent = `-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events (topic, message_key, payload, headers)
+VALUES ($1, $2, $3, $4)
+`
+
+type InsertOutboxEventParams struct {
+	Topic      string          `json:"topic"`
+	MessageKey string          `json:"message_key"`
+	Payload    []byte          `json:"payload"`
+	Headers    json.RawMessage `json:"headers"`
+}
+
+func (q *Queries) InsertOutboxEvent(ctx context.Context, arg InsertOutboxEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertOutboxEvent,
+		arg.Topic,
+		arg.MessageKey,
+		arg.Payload,
+		arg.Headers,
+	)
+	return err
+}
+
+const insertRow = `-- name: InsertRow :one
+INSERT INTO rows (section_id, label, display_order)
+VALUES ($1, $2, $3)
+    RETURNING id, section_id, label, display_order
+`
+
+type InsertRowParams struct {
+	SectionID    int32  `json:"section_id"`
+	Label        string `json:"label"`
+	DisplayOrder int32  `json:"display_order"`
+}
+
+func (q *Queries) InsertRow(ctx context.Context, arg InsertRowParams) (Row, error) {
+	row := q.db.QueryRowContext(ctx, insertRow, arg.SectionID, arg.Label, arg.DisplayOrder)
+	var i Row
+	err := row.Scan(
+		&i.ID,
+		&i.SectionID,
+		&i.Label,
+		&i.DisplayOrder,
+	)
+	return i, err
+}
+
+const insertSeat = `-- name: InsertSeat :one
+INSERT INTO seats (row_id, label, pos_x, pos_y, is_accessible, is_obstructed_view)
+VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, row_id, label, pos_x, pos_y, is_accessible, is_obstructed_view, companion_seat_id
+`
+
+type InsertSeatParams struct {
+	RowID            int32         `json:"row_id"`
+	Label            string        `json:"label"`
+	PosX             float64       `json:"pos_x"`
+	PosY             float64       `json:"pos_y"`
+	IsAccessible     bool          `json:"is_accessible"`
+	IsObstructedView bool          `json:"is_obstructed_view"`
+	CompanionSeatID  sql.NullInt32 `json:"companion_seat_id"`
+}
+
+func (q *Queries) InsertSeat(ctx context.Context, arg InsertSeatParams) (Seat, error) {
+	row := q.db.QueryRowContext(ctx, insertSeat,
+		arg.RowID,
+		arg.Label,
+		arg.PosX,
+		arg.PosY,
+		arg.IsAccessible,
+		arg.IsObstructedView,
+	)
+	var i Seat
+	err := row.Scan(
+		&i.ID,
+		&i.RowID,
+		&i.Label,
+		&i.PosX,
+		&i.PosY,
+		&i.IsAccessible,
+		&i.IsObstructedView,
+		&i.CompanionSeatID,
+	)
+	return i, err
+}
+
+const insertSection = `-- name: InsertSection :one
+INSERT INTO sections (venue_id, name, code, pos_x, pos_y, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id, venue_id, name, code, pos_x, pos_y, width, height
+`
+
+type InsertSectionParams struct {
+	VenueID int32   `json:"venue_id"`
+	Name    string  `json:"name"`
+	Code    string  `json:"code"`
+	PosX    float64 `json:"pos_x"`
+	PosY    float64 `json:"pos_y"`
+	Width   float64 `json:"width"`
+	Height  float64 `json:"height"`
+}
+
+func (q *Queries) InsertSection(ctx context.Context, arg InsertSectionParams) (Section, error) {
+	row := q.db.QueryRowContext(ctx, insertSection,
+		arg.VenueID,
+		arg.Name,
+		arg.Code,
+		arg.PosX,
+		arg.PosY,
+		arg.Width,
+		arg.Height,
+	)
+	var i Section
+	err := row.Scan(
+		&i.ID,
+		&i.VenueID,
+		&i.Name,
+		&i.Code,
+		&i.PosX,
+		&i.PosY,
+		&i.Width,
+		&i.Height,
+	)
+	return i, err
+}
+
+const insertStockMovement = `-- name: InsertStockMovement :exec
+INSERT INTO stock_movements (event_category_id, delta, reason, reference)
+VALUES ($1, $2, $3, $4)
+`
+
+type InsertStockMovementParams struct {
+	EventCategoryID int32          `json:"event_category_id"`
+	Delta           int32          `json:"delta"`
+	Reason          string         `json:"reason"`
+	Reference       sql.NullString `json:"reference"`
+}
+
+func (q *Queries) InsertStockMovement(ctx context.Context, arg InsertStockMovementParams) error {
+	_, err := q.db.ExecContext(ctx, insertStockMovement,
+		arg.EventCategoryID,
+		arg.Delta,
+		arg.Reason,
+		arg.Reference,
+	)
+	return err
+}
+
 const insertTicket = `-- name: InsertTicket :one
 INSERT INTO tickets (event_category_id, seat_number, status, reserved_until)
 VALUES ($1, $2, $3, $4)
-    RETURNING id, event_category_id, seat_number, status, reserved_until, version
+    RETURNING id, event_category_id, seat_number, status, reserved_until, version, seat_id
 `
 
 type InsertTicketParams struct {
@@ -470,21 +1000,158 @@ func (q *Queries) InsertTicket(ctx context.Context, arg InsertTicketParams) (Tic
 		&i.Status,
 		&i.ReservedUntil,
 		&i.Version,
+		&i.SeatID,
+	)
+	return i, err
+}
+
+const insertVenue = `-- name: InsertVenue :one
+INSERT INTO venues (name, address)
+VALUES ($1, $2)
+    RETURNING id, name, address, created_at
+`
+
+type InsertVenueParams struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+func (q *Queries) InsertVenue(ctx context.Context, arg InsertVenueParams) (Venue, error) {
+	row := q.db.QueryRowContext(ctx, insertVenue, arg.Name, arg.Address)
+	var i Venue
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Address,
+		&i.CreatedAt,
 	)
 	return i, err
 }
 
+const lockAccessibleSeatPair = `-- name: LockAccessibleSeatPair :one
+SELECT t.id AS ticket_id, t.seat_number, c.id AS companion_ticket_id, c.seat_number AS companion_seat_number
+FROM tickets t
+JOIN seats s ON s.id = t.seat_id
+JOIN tickets c ON c.seat_id = s.companion_seat_id AND c.event_category_id = t.event_category_id
+WHERE t.event_category_id = $1
+  AND s.is_accessible
+  AND t.status = 'AVAILABLE'
+  AND c.status = 'AVAILABLE'
+ORDER BY t.id
+LIMIT 1
+FOR UPDATE OF t, c SKIP LOCKED
+`
+
+type LockAccessibleSeatPairRow struct {
+	TicketID            int32          `json:"ticket_id"`
+	SeatNumber          sql.NullString `json:"seat_number"`
+	CompanionTicketID   int32          `json:"companion_ticket_id"`
+	CompanionSeatNumber sql.NullString `json:"companion_seat_number"`
+}
+
+func (q *Queries) LockAccessibleSeatPair(ctx context.Context, eventCategoryID int32) (LockAccessibleSeatPairRow, error) {
+	row := q.db.QueryRowContext(ctx, lockAccessibleSeatPair, eventCategoryID)
+	var i LockAccessibleSeatPairRow
+	err := row.Scan(
+		&i.TicketID,
+		&i.SeatNumber,
+		&i.CompanionTicketID,
+		&i.CompanionSeatNumber,
+	)
+	return i, err
+}
+
+const markOutboxEventDeadLettered = `-- name: MarkOutboxEventDeadLettered :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, dead_lettered_at = NOW()
+WHERE id = $1
+`
+
+type MarkOutboxEventDeadLetteredParams struct {
+	ID        int64          `json:"id"`
+	LastError sql.NullString `json:"last_error"`
+}
+
+func (q *Queries) MarkOutboxEventDeadLettered(ctx context.Context, arg MarkOutboxEventDeadLetteredParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventDeadLettered, arg.ID, arg.LastError)
+	return err
+}
+
+const markOutboxEventFailed = `-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1
+`
+
+type MarkOutboxEventFailedParams struct {
+	ID        int64          `json:"id"`
+	LastError sql.NullString `json:"last_error"`
+}
+
+func (q *Queries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventFailed, arg.ID, arg.LastError)
+	return err
+}
+
+const markOutboxEventPublished = `-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkOutboxEventPublished(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, markOutboxEventPublished, id)
+	return err
+}
+
+const markTicketSold = `-- name: MarkTicketSold :exec
+UPDATE tickets
+SET status = 'SOLD', reserved_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkTicketSold(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markTicketSold, id)
+	return err
+}
+
+const releaseSoldTicket = `-- name: ReleaseSoldTicket :execrows
+UPDATE tickets
+SET status = 'AVAILABLE'
+WHERE id = $1 AND event_category_id = $2 AND status = 'SOLD'
+`
+
+type ReleaseSoldTicketParams struct {
+	ID              int32 `json:"id"`
+	EventCategoryID int32 `json:"event_category_id"`
+}
+
+func (q *Queries) ReleaseSoldTicket(ctx context.Context, arg ReleaseSoldTicketParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, releaseSoldTicket, arg.ID, arg.EventCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const reserveAvailableSeat = `-- name: ReserveAvailableSeat :one
 UPDATE tickets
 SET status = 'SOLD'
 WHERE id = (
     SELECT t.id
     FROM tickets t
+    JOIN event_categories ec ON ec.id = t.event_category_id
+    LEFT JOIN seats s ON s.id = t.seat_id
     WHERE t.event_category_id = $1
       AND t.status = 'AVAILABLE'
+      AND (
+          s.id IS NULL OR
+          ec.accessible_release_at <= NOW() OR
+          (NOT s.is_accessible AND NOT EXISTS (SELECT 1 FROM seats a WHERE a.companion_seat_id = s.id))
+          )
     ORDER BY t.id
     LIMIT 1
-    FOR UPDATE SKIP LOCKED
+    FOR UPDATE OF t SKIP LOCKED
             )
 RETURNING id, seat_number
 `
@@ -494,6 +1161,7 @@ type ReserveAvailableSeatRow struct {
 	SeatNumber sql.NullString `json:"seat_number"`
 }
 
+// accessible and companion seats are skipped until the category's accessible_release_at
 func (q *Queries) ReserveAvailableSeat(ctx context.Context, eventCategoryID int32) (ReserveAvailableSeatRow, error) {
 	row := q.db.QueryRowContext(ctx, reserveAvailableSeat, eventCategoryID)
 	var i ReserveAvailableSeatRow
@@ -501,6 +1169,75 @@ func (q *Queries) ReserveAvailableSeat(ctx context.Context, eventCategoryID int3
 	return i, err
 }
 
+const setCategoryAccessibleRelease = `-- name: SetCategoryAccessibleRelease :exec
+UPDATE event_categories
+SET accessible_release_at = $1
+WHERE id = $2
+`
+
+type SetCategoryAccessibleReleaseParams struct {
+	AccessibleReleaseAt sql.NullTime `json:"accessible_release_at"`
+	ID                  int32        `json:"id"`
+}
+
+func (q *Queries) SetCategoryAccessibleRelease(ctx context.Context, arg SetCategoryAccessibleReleaseParams) error {
+	_, err := q.db.ExecContext(ctx, setCategoryAccessibleRelease, arg.AccessibleReleaseAt, arg.ID)
+	return err
+}
+
+const setEventVenue = `-- name: SetEventVenue :exec
+UPDATE events
+SET venue_id = $1
+WHERE id = $2
+`
+
+type SetEventVenueParams struct {
+	VenueID sql.NullInt32 `json:"venue_id"`
+	ID      int32         `json:"id"`
+}
+
+func (q *Queries) SetEventVenue(ctx context.Context, arg SetEventVenueParams) error {
+	_, err := q.db.ExecContext(ctx, setEventVenue, arg.VenueID, arg.ID)
+	return err
+}
+
+const setSeatCompanion = `-- name: SetSeatCompanion :exec
+UPDATE seats
+SET companion_seat_id = $1
+WHERE id = $2
+`
+
+type SetSeatCompanionParams struct {
+	CompanionSeatID sql.NullInt32 `json:"companion_seat_id"`
+	ID              int32         `json:"id"`
+}
+
+func (q *Queries) SetSeatCompanion(ctx context.Context, arg SetSeatCompanionParams) error {
+	_, err := q.db.ExecContext(ctx, setSeatCompanion, arg.CompanionSeatID, arg.ID)
+	return err
+}
+
+const syncCategoryCapacityFromLayout = `-- name: SyncCategoryCapacityFromLayout :exec
+UPDATE event_categories ec
+SET total_capacity = t.total, available_stock = t.available
+FROM (
+    SELECT event_category_id,
+           COUNT(*) AS total,
+           COUNT(*) FILTER (WHERE status = 'AVAILABLE') AS available
+    FROM tickets
+    WHERE event_category_id IN (
+        SELECT event_category_id FROM event_section_categories WHERE event_id = $1
+    )
+    GROUP BY event_category_id
+     ) t
+WHERE ec.id = t.event_category_id AND ec.event_id = $1
+`
+
+func (q *Queries) SyncCategoryCapacityFromLayout(ctx context.Context, eventID int32) error {
+	_, err := q.db.ExecContext(ctx, syncCategoryCapacityFromLayout, eventID)
+	return err
+}
+
 const updateEventCategoryAvailStock = `-- name: UpdateEventCategoryAvailStock :exec
 UPDATE event_categories
 SET available_stock = $1
@@ -517,6 +1254,23 @@ func (q *Queries) UpdateEventCategoryAvailStock(ctx context.Context, arg UpdateE
 	return err
 }
 
+const updateEventCategoryCapacity = `-- name: UpdateEventCategoryCapacity :exec
+UPDATE event_categories
+SET total_capacity = $1, available_stock = $2
+WHERE id = $3
+`
+
+type UpdateEventCategoryCapacityParams struct {
+	TotalCapacity  int32 `json:"total_capacity"`
+	AvailableStock int32 `json:"available_stock"`
+	ID             int32 `json:"id"`
+}
+
+func (q *Queries) UpdateEventCategoryCapacity(ctx context.Context, arg UpdateEventCategoryCapacityParams) error {
+	_, err := q.db.ExecContext(ctx, updateEventCategoryCapacity, arg.TotalCapacity, arg.AvailableStock, arg.ID)
+	return err
+}
+
 const updateTicketStatus = `-- name: UpdateTicketStatus :one
 UPDATE tickets
 SET status = $1, reserved_until = $2
@@ -542,3 +1296,27 @@ func (q *Queries) UpdateTicketStatus(ctx context.Context, arg UpdateTicketStatus
 	err := row.Scan(&id)
 	return id, err
 }
+
+const upsertEventSectionCategory = `-- name: UpsertEventSectionCategory :execrows
+INSERT INTO event_section_categories (event_id, section_id, event_category_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id, section_id) DO UPDATE
+SET event_category_id = EXCLUDED.event_category_id
+WHERE event_section_categories.event_category_id = EXCLUDED.event_category_id
+`
+
+type UpsertEventSectionCategoryParams struct {
+	EventID         int32 `json:"event_id"`
+	SectionID       int32 `json:"section_id"`
+	EventCategoryID int32 `json:"event_category_id"`
+}
+
+// mapping a section again to the same category is a no-op; mapping it to another
+// category affects no row
+func (q *Queries) UpsertEventSectionCategory(ctx context.Context, arg UpsertEventSectionCategoryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, upsertEventSectionCategory, arg.EventID, arg.SectionID, arg.EventCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
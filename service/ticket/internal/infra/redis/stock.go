@@ -4,11 +4,75 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+var (
+	// ErrStockMissing means the counter key does not exist, e.g. after Redis was flushed.
+	ErrStockMissing = errors.New("stock counter missing")
+	// ErrStockInsufficient means the counter holds less than the requested quantity.
+	ErrStockInsufficient = errors.New("stock not sufficient")
+)
+
+// holdExpiryKey is a sorted set of "<eventCatID>:<reservationID>" scored by the
+// unix time the hold expires at, shared by all categories.
+const holdExpiryKey = "stock:holds:expiry"
+
+// reserveScript checks and decrements the counter and records the hold in one step, so
+// the counter never goes negative and a crash cannot separate the two writes.
+// Reserving the same reservation ID twice is a no-op.
+var reserveScript = redis.NewScript(`
+local stock = redis.call('GET', KEYS[1])
+if not stock then
+	return -2
+end
+if redis.call('HEXISTS', KEYS[2], ARGV[1]) == 1 then
+	return tonumber(stock)
+end
+local qty = tonumber(ARGV[2])
+if tonumber(stock) < qty then
+	return -1
+end
+local remaining = redis.call('DECRBY', KEYS[1], qty)
+redis.call('HSET', KEYS[2], ARGV[1], qty)
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[4])
+return remaining
+`)
+
+// releaseScript drops a hold and gives its quantity back to the counter.
+var releaseScript = redis.NewScript(`
+local qty = redis.call('HGET', KEYS[2], ARGV[1])
+if not qty then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[2])
+redis.call('INCRBY', KEYS[1], qty)
+return tonumber(qty)
+`)
+
+// commitScript drops a hold without touching the counter: the stock is sold.
+var commitScript = redis.NewScript(`
+local qty = redis.call('HGET', KEYS[1], ARGV[1])
+if not qty then
+	return 0
+end
+redis.call('HDEL', KEYS[1], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[2])
+return tonumber(qty)
+`)
+
+// StockHold is a quantity taken from a category's counter under a reservation ID.
+type StockHold struct {
+	EventCatID    int32
+	ReservationID string
+	Qty           int64
+}
+
 type StockCounter struct {
 	client *redis.Client
 }
@@ -21,6 +85,14 @@ func stockKey(evntID int32) string {
 	return fmt.Sprintf("stock:%d", evntID)
 }
 
+func holdsKey(eventCatID int32) string {
+	return fmt.Sprintf("stock:holds:%d", eventCatID)
+}
+
+func holdMember(eventCatID int32, reservationID string) string {
+	return fmt.Sprintf("%d:%s", eventCatID, reservationID)
+}
+
 func (s *StockCounter) Seed(ctx context.Context, eventID int32, stock int64) error {
 	key := stockKey(eventID)
 	err := s.client.SetArgs(ctx, key, stock, redis.SetArgs{
@@ -37,38 +109,113 @@ func (s *StockCounter) Seed(ctx context.Context, eventID int32, stock int64) err
 	return nil
 }
 
-func (s *StockCounter) Decrement(ctx context.Context, eventID int32, val int64) error {
+func (s *StockCounter) Get(ctx context.Context, eventID int32) (int64, error) {
+	key := stockKey(eventID)
+	stock, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrStockMissing
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get stock: %w", err)
+	}
+	return stock, nil
+}
+
+// Set overwrites the counter. Only the reconciliation job should call it.
+func (s *StockCounter) Set(ctx context.Context, eventID int32, stock int64) error {
 	key := stockKey(eventID)
-	remaining, err := s.client.DecrBy(ctx, key, val).Result()
+	if err := s.client.Set(ctx, key, stock, 0).Err(); err != nil {
+		return fmt.Errorf("set stock: %w", err)
+	}
+	return nil
+}
 
+// Reserve takes qty from the counter and holds it under reservationID until expiresAt.
+// It returns the remaining stock.
+func (s *StockCounter) Reserve(ctx context.Context, eventCatID int32, reservationID string, qty int64, expiresAt time.Time) (int64, error) {
+	keys := []string{stockKey(eventCatID), holdsKey(eventCatID), holdExpiryKey}
+	remaining, err := reserveScript.Run(ctx, s.client, keys,
+		reservationID, qty, expiresAt.Unix(), holdMember(eventCatID, reservationID)).Int64()
 	if err != nil {
-		log.Printf("failed to decrement stock for event %d: %v\n", eventID, err)
-		return fmt.Errorf("decrement stock (%d): %w", remaining, err)
+		return 0, fmt.Errorf("reserve stock: %w", err)
 	}
 
-	if remaining < 0 {
-		s.client.IncrBy(ctx, key, val)
-		log.Printf("stock insufficient for event %d, rolling back decrement. Remaining stock: %d\n", eventID, remaining)
-		return fmt.Errorf("stock not sufficient: (%d)", remaining)
+	switch remaining {
+	case -2:
+		return 0, ErrStockMissing
+	case -1:
+		return 0, ErrStockInsufficient
 	}
+	return remaining, nil
+}
 
-	return nil
+// Release returns a held quantity to the counter. It returns 0 when the hold no longer
+// exists, e.g. because it already expired or was committed.
+func (s *StockCounter) Release(ctx context.Context, eventCatID int32, reservationID string) (int64, error) {
+	keys := []string{stockKey(eventCatID), holdsKey(eventCatID), holdExpiryKey}
+	qty, err := releaseScript.Run(ctx, s.client, keys, reservationID, holdMember(eventCatID, reservationID)).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("release stock: %w", err)
+	}
+	return qty, nil
 }
 
-func (s *StockCounter) Increment(ctx context.Context, eventID int32, val int64) error {
-	key := stockKey(eventID)
-	_, err := s.client.IncrBy(ctx, key, val).Result()
+// Commit turns a hold into a sale so it no longer expires. It returns 0 when the hold
+// no longer exists.
+func (s *StockCounter) Commit(ctx context.Context, eventCatID int32, reservationID string) (int64, error) {
+	keys := []string{holdsKey(eventCatID), holdExpiryKey}
+	qty, err := commitScript.Run(ctx, s.client, keys, reservationID, holdMember(eventCatID, reservationID)).Int64()
 	if err != nil {
-		return fmt.Errorf("increment stock: %w", err)
+		return 0, fmt.Errorf("commit stock: %w", err)
 	}
-	return nil
+	return qty, nil
 }
 
-func (s *StockCounter) Get(ctx context.Context, eventID int32) (int64, error) {
-	key := stockKey(eventID)
-	stock, err := s.client.Get(ctx, key).Int64()
+// Held sums the quantity of all open holds on a category.
+func (s *StockCounter) Held(ctx context.Context, eventCatID int32) (int64, error) {
+	vals, err := s.client.HVals(ctx, holdsKey(eventCatID)).Result()
 	if err != nil {
-		return 0, fmt.Errorf("get stock: %w", err)
+		return 0, fmt.Errorf("get held stock: %w", err)
 	}
-	return stock, nil
+
+	var held int64
+	for _, v := range vals {
+		qty, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse held stock: %w", err)
+		}
+		held += qty
+	}
+	return held, nil
+}
+
+// ReleaseExpired releases up to limit holds whose expiry is before now.
+func (s *StockCounter) ReleaseExpired(ctx context.Context, now time.Time, limit int64) ([]StockHold, error) {
+	members, err := s.client.ZRangeByScore(ctx, holdExpiryKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now.Unix(), 10),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("list expired holds: %w", err)
+	}
+
+	var released []StockHold
+	for _, member := range members {
+		catPart, reservationID, ok := strings.Cut(member, ":")
+		eventCatID, parseErr := strconv.ParseInt(catPart, 10, 32)
+		if !ok || parseErr != nil {
+			s.client.ZRem(ctx, holdExpiryKey, member)
+			continue
+		}
+
+		qty, err := s.Release(ctx, int32(eventCatID), reservationID)
+		if err != nil {
+			return released, err
+		}
+		if qty > 0 {
+			released = append(released, StockHold{EventCatID: int32(eventCatID), ReservationID: reservationID, Qty: qty})
+		}
+	}
+	return released, nil
 }
@@ -1,10 +1,33 @@
 package model
 
 import (
+	"errors"
 	"io"
 	"time"
 )
 
+var (
+	ErrEventNotFound = errors.New("event not found")
+	// ErrForbidden is returned when a user without an organization tries to manage events.
+	ErrForbidden = errors.New("not allowed to manage events")
+
+	ErrVenueNotFound         = errors.New("venue not found")
+	ErrEventCategoryNotFound = errors.New("event category not found")
+	// ErrInvalidLayout is returned for venue charts and layout mappings that do not
+	// fit together.
+	ErrInvalidLayout = errors.New("invalid layout")
+	// ErrLayoutConflict is returned when applying a layout would contradict the
+	// section mappings or tickets the event already has.
+	ErrLayoutConflict = errors.New("layout conflicts with the event's existing layout")
+)
+
+// Actor is the authenticated user performing a request.
+type Actor struct {
+	UserID int32
+	Role   string
+	OrgID  int32
+}
+
 type EventData struct {
 	ID          int32     `json:"id"`
 	Name        string    `json:"name"`
@@ -13,6 +36,9 @@ type EventData struct {
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
 	ImageURL    string    `json:"image_url,omitempty"`
+	VenueID     int32     `json:"venue_id,omitempty"`
+	CreatedBy   int32     `json:"created_by,omitempty"`
+	OrganizerID int32     `json:"organizer_id,omitempty"`
 }
 
 type FileData struct {
@@ -30,13 +56,14 @@ type InsertTicketRequest struct {
 }
 
 type EventCategoryData struct {
-	EventID           int32   `json:"event_id"`
-	CategoryID        int32   `json:"category_id"`
-	CategoryType      string  `json:"category_type"`
-	Price             float64 `json:"price"`
-	BookType          string  `json:"book_type"`
-	TotalCapacity     int32   `json:"total_capacity"`
-	AvailableCapacity int32   `json:"available_capacity"`
+	EventID             int32      `json:"event_id"`
+	CategoryID          int32      `json:"category_id"`
+	CategoryType        string     `json:"category_type"`
+	Price               float64    `json:"price"`
+	BookType            string     `json:"book_type"`
+	TotalCapacity       int32      `json:"total_capacity"`
+	AvailableCapacity   int32      `json:"available_capacity"`
+	AccessibleReleaseAt *time.Time `json:"accessible_release_at,omitempty"`
 }
 
 type EventImageData struct {
@@ -58,12 +85,14 @@ type BrowseCursor struct {
 }
 
 type BrowseFilter struct {
-	EventName string        `json:"event_name"`
-	Location  string        `json:"location"`
-	StartDate time.Time     `json:"start_date"`
-	EndDate   time.Time     `json:"end_date"`
-	Cursor    *BrowseCursor `json:"cursor"`
-	Limit     int           `json:"limit"`
+	EventName string    `json:"event_name"`
+	Location  string    `json:"location"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	// OrganizerID limits the result to one organization's events when non-zero.
+	OrganizerID int32         `json:"organizer_id"`
+	Cursor      *BrowseCursor `json:"cursor"`
+	Limit       int           `json:"limit"`
 }
 
 type BrowseResult struct {
@@ -85,8 +114,128 @@ type EventCatStock struct {
 	Stock      int64
 }
 
+// StandingStock is what Postgres knows about a STANDING category: its capacity and
+// how many standing bookings are confirmed against it.
+type StandingStock struct {
+	EventCatID     int32
+	TotalCapacity  int64
+	AvailableStock int64
+	Confirmed      int64
+}
+
+// Expected is the stock the Redis counter should hold.
+func (s StandingStock) Expected() int64 {
+	return s.TotalCapacity - s.Confirmed
+}
+
+// Reasons of stock movements. A reservation takes stock as a HOLD, which is settled
+// either by RELEASE or EXPIRED giving it back, or by confirming it: the hold is
+// returned and the stock taken again as a BOOKING. BOOKING entries are therefore the
+// confirmed sales, and all deltas sum to the change of the Redis counter.
+const (
+	StockMovementHold    = "HOLD"
+	StockMovementBooking = "BOOKING"
+	StockMovementRelease = "RELEASE"
+	StockMovementRepair  = "REPAIR"
+	StockMovementExpired = "EXPIRED"
+)
+
+type StockMovement struct {
+	EventCatID int32
+	Delta      int64
+	Reason     string
+	Reference  string
+}
+
 type ExpiredSeat struct {
 	TicketID        int32
+	EventID         int32
 	EventCategoryID int32
 	SeatNumber      string
 }
+
+// OutboxMessage is an event stored alongside the change that produced it and
+// published to Kafka afterwards by the outbox relay.
+type OutboxMessage struct {
+	ID       int64
+	Topic    string
+	Key      string
+	Payload  []byte
+	Headers  map[string]string
+	Attempts int32
+}
+
+type VenueData struct {
+	ID      int32  `json:"id"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type SeatLayout struct {
+	ID               int32   `json:"id"`
+	Label            string  `json:"label"`
+	X                float64 `json:"x"`
+	Y                float64 `json:"y"`
+	IsAccessible     bool    `json:"is_accessible"`
+	IsObstructedView bool    `json:"is_obstructed_view"`
+	CompanionSeatID  int32   `json:"companion_seat_id,omitempty"`
+
+	// CompanionLabel links an accessible seat to a seat in the same row when a venue is created
+	CompanionLabel string `json:"companion_label,omitempty"`
+
+	// only filled when the chart is rendered for a specific event
+	EventCategoryID int32  `json:"event_category_id,omitempty"`
+	TicketID        int32  `json:"ticket_id,omitempty"`
+	Status          string `json:"status,omitempty"`
+}
+
+type RowLayout struct {
+	ID           int32        `json:"id"`
+	Label        string       `json:"label"`
+	DisplayOrder int32        `json:"display_order"`
+	Seats        []SeatLayout `json:"seats"`
+}
+
+type SectionLayout struct {
+	ID     int32       `json:"id"`
+	Name   string      `json:"name"`
+	Code   string      `json:"code"`
+	X      float64     `json:"x"`
+	Y      float64     `json:"y"`
+	Width  float64     `json:"width"`
+	Height float64     `json:"height"`
+	Rows   []RowLayout `json:"rows"`
+}
+
+type VenueChart struct {
+	Venue    VenueData       `json:"venue"`
+	Sections []SectionLayout `json:"sections"`
+}
+
+type SectionCategoryMapping struct {
+	SectionID       int32 `json:"section_id"`
+	EventCategoryID int32 `json:"event_category_id"`
+}
+
+type EventLayoutRequest struct {
+	EventID  int32                    `json:"event_id"`
+	VenueID  int32                    `json:"venue_id"`
+	Sections []SectionCategoryMapping `json:"sections"`
+
+	// AccessibleReleaseAt is when accessible and companion seats of the mapped
+	// categories open up for general sale; nil keeps them held indefinitely.
+	AccessibleReleaseAt *time.Time `json:"accessible_release_at"`
+}
+
+type AccessibleSeatPair struct {
+	TicketID            int32  `json:"ticket_id"`
+	SeatNumber          string `json:"seat_number"`
+	CompanionTicketID   int32  `json:"companion_ticket_id"`
+	CompanionSeatNumber string `json:"companion_seat_number"`
+}
+
+type EventSeatingChart struct {
+	EventID  int32           `json:"event_id"`
+	Venue    VenueData       `json:"venue"`
+	Sections []SectionLayout `json:"sections"`
+}
@@ -3,13 +3,15 @@ package model
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type TicketRepo interface {
 	WithTx(tx *sql.Tx) TicketRepo
+	ForOrganizer(organizerID int32) TicketRepo
 	InsertEvent(ctx context.Context, params EventData) (EventData, error)
 	InsertEventImage(ctx context.Context, params ImageKeyData) error
-	DeleteEventImage(ctx context.Context, key string) error
+	DeleteEventImage(ctx context.Context, eventID int32, key string) error
 	GetEventByID(ctx context.Context, id int32) (EventData, error)
 	GetEventCategory(ctx context.Context, eventID int32) ([]EventCategoryData, error)
 	GetEventImages(ctx context.Context, eventID int32) ([]EventImageData, error)
@@ -20,19 +22,53 @@ type TicketRepo interface {
 	ReserveAvailableSeat(ctx context.Context, eventCatID int32) (string, int32, error)
 	GetAllStandingEventCatStock(ctx context.Context) ([]EventCatStock, error)
 	UpdateEventCategoryStock(ctx context.Context, eventCatID int32, stock int64) error
+	UpdateEventCategoryCapacity(ctx context.Context, eventCatID int32, capacity, stock int64) error
 	ExpireReservedSeats(ctx context.Context) ([]ExpiredSeat, error)
+	InsertVenue(ctx context.Context, venue VenueData) (VenueData, error)
+	InsertSection(ctx context.Context, venueID int32, section SectionLayout) (int32, error)
+	InsertRow(ctx context.Context, sectionID int32, row RowLayout) (int32, error)
+	InsertSeat(ctx context.Context, rowID int32, seat SeatLayout) (int32, error)
+	GetVenueByID(ctx context.Context, id int32) (VenueData, error)
+	GetVenueChart(ctx context.Context, venueID int32) ([]SectionLayout, error)
+	SetEventVenue(ctx context.Context, eventID, venueID int32) error
+	MapSectionToCategory(ctx context.Context, eventID, sectionID, eventCatID int32) error
+	GenerateTicketsFromLayout(ctx context.Context, eventID int32) (int64, error)
+	SyncCategoryCapacityFromLayout(ctx context.Context, eventID int32) error
+	GetEventSeatingChart(ctx context.Context, eventID int32) ([]SectionLayout, error)
+	SetSeatCompanion(ctx context.Context, seatID, companionSeatID int32) error
+	SetCategoryAccessibleRelease(ctx context.Context, eventCatID int32, releaseAt *time.Time) error
+	IsSeatHeldForAccessibility(ctx context.Context, ticketID int32) (bool, error)
+	LockAccessibleSeatPair(ctx context.Context, eventCatID int32) (AccessibleSeatPair, error)
+	MarkTicketSold(ctx context.Context, ticketID int32) error
+	ReleaseSoldTicket(ctx context.Context, eventCatID, ticketID int32) error
+	GetStandingStockExpectations(ctx context.Context) ([]StandingStock, error)
+	InsertStockMovement(ctx context.Context, movement StockMovement) error
+	InsertOutboxMessage(ctx context.Context, msg OutboxMessage) error
+	GetPendingOutboxMessages(ctx context.Context, limit int32) ([]OutboxMessage, error)
+	MarkOutboxMessagePublished(ctx context.Context, id int64) error
+	MarkOutboxMessageFailed(ctx context.Context, id int64, reason string) error
+	MarkOutboxMessageDeadLettered(ctx context.Context, id int64, reason string) error
 }
 
 type TicketService interface {
-	CreateEvent(ctx context.Context, req InsertTicketRequest) (EventData, error)
-	DeleteImg(ctx context.Context, key string) error
-	UploadImg(ctx context.Context, eventID int32, files []FileData) error
+	CreateEvent(ctx context.Context, actor Actor, req InsertTicketRequest) (EventData, error)
+	DeleteImg(ctx context.Context, actor Actor, eventID int32, key string) error
+	UploadImg(ctx context.Context, actor Actor, eventID int32, files []FileData) error
 	GetEventDetail(ctx context.Context, id int32) (EventDetailsData, error)
 	BrowseEvents(ctx context.Context, filter BrowseFilter) (BrowseResult, error)
 	ValidateTicketBooking(ctx context.Context, seatId string, eventID, eventCategory int32) error
 	ReserveAvailableSeat(ctx context.Context, eventCatID int32) (string, int32, error)
 	ReserveTicket(ctx context.Context, seatNum string, eventCategoryID int32) (int32, error)
 	ReleaseTicket(ctx context.Context, seatNum string, eventCategoryID int32) error
+	CreateVenue(ctx context.Context, chart VenueChart) (VenueChart, error)
+	GetVenueChart(ctx context.Context, venueID int32) (VenueChart, error)
+	ApplyEventLayout(ctx context.Context, actor Actor, req EventLayoutRequest) (int64, error)
+	GetEventSeatingChart(ctx context.Context, eventID int32) (EventSeatingChart, error)
+	ReserveAccessibleSeatPair(ctx context.Context, eventCatID int32) (AccessibleSeatPair, error)
+	ReleaseAccessibleSeatPair(ctx context.Context, eventCatID, ticketID, companionTicketID int32) error
+	RecordStockMovement(ctx context.Context, movement StockMovement) error
+	ExpireReservedSeats(ctx context.Context) ([]ExpiredSeat, error)
+	BrowseOrganizerEvents(ctx context.Context, actor Actor, filter BrowseFilter) (BrowseResult, error)
 }
 
 type ImageKeyData struct {
@@ -14,12 +14,15 @@ func toModel(e ticketDB.Event) model.EventData {
 		Location:    e.Location,
 		StartTime:   e.StartTime,
 		EndTime:     e.EndTime,
+		VenueID:     e.VenueID.Int32,
+		CreatedBy:   e.CreatedBy.Int32,
+		OrganizerID: e.OrganizerID.Int32,
 	}
 }
 
 func toModelEventCategory(ec ticketDB.EventCategory) model.EventCategoryData {
 	price, _ := strconv.ParseFloat(ec.Price, 64)
-	return model.EventCategoryData{
+	data := model.EventCategoryData{
 		EventID:           ec.EventID,
 		CategoryID:        ec.ID,
 		CategoryType:      ec.CategoryType.String,
@@ -28,4 +31,88 @@ func toModelEventCategory(ec ticketDB.EventCategory) model.EventCategoryData {
 		TotalCapacity:     ec.TotalCapacity,
 		AvailableCapacity: ec.AvailableStock,
 	}
+	if ec.AccessibleReleaseAt.Valid {
+		data.AccessibleReleaseAt = &ec.AccessibleReleaseAt.Time
+	}
+	return data
+}
+
+// chartRow is the common shape of GetVenueChart and GetEventSeatingChart rows,
+// so both can be folded into the same section → row → seat tree.
+type chartRow struct {
+	section model.SectionLayout
+	row     model.RowLayout
+	seat    model.SeatLayout
+}
+
+func venueChartRow(r ticketDB.GetVenueChartRow) chartRow {
+	return chartRow{
+		section: model.SectionLayout{
+			ID:     r.SectionID,
+			Name:   r.SectionName,
+			Code:   r.SectionCode,
+			X:      r.SectionPosX,
+			Y:      r.SectionPosY,
+			Width:  r.SectionWidth,
+			Height: r.SectionHeight,
+		},
+		row: model.RowLayout{
+			ID:           r.RowID,
+			Label:        r.RowLabel,
+			DisplayOrder: r.RowDisplayOrder,
+		},
+		seat: model.SeatLayout{
+			ID:               r.SeatID,
+			Label:            r.SeatLabel,
+			X:                r.SeatPosX,
+			Y:                r.SeatPosY,
+			IsAccessible:     r.IsAccessible,
+			IsObstructedView: r.IsObstructedView,
+			CompanionSeatID:  r.CompanionSeatID.Int32,
+		},
+	}
+}
+
+func eventChartRow(r ticketDB.GetEventSeatingChartRow) chartRow {
+	row := venueChartRow(ticketDB.GetVenueChartRow{
+		SectionID:        r.SectionID,
+		SectionName:      r.SectionName,
+		SectionCode:      r.SectionCode,
+		SectionPosX:      r.SectionPosX,
+		SectionPosY:      r.SectionPosY,
+		SectionWidth:     r.SectionWidth,
+		SectionHeight:    r.SectionHeight,
+		RowID:            r.RowID,
+		RowLabel:         r.RowLabel,
+		RowDisplayOrder:  r.RowDisplayOrder,
+		SeatID:           r.SeatID,
+		SeatLabel:        r.SeatLabel,
+		SeatPosX:         r.SeatPosX,
+		SeatPosY:         r.SeatPosY,
+		IsAccessible:     r.IsAccessible,
+		IsObstructedView: r.IsObstructedView,
+		CompanionSeatID:  r.CompanionSeatID,
+	})
+	row.seat.EventCategoryID = r.EventCategoryID.Int32
+	row.seat.TicketID = r.TicketID.Int32
+	row.seat.Status = r.TicketStatus.String
+	return row
+}
+
+// toSectionLayouts relies on the queries ordering by section, then row.
+func toSectionLayouts(rows []chartRow) []model.SectionLayout {
+	var sections []model.SectionLayout
+	for _, r := range rows {
+		if len(sections) == 0 || sections[len(sections)-1].ID != r.section.ID {
+			sections = append(sections, r.section)
+		}
+		sec := &sections[len(sections)-1]
+
+		if len(sec.Rows) == 0 || sec.Rows[len(sec.Rows)-1].ID != r.row.ID {
+			sec.Rows = append(sec.Rows, r.row)
+		}
+		row := &sec.Rows[len(sec.Rows)-1]
+		row.Seats = append(row.Seats, r.seat)
+	}
+	return sections
 }
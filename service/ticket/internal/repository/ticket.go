@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	ticketDB "ticket-tix/service/ticket/internal/infra/postgres"
@@ -13,6 +14,8 @@ import (
 type ticketRepo struct {
 	db    *ticketDB.Queries
 	rawDB *sql.DB
+	// organizerID scopes event reads and writes to one organization; 0 is unscoped.
+	organizerID int32
 }
 
 func NewTicketRepo(db *sql.DB) model.TicketRepo {
@@ -24,12 +27,57 @@ func NewTicketRepo(db *sql.DB) model.TicketRepo {
 
 func (r *ticketRepo) WithTx(tx *sql.Tx) model.TicketRepo {
 	return &ticketRepo{
-		db:    r.db.WithTx(tx),
-		rawDB: r.rawDB,
+		db:          r.db.WithTx(tx),
+		rawDB:       r.rawDB,
+		organizerID: r.organizerID,
 	}
 }
 
+// ForOrganizer returns a repo that only reads and changes events of the organization.
+// Events of other organizations look like they do not exist.
+func (r *ticketRepo) ForOrganizer(organizerID int32) model.TicketRepo {
+	return &ticketRepo{
+		db:          r.db,
+		rawDB:       r.rawDB,
+		organizerID: organizerID,
+	}
+}
+
+// authorizeEvent fails with ErrEventNotFound when the repo is scoped to an
+// organization that does not own the event.
+func (r *ticketRepo) authorizeEvent(ctx context.Context, eventID int32) error {
+	if r.organizerID == 0 {
+		return nil
+	}
+	organizerID, err := r.db.GetEventOrganizer(ctx, eventID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("get event organizer: %w", err)
+	}
+	if err != nil || organizerID.Int32 != r.organizerID {
+		return model.ErrEventNotFound
+	}
+	return nil
+}
+
+// authorizeEventCategory is authorizeEvent for the event a category belongs to.
+func (r *ticketRepo) authorizeEventCategory(ctx context.Context, eventCatID int32) error {
+	if r.organizerID == 0 {
+		return nil
+	}
+	organizerID, err := r.db.GetEventCategoryOrganizer(ctx, eventCatID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("get event category organizer: %w", err)
+	}
+	if err != nil || organizerID.Int32 != r.organizerID {
+		return model.ErrEventNotFound
+	}
+	return nil
+}
+
 func (r *ticketRepo) InsertEvent(ctx context.Context, params model.EventData) (model.EventData, error) {
+	if r.organizerID != 0 {
+		params.OrganizerID = r.organizerID
+	}
 	event, err := r.db.InsertEvent(ctx, ticketDB.InsertEventParams{
 		Name: params.Name,
 		Description: sql.NullString{
@@ -39,6 +87,14 @@ func (r *ticketRepo) InsertEvent(ctx context.Context, params model.EventData) (m
 		Location:  params.Location,
 		StartTime: params.StartTime,
 		EndTime:   params.EndTime,
+		CreatedBy: sql.NullInt32{
+			Int32: params.CreatedBy,
+			Valid: params.CreatedBy != 0,
+		},
+		OrganizerID: sql.NullInt32{
+			Int32: params.OrganizerID,
+			Valid: params.OrganizerID != 0,
+		},
 	})
 	if err != nil {
 		return model.EventData{}, fmt.Errorf("insert event: %w", err)
@@ -48,6 +104,9 @@ func (r *ticketRepo) InsertEvent(ctx context.Context, params model.EventData) (m
 }
 
 func (r *ticketRepo) InsertEventImage(ctx context.Context, params model.ImageKeyData) error {
+	if err := r.authorizeEvent(ctx, params.EventID); err != nil {
+		return err
+	}
 	_, err := r.db.InsertEventImage(ctx, ticketDB.InsertEventImageParams{
 		EventID:  params.EventID,
 		ImageKey: params.Key,
@@ -66,8 +125,14 @@ func (r *ticketRepo) InsertEventImage(ctx context.Context, params model.ImageKey
 	return nil
 }
 
-func (r *ticketRepo) DeleteEventImage(ctx context.Context, key string) error {
-	err := r.db.DeleteEventImage(ctx, key)
+func (r *ticketRepo) DeleteEventImage(ctx context.Context, eventID int32, key string) error {
+	if err := r.authorizeEvent(ctx, eventID); err != nil {
+		return err
+	}
+	err := r.db.DeleteEventImage(ctx, ticketDB.DeleteEventImageParams{
+		ImageKey: key,
+		EventID:  eventID,
+	})
 	if err != nil {
 		return fmt.Errorf("delete event image: %w", err)
 	}
@@ -78,10 +143,13 @@ func (r *ticketRepo) GetEventByID(ctx context.Context, id int32) (model.EventDat
 	event, err := r.db.GetEventDetails(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return model.EventData{}, fmt.Errorf("event not found")
+			return model.EventData{}, model.ErrEventNotFound
 		}
 		return model.EventData{}, fmt.Errorf("failed get event by id: %w", err)
 	}
+	if r.organizerID != 0 && event.OrganizerID.Int32 != r.organizerID {
+		return model.EventData{}, model.ErrEventNotFound
+	}
 	return toModel(event), nil
 }
 
@@ -130,14 +198,19 @@ func (r *ticketRepo) BrowseEvents(ctx context.Context, filter model.BrowseFilter
 		cursorID = filter.Cursor.ID
 	}
 
+	if r.organizerID != 0 {
+		filter.OrganizerID = r.organizerID
+	}
+
 	rows, err := r.db.BrowseEvents(ctx, ticketDB.BrowseEventsParams{
-		EventName:  filter.EventName,
-		Location:   filter.Location,
-		StartDate:  filter.StartDate,
-		EndDate:    filter.EndDate,
-		CursorTime: cursorTime,
-		CursorID:   cursorID,
-		PageSize:   int32(filter.Limit),
+		EventName:   filter.EventName,
+		Location:    filter.Location,
+		StartDate:   filter.StartDate,
+		EndDate:     filter.EndDate,
+		OrganizerID: filter.OrganizerID,
+		CursorTime:  cursorTime,
+		CursorID:    cursorID,
+		PageSize:    int32(filter.Limit),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("browse events: %w", err)
@@ -178,7 +251,7 @@ func (r *ticketRepo) GetEventCategoryByID(ctx context.Context, eventCatID int32)
 	ec, err := r.db.GetEventCategoryById(ctx, eventCatID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return model.EventCategoryData{}, fmt.Errorf("event category not found")
+			return model.EventCategoryData{}, model.ErrEventCategoryNotFound
 		}
 		return model.EventCategoryData{}, fmt.Errorf("failed get event category by id: %w", err)
 	}
@@ -248,6 +321,18 @@ func (r *ticketRepo) UpdateEventCategoryStock(ctx context.Context, eventCatID in
 	return nil
 }
 
+func (r *ticketRepo) UpdateEventCategoryCapacity(ctx context.Context, eventCatID int32, capacity, stock int64) error {
+	err := r.db.UpdateEventCategoryCapacity(ctx, ticketDB.UpdateEventCategoryCapacityParams{
+		TotalCapacity:  int32(capacity),
+		AvailableStock: int32(stock),
+		ID:             eventCatID,
+	})
+	if err != nil {
+		return fmt.Errorf("update event category capacity: %w", err)
+	}
+	return nil
+}
+
 func (r *ticketRepo) ExpireReservedSeats(ctx context.Context) ([]model.ExpiredSeat, error) {
 	var res []model.ExpiredSeat
 	expiredSeat, err := r.db.ExpireReservedTickets(ctx)
@@ -258,6 +343,7 @@ func (r *ticketRepo) ExpireReservedSeats(ctx context.Context) ([]model.ExpiredSe
 	for _, seat := range expiredSeat {
 		res = append(res, model.ExpiredSeat{
 			TicketID:        seat.ID,
+			EventID:         seat.EventID,
 			EventCategoryID: seat.EventCategoryID,
 			SeatNumber:      seat.SeatNumber.String,
 		})
@@ -265,3 +351,105 @@ func (r *ticketRepo) ExpireReservedSeats(ctx context.Context) ([]model.ExpiredSe
 
 	return res, nil
 }
+
+func (r *ticketRepo) GetStandingStockExpectations(ctx context.Context) ([]model.StandingStock, error) {
+	rows, err := r.db.GetStandingStockExpectations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get standing stock expectations: %w", err)
+	}
+
+	res := make([]model.StandingStock, 0, len(rows))
+	for _, row := range rows {
+		res = append(res, model.StandingStock{
+			EventCatID:     row.ID,
+			TotalCapacity:  int64(row.TotalCapacity),
+			AvailableStock: int64(row.AvailableStock),
+			Confirmed:      int64(row.Confirmed),
+		})
+	}
+	return res, nil
+}
+
+func (r *ticketRepo) InsertStockMovement(ctx context.Context, movement model.StockMovement) error {
+	err := r.db.InsertStockMovement(ctx, ticketDB.InsertStockMovementParams{
+		EventCategoryID: movement.EventCatID,
+		Delta:           int32(movement.Delta),
+		Reason:          movement.Reason,
+		Reference:       sql.NullString{String: movement.Reference, Valid: movement.Reference != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("insert stock movement: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) InsertOutboxMessage(ctx context.Context, msg model.OutboxMessage) error {
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return fmt.Errorf("marshal outbox headers: %w", err)
+	}
+
+	err = r.db.InsertOutboxEvent(ctx, ticketDB.InsertOutboxEventParams{
+		Topic:      msg.Topic,
+		MessageKey: msg.Key,
+		Payload:    msg.Payload,
+		Headers:    headers,
+	})
+	if err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) GetPendingOutboxMessages(ctx context.Context, limit int32) ([]model.OutboxMessage, error) {
+	rows, err := r.db.GetPendingOutboxEvents(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get pending outbox events: %w", err)
+	}
+
+	res := make([]model.OutboxMessage, 0, len(rows))
+	for _, row := range rows {
+		var headers map[string]string
+		if err := json.Unmarshal(row.Headers, &headers); err != nil {
+			return nil, fmt.Errorf("unmarshal outbox headers %d: %w", row.ID, err)
+		}
+		res = append(res, model.OutboxMessage{
+			ID:       row.ID,
+			Topic:    row.Topic,
+			Key:      row.MessageKey,
+			Payload:  row.Payload,
+			Headers:  headers,
+			Attempts: row.Attempts,
+		})
+	}
+	return res, nil
+}
+
+func (r *ticketRepo) MarkOutboxMessagePublished(ctx context.Context, id int64) error {
+	if err := r.db.MarkOutboxEventPublished(ctx, id); err != nil {
+		return fmt.Errorf("mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) MarkOutboxMessageFailed(ctx context.Context, id int64, reason string) error {
+	err := r.db.MarkOutboxEventFailed(ctx, ticketDB.MarkOutboxEventFailedParams{
+		ID:        id,
+		LastError: sql.NullString{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) MarkOutboxMessageDeadLettered(ctx context.Context, id int64, reason string) error {
+	err := r.db.MarkOutboxEventDeadLettered(ctx, ticketDB.MarkOutboxEventDeadLetteredParams{
+		ID:        id,
+		LastError: sql.NullString{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("mark outbox event dead lettered: %w", err)
+	}
+	return nil
+}
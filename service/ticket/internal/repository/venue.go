@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	ticketDB "ticket-tix/service/ticket/internal/infra/postgres"
+	"ticket-tix/service/ticket/internal/model"
+	"time"
+)
+
+func (r *ticketRepo) InsertVenue(ctx context.Context, venue model.VenueData) (model.VenueData, error) {
+	v, err := r.db.InsertVenue(ctx, ticketDB.InsertVenueParams{
+		Name:    venue.Name,
+		Address: venue.Address,
+	})
+	if err != nil {
+		return model.VenueData{}, fmt.Errorf("insert venue: %w", err)
+	}
+	return model.VenueData{ID: v.ID, Name: v.Name, Address: v.Address}, nil
+}
+
+func (r *ticketRepo) InsertSection(ctx context.Context, venueID int32, section model.SectionLayout) (int32, error) {
+	sec, err := r.db.InsertSection(ctx, ticketDB.InsertSectionParams{
+		VenueID: venueID,
+		Name:    section.Name,
+		Code:    section.Code,
+		PosX:    section.X,
+		PosY:    section.Y,
+		Width:   section.Width,
+		Height:  section.Height,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("insert section: %w", err)
+	}
+	return sec.ID, nil
+}
+
+func (r *ticketRepo) InsertRow(ctx context.Context, sectionID int32, row model.RowLayout) (int32, error) {
+	rw, err := r.db.InsertRow(ctx, ticketDB.InsertRowParams{
+		SectionID:    sectionID,
+		Label:        row.Label,
+		DisplayOrder: row.DisplayOrder,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("insert row: %w", err)
+	}
+	return rw.ID, nil
+}
+
+func (r *ticketRepo) InsertSeat(ctx context.Context, rowID int32, seat model.SeatLayout) (int32, error) {
+	st, err := r.db.InsertSeat(ctx, ticketDB.InsertSeatParams{
+		RowID:            rowID,
+		Label:            seat.Label,
+		PosX:             seat.X,
+		PosY:             seat.Y,
+		IsAccessible:     seat.IsAccessible,
+		IsObstructedView: seat.IsObstructedView,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("insert seat: %w", err)
+	}
+	return st.ID, nil
+}
+
+func (r *ticketRepo) GetVenueByID(ctx context.Context, id int32) (model.VenueData, error) {
+	v, err := r.db.GetVenueByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.VenueData{}, model.ErrVenueNotFound
+		}
+		return model.VenueData{}, fmt.Errorf("failed get venue by id: %w", err)
+	}
+	return model.VenueData{ID: v.ID, Name: v.Name, Address: v.Address}, nil
+}
+
+func (r *ticketRepo) GetVenueChart(ctx context.Context, venueID int32) ([]model.SectionLayout, error) {
+	rows, err := r.db.GetVenueChart(ctx, venueID)
+	if err != nil {
+		return nil, fmt.Errorf("get venue chart: %w", err)
+	}
+
+	chart := make([]chartRow, 0, len(rows))
+	for _, row := range rows {
+		chart = append(chart, venueChartRow(row))
+	}
+	return toSectionLayouts(chart), nil
+}
+
+func (r *ticketRepo) SetEventVenue(ctx context.Context, eventID, venueID int32) error {
+	if err := r.authorizeEvent(ctx, eventID); err != nil {
+		return err
+	}
+	err := r.db.SetEventVenue(ctx, ticketDB.SetEventVenueParams{
+		VenueID: sql.NullInt32{Int32: venueID, Valid: venueID != 0},
+		ID:      eventID,
+	})
+	if err != nil {
+		return fmt.Errorf("set event venue: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) MapSectionToCategory(ctx context.Context, eventID, sectionID, eventCatID int32) error {
+	if err := r.authorizeEvent(ctx, eventID); err != nil {
+		return err
+	}
+	mapped, err := r.db.UpsertEventSectionCategory(ctx, ticketDB.UpsertEventSectionCategoryParams{
+		EventID:         eventID,
+		SectionID:       sectionID,
+		EventCategoryID: eventCatID,
+	})
+	if err != nil {
+		return fmt.Errorf("map section %d to category %d: %w", sectionID, eventCatID, err)
+	}
+	if mapped == 0 {
+		return fmt.Errorf("%w: section %d is mapped to another category", model.ErrLayoutConflict, sectionID)
+	}
+	return nil
+}
+
+func (r *ticketRepo) GenerateTicketsFromLayout(ctx context.Context, eventID int32) (int64, error) {
+	if err := r.authorizeEvent(ctx, eventID); err != nil {
+		return 0, err
+	}
+	count, err := r.db.GenerateTicketsFromLayout(ctx, eventID)
+	if err != nil {
+		return 0, fmt.Errorf("generate tickets from layout: %w", err)
+	}
+
+	// a ticket already holding a seat's number for another seat keeps that seat
+	// without a ticket
+	unlinked, err := r.db.CountUnlinkedLayoutSeats(ctx, eventID)
+	if err != nil {
+		return 0, fmt.Errorf("count unlinked layout seats: %w", err)
+	}
+	if unlinked > 0 {
+		return 0, fmt.Errorf("%w: %d seat(s) share a seat number with a ticket of another seat", model.ErrLayoutConflict, unlinked)
+	}
+	return count, nil
+}
+
+func (r *ticketRepo) SyncCategoryCapacityFromLayout(ctx context.Context, eventID int32) error {
+	if err := r.authorizeEvent(ctx, eventID); err != nil {
+		return err
+	}
+	if err := r.db.SyncCategoryCapacityFromLayout(ctx, eventID); err != nil {
+		return fmt.Errorf("sync category capacity: %w", err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) GetEventSeatingChart(ctx context.Context, eventID int32) ([]model.SectionLayout, error) {
+	rows, err := r.db.GetEventSeatingChart(ctx, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("get event seating chart: %w", err)
+	}
+
+	chart := make([]chartRow, 0, len(rows))
+	for _, row := range rows {
+		chart = append(chart, eventChartRow(row))
+	}
+	return toSectionLayouts(chart), nil
+}
+
+func (r *ticketRepo) SetSeatCompanion(ctx context.Context, seatID, companionSeatID int32) error {
+	err := r.db.SetSeatCompanion(ctx, ticketDB.SetSeatCompanionParams{
+		CompanionSeatID: sql.NullInt32{Int32: companionSeatID, Valid: companionSeatID != 0},
+		ID:              seatID,
+	})
+	if err != nil {
+		return fmt.Errorf("set companion of seat %d: %w", seatID, err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) SetCategoryAccessibleRelease(ctx context.Context, eventCatID int32, releaseAt *time.Time) error {
+	if err := r.authorizeEventCategory(ctx, eventCatID); err != nil {
+		return err
+	}
+	var release sql.NullTime
+	if releaseAt != nil {
+		release = sql.NullTime{Time: *releaseAt, Valid: true}
+	}
+
+	err := r.db.SetCategoryAccessibleRelease(ctx, ticketDB.SetCategoryAccessibleReleaseParams{
+		AccessibleReleaseAt: release,
+		ID:                  eventCatID,
+	})
+	if err != nil {
+		return fmt.Errorf("set accessible release of category %d: %w", eventCatID, err)
+	}
+	return nil
+}
+
+// IsSeatHeldForAccessibility reports whether the ticket is an accessible or companion
+// seat that has not been released for general sale yet. Tickets without a seat are never held.
+func (r *ticketRepo) IsSeatHeldForAccessibility(ctx context.Context, ticketID int32) (bool, error) {
+	hold, err := r.db.GetSeatHold(ctx, ticketID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("get seat hold: %w", err)
+	}
+	return (hold.IsAccessible || hold.IsCompanion) && !hold.IsReleased, nil
+}
+
+func (r *ticketRepo) LockAccessibleSeatPair(ctx context.Context, eventCatID int32) (model.AccessibleSeatPair, error) {
+	pair, err := r.db.LockAccessibleSeatPair(ctx, eventCatID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.AccessibleSeatPair{}, fmt.Errorf("no available accessible seat pair")
+		}
+		return model.AccessibleSeatPair{}, fmt.Errorf("lock accessible seat pair: %w", err)
+	}
+	return model.AccessibleSeatPair{
+		TicketID:            pair.TicketID,
+		SeatNumber:          pair.SeatNumber.String,
+		CompanionTicketID:   pair.CompanionTicketID,
+		CompanionSeatNumber: pair.CompanionSeatNumber.String,
+	}, nil
+}
+
+func (r *ticketRepo) MarkTicketSold(ctx context.Context, ticketID int32) error {
+	if err := r.db.MarkTicketSold(ctx, ticketID); err != nil {
+		return fmt.Errorf("mark ticket %d sold: %w", ticketID, err)
+	}
+	return nil
+}
+
+func (r *ticketRepo) ReleaseSoldTicket(ctx context.Context, eventCatID, ticketID int32) error {
+	released, err := r.db.ReleaseSoldTicket(ctx, ticketDB.ReleaseSoldTicketParams{
+		ID:              ticketID,
+		EventCategoryID: eventCatID,
+	})
+	if err != nil {
+		return fmt.Errorf("release sold ticket %d: %w", ticketID, err)
+	}
+	if released == 0 {
+		return fmt.Errorf("ticket %d is not sold in event category %d", ticketID, eventCatID)
+	}
+	return nil
+}
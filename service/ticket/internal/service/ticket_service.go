@@ -7,12 +7,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"ticket-tix/common/pkg/events"
+	"ticket-tix/common/pkg/jwt"
 	"ticket-tix/common/pkg/storage"
 	"ticket-tix/service/ticket/internal/model"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	producerName = "ticket-service"
+)
+
 type TicketService struct {
 	storage *storage.Storage
 	repo    model.TicketRepo
@@ -23,14 +30,31 @@ func NewTicketService(db *sql.DB, storage *storage.Storage, repo model.TicketRep
 	return &TicketService{db: db, storage: storage, repo: repo}
 }
 
-func (s *TicketService) CreateEvent(ctx context.Context, req model.InsertTicketRequest) (model.EventData, error) {
+// repoFor returns the repo an actor manages events through. Admins see every event,
+// organizers only their organization's.
+func (s *TicketService) repoFor(actor model.Actor) (model.TicketRepo, error) {
+	switch {
+	case actor.Role == jwt.RoleAdmin:
+		return s.repo, nil
+	case actor.Role == jwt.RoleOrganizer && actor.OrgID != 0:
+		return s.repo.ForOrganizer(actor.OrgID), nil
+	}
+	return nil, model.ErrForbidden
+}
+
+func (s *TicketService) CreateEvent(ctx context.Context, actor model.Actor, req model.InsertTicketRequest) (model.EventData, error) {
+	repo, err := s.repoFor(actor)
+	if err != nil {
+		return model.EventData{}, err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return model.EventData{}, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	txRepo := s.repo.WithTx(tx)
+	txRepo := repo.WithTx(tx)
 
 	event, err := txRepo.InsertEvent(ctx, req.Event)
 	if err != nil {
@@ -62,15 +86,22 @@ func (s *TicketService) CreateEvent(ctx context.Context, req model.InsertTicketR
 	return event, nil
 }
 
-func (s *TicketService) DeleteImg(ctx context.Context, key string) error {
+func (s *TicketService) DeleteImg(ctx context.Context, actor model.Actor, eventID int32, key string) error {
+	repo, err := s.repoFor(actor)
+	if err != nil {
+		return err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback()
 
-	txRepo := s.repo.WithTx(tx)
-	err = txRepo.DeleteEventImage(ctx, key)
+	txRepo := repo.WithTx(tx)
+	if err := txRepo.DeleteEventImage(ctx, eventID, key); err != nil {
+		return err
+	}
 	if err := s.storage.Delete(ctx, key); err != nil {
 		return fmt.Errorf("delete image: %w", err)
 	}
@@ -80,7 +111,16 @@ func (s *TicketService) DeleteImg(ctx context.Context, key string) error {
 	return nil
 }
 
-func (s *TicketService) UploadImg(ctx context.Context, eventID int32, files []model.FileData) error {
+func (s *TicketService) UploadImg(ctx context.Context, actor model.Actor, eventID int32, files []model.FileData) error {
+	repo, err := s.repoFor(actor)
+	if err != nil {
+		return err
+	}
+	// check access before anything is uploaded to storage
+	if _, err := repo.GetEventByID(ctx, eventID); err != nil {
+		return err
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
@@ -92,7 +132,7 @@ func (s *TicketService) UploadImg(ctx context.Context, eventID int32, files []mo
 		return uploadErr
 	}
 
-	txRepo := s.repo.WithTx(tx)
+	txRepo := repo.WithTx(tx)
 	for i, key := range fileKeys {
 		if err := txRepo.InsertEventImage(ctx, model.ImageKeyData{
 			EventID:      eventID,
@@ -144,8 +184,21 @@ func (s *TicketService) GetEventDetail(ctx context.Context, id int32) (model.Eve
 }
 
 func (s *TicketService) BrowseEvents(ctx context.Context, filter model.BrowseFilter) (model.BrowseResult, error) {
+	return s.browse(ctx, s.repo, filter)
+}
+
+// BrowseOrganizerEvents lists the events the actor manages.
+func (s *TicketService) BrowseOrganizerEvents(ctx context.Context, actor model.Actor, filter model.BrowseFilter) (model.BrowseResult, error) {
+	repo, err := s.repoFor(actor)
+	if err != nil {
+		return model.BrowseResult{}, err
+	}
+	return s.browse(ctx, repo, filter)
+}
+
+func (s *TicketService) browse(ctx context.Context, repo model.TicketRepo, filter model.BrowseFilter) (model.BrowseResult, error) {
 	filter.Limit += 1
-	events, err := s.repo.BrowseEvents(ctx, filter)
+	events, err := repo.BrowseEvents(ctx, filter)
 	if err != nil {
 		return model.BrowseResult{}, fmt.Errorf("browse events: %w", err)
 	}
@@ -206,6 +259,7 @@ func (s *TicketService) UpdateTicketStatus(ctx context.Context, status, seatNum
 
 // ValidateTicketBooking validates whether a ticket/seat can be booked.
 // For SEATED categories: checks the specific seat exists, is AVAILABLE, and not expired-reserved.
+// Accessible and companion seats are rejected until the category's accessible release time.
 // For STANDING categories: checks there is remaining available stock.
 func (s *TicketService) ValidateTicketBooking(ctx context.Context, seatId string, eventID, eventCategory int32) error {
 	ecDetail, err := s.repo.GetEventCategoryByID(ctx, eventCategory)
@@ -234,6 +288,14 @@ func (s *TicketService) ValidateTicketBooking(ctx context.Context, seatId string
 				return fmt.Errorf("ticket is not available for seat %s", seatId)
 			}
 
+			held, err := s.repo.IsSeatHeldForAccessibility(ctx, ticketDetail.ID)
+			if err != nil {
+				return fmt.Errorf("check accessible hold: %w", err)
+			}
+			if held {
+				return fmt.Errorf("ticket is not available for seat %s, held for accessible seating", seatId)
+			}
+
 		case "FLEXIBLE":
 			if seatId != "" {
 				return fmt.Errorf("seat_id must be empty for SEATED category with FLEXIBLE book type")
@@ -300,6 +362,57 @@ func (s *TicketService) ReleaseTicket(ctx context.Context, seatNum string, event
 	return err
 }
 
+// RecordStockMovement appends a standing stock change to the Postgres ledger.
+func (s *TicketService) RecordStockMovement(ctx context.Context, movement model.StockMovement) error {
+	return s.repo.InsertStockMovement(ctx, movement)
+}
+
+// ExpireReservedSeats frees lapsed seat reservations and queues a
+// ticket.reservation.expired event for each in the outbox, in the same transaction,
+// so an event exists exactly when the seat was actually released.
+func (s *TicketService) ExpireReservedSeats(ctx context.Context) ([]model.ExpiredSeat, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := s.repo.WithTx(tx)
+	expired, err := txRepo.ExpireReservedSeats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, seat := range expired {
+		msg, err := events.Encode(producerName, fmt.Sprint(seat.TicketID), events.TicketReservationExpiredEvent{
+			TicketID:   seat.TicketID,
+			EventID:    seat.EventID,
+			EventCatID: seat.EventCategoryID,
+			SeatNumber: seat.SeatNumber,
+			ExpiredAt:  now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = txRepo.InsertOutboxMessage(ctx, model.OutboxMessage{
+			Topic:   events.TicketReservationExpiredEvent{}.EventType(),
+			Key:     string(msg.Key),
+			Payload: msg.Value,
+			Headers: msg.Headers,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return expired, nil
+}
+
 func (s *TicketService) insertFiles(ctx context.Context, eventID int32, files []model.FileData) ([]string, error) {
 	filesKey := make([]string, 0, len(files))
 	for _, file := range files {
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ticket-tix/service/ticket/internal/model"
+)
+
+// CreateVenue stores a reusable venue layout (sections → rows → seats) in a single transaction.
+func (s *TicketService) CreateVenue(ctx context.Context, chart model.VenueChart) (model.VenueChart, error) {
+	if chart.Venue.Name == "" {
+		return model.VenueChart{}, fmt.Errorf("%w: venue name is required", model.ErrInvalidLayout)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.VenueChart{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := s.repo.WithTx(tx)
+
+	venue, err := txRepo.InsertVenue(ctx, chart.Venue)
+	if err != nil {
+		return model.VenueChart{}, err
+	}
+
+	for i := range chart.Sections {
+		section := &chart.Sections[i]
+		if section.Code == "" {
+			return model.VenueChart{}, fmt.Errorf("%w: section code is required for section %q", model.ErrInvalidLayout, section.Name)
+		}
+
+		section.ID, err = txRepo.InsertSection(ctx, venue.ID, *section)
+		if err != nil {
+			return model.VenueChart{}, err
+		}
+
+		for j := range section.Rows {
+			row := &section.Rows[j]
+			row.ID, err = txRepo.InsertRow(ctx, section.ID, *row)
+			if err != nil {
+				return model.VenueChart{}, err
+			}
+
+			seatIDs := make(map[string]int32, len(row.Seats))
+			for k := range row.Seats {
+				seat := &row.Seats[k]
+				seat.ID, err = txRepo.InsertSeat(ctx, row.ID, *seat)
+				if err != nil {
+					return model.VenueChart{}, err
+				}
+				seatIDs[seat.Label] = seat.ID
+			}
+
+			if err := linkCompanionSeats(ctx, txRepo, row, seatIDs); err != nil {
+				return model.VenueChart{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.VenueChart{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	chart.Venue = venue
+	return chart, nil
+}
+
+// linkCompanionSeats points every accessible seat in the row at the seat named by its CompanionLabel.
+func linkCompanionSeats(ctx context.Context, repo model.TicketRepo, row *model.RowLayout, seatIDs map[string]int32) error {
+	for k := range row.Seats {
+		seat := &row.Seats[k]
+		if seat.CompanionLabel == "" {
+			continue
+		}
+		if !seat.IsAccessible {
+			return fmt.Errorf("%w: seat %s-%s has a companion but is not accessible", model.ErrInvalidLayout, row.Label, seat.Label)
+		}
+
+		companionID, ok := seatIDs[seat.CompanionLabel]
+		if !ok || companionID == seat.ID {
+			return fmt.Errorf("%w: invalid companion %q for seat %s-%s", model.ErrInvalidLayout, seat.CompanionLabel, row.Label, seat.Label)
+		}
+
+		if err := repo.SetSeatCompanion(ctx, seat.ID, companionID); err != nil {
+			return err
+		}
+		seat.CompanionSeatID = companionID
+	}
+	return nil
+}
+
+func (s *TicketService) GetVenueChart(ctx context.Context, venueID int32) (model.VenueChart, error) {
+	venue, err := s.repo.GetVenueByID(ctx, venueID)
+	if err != nil {
+		return model.VenueChart{}, fmt.Errorf("get venue by id: %w", err)
+	}
+
+	sections, err := s.repo.GetVenueChart(ctx, venueID)
+	if err != nil {
+		return model.VenueChart{}, err
+	}
+
+	return model.VenueChart{Venue: venue, Sections: sections}, nil
+}
+
+// ApplyEventLayout attaches a venue to an event, maps the venue sections onto the
+// event's SEATED categories and generates one ticket per seat. Capacity and stock of
+// the mapped categories are recomputed from all of their tickets. Applying a layout
+// again is safe: existing tickets are linked to their seat instead of duplicated, and
+// remapping a section to another category fails with ErrLayoutConflict.
+// Returns the number of tickets created or linked.
+func (s *TicketService) ApplyEventLayout(ctx context.Context, actor model.Actor, req model.EventLayoutRequest) (int64, error) {
+	if len(req.Sections) == 0 {
+		return 0, fmt.Errorf("%w: at least one section mapping is required", model.ErrInvalidLayout)
+	}
+
+	repo, err := s.repoFor(actor)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := repo.GetEventByID(ctx, req.EventID); err != nil {
+		return 0, fmt.Errorf("get event by id: %w", err)
+	}
+
+	chart, err := s.GetVenueChart(ctx, req.VenueID)
+	if err != nil {
+		return 0, err
+	}
+
+	venueSections := make(map[int32]bool, len(chart.Sections))
+	for _, sec := range chart.Sections {
+		venueSections[sec.ID] = true
+	}
+
+	for _, m := range req.Sections {
+		if !venueSections[m.SectionID] {
+			return 0, fmt.Errorf("%w: section %d does not belong to venue %d", model.ErrInvalidLayout, m.SectionID, req.VenueID)
+		}
+
+		ec, err := s.repo.GetEventCategoryByID(ctx, m.EventCategoryID)
+		if err != nil {
+			return 0, fmt.Errorf("get event category by id: %w", err)
+		}
+		if ec.EventID != req.EventID {
+			return 0, fmt.Errorf("%w: event category %d does not belong to event %d", model.ErrInvalidLayout, m.EventCategoryID, req.EventID)
+		}
+		if ec.CategoryType != "SEATED" {
+			return 0, fmt.Errorf("%w: event category %d is not SEATED", model.ErrInvalidLayout, m.EventCategoryID)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := repo.WithTx(tx)
+
+	if err := txRepo.SetEventVenue(ctx, req.EventID, req.VenueID); err != nil {
+		return 0, err
+	}
+
+	for _, m := range req.Sections {
+		if err := txRepo.MapSectionToCategory(ctx, req.EventID, m.SectionID, m.EventCategoryID); err != nil {
+			return 0, err
+		}
+	}
+
+	released := make(map[int32]bool, len(req.Sections))
+	for _, m := range req.Sections {
+		if released[m.EventCategoryID] {
+			continue
+		}
+		if err := txRepo.SetCategoryAccessibleRelease(ctx, m.EventCategoryID, req.AccessibleReleaseAt); err != nil {
+			return 0, err
+		}
+		released[m.EventCategoryID] = true
+	}
+
+	created, err := txRepo.GenerateTicketsFromLayout(ctx, req.EventID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := txRepo.SyncCategoryCapacityFromLayout(ctx, req.EventID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *TicketService) GetEventSeatingChart(ctx context.Context, eventID int32) (model.EventSeatingChart, error) {
+	event, err := s.repo.GetEventByID(ctx, eventID)
+	if err != nil {
+		return model.EventSeatingChart{}, fmt.Errorf("get event by id: %w", err)
+	}
+
+	if event.VenueID == 0 {
+		return model.EventSeatingChart{}, fmt.Errorf("%w: event %d has no seating chart", model.ErrVenueNotFound, eventID)
+	}
+
+	venue, err := s.repo.GetVenueByID(ctx, event.VenueID)
+	if err != nil {
+		return model.EventSeatingChart{}, fmt.Errorf("get venue by id: %w", err)
+	}
+
+	sections, err := s.repo.GetEventSeatingChart(ctx, eventID)
+	if err != nil {
+		return model.EventSeatingChart{}, err
+	}
+
+	return model.EventSeatingChart{
+		EventID:  eventID,
+		Venue:    venue,
+		Sections: sections,
+	}, nil
+}
+
+// ReserveAccessibleSeatPair sells an accessible seat together with its linked companion
+// seat. Both tickets are locked and marked SOLD in one transaction, so the pair is
+// never split between buyers.
+func (s *TicketService) ReserveAccessibleSeatPair(ctx context.Context, eventCatID int32) (model.AccessibleSeatPair, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.AccessibleSeatPair{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := s.repo.WithTx(tx)
+
+	pair, err := txRepo.LockAccessibleSeatPair(ctx, eventCatID)
+	if err != nil {
+		return model.AccessibleSeatPair{}, err
+	}
+
+	if err := txRepo.MarkTicketSold(ctx, pair.TicketID); err != nil {
+		return model.AccessibleSeatPair{}, err
+	}
+	if err := txRepo.MarkTicketSold(ctx, pair.CompanionTicketID); err != nil {
+		return model.AccessibleSeatPair{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.AccessibleSeatPair{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return pair, nil
+}
+
+// ReleaseAccessibleSeatPair puts a pair sold by ReserveAccessibleSeatPair back on sale,
+// for when the bookings of the pair could not be created. Both seats are released or
+// neither.
+func (s *TicketService) ReleaseAccessibleSeatPair(ctx context.Context, eventCatID, ticketID, companionTicketID int32) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	txRepo := s.repo.WithTx(tx)
+
+	if err := txRepo.ReleaseSoldTicket(ctx, eventCatID, ticketID); err != nil {
+		return err
+	}
+	if err := txRepo.ReleaseSoldTicket(ctx, eventCatID, companionTicketID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
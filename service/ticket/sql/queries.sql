@@ -1,6 +1,6 @@
 -- name: InsertEvent :one
-INSERT INTO events (name, description, location, start_time, end_time)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO events (name, description, location, start_time, end_time, created_by, organizer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
     RETURNING *;
 
 -- name: InsertEventImage :one
@@ -25,7 +25,7 @@ VALUES ($1, $2)
 
 -- name: DeleteEventImage :exec
 DELETE FROM event_images
-WHERE image_key = $1;
+WHERE image_key = $1 AND event_id = $2;
 
 -- name: GetEventDetails :one
 SELECT * FROM events
@@ -53,6 +53,7 @@ WHERE
     (sqlc.arg(location)::text = '' OR e.location ILIKE '%' || sqlc.arg(location) || '%') AND
     (sqlc.arg(start_date)::timestamp = '0001-01-01 00:00:00' OR e.start_time >= sqlc.arg(start_date)) AND
     (sqlc.arg(end_date)::timestamp = '0001-01-01 00:00:00' OR e.start_time <= sqlc.arg(end_date)) AND
+    (sqlc.arg(organizer_id)::int = 0 OR e.organizer_id = sqlc.arg(organizer_id)) AND
     (
         sqlc.arg(cursor_time)::timestamp = '0001-01-01 00:00:00' OR
         e.start_time > sqlc.arg(cursor_time)::timestamp OR
@@ -73,16 +74,24 @@ WHERE event_category_id = $1 AND seat_number = $2
 LIMIT 1;
 
 -- name: ReserveAvailableSeat :one
+-- accessible and companion seats are skipped until the category's accessible_release_at
 UPDATE tickets
 SET status = 'SOLD'
 WHERE id = (
     SELECT t.id
     FROM tickets t
+    JOIN event_categories ec ON ec.id = t.event_category_id
+    LEFT JOIN seats s ON s.id = t.seat_id
     WHERE t.event_category_id = $1
       AND t.status = 'AVAILABLE'
+      AND (
+          s.id IS NULL OR
+          ec.accessible_release_at <= NOW() OR
+          (NOT s.is_accessible AND NOT EXISTS (SELECT 1 FROM seats a WHERE a.companion_seat_id = s.id))
+          )
     ORDER BY t.id
     LIMIT 1
-    FOR UPDATE SKIP LOCKED
+    FOR UPDATE OF t SKIP LOCKED
             )
 RETURNING id, seat_number;
 
@@ -95,8 +104,212 @@ UPDATE event_categories
 SET available_stock = $1
 WHERE id = $2;
 
+-- name: UpdateEventCategoryCapacity :exec
+UPDATE event_categories
+SET total_capacity = $1, available_stock = $2
+WHERE id = $3;
+
 -- name: ExpireReservedTickets :many
-UPDATE tickets
+UPDATE tickets t
 SET status = 'AVAILABLE', reserved_until = NULL
-WHERE status = 'RESERVED' AND reserved_until < NOW()
-RETURNING id, event_category_id, seat_number;
\ No newline at end of file
+FROM event_categories ec
+WHERE ec.id = t.event_category_id
+  AND t.status = 'RESERVED' AND t.reserved_until < NOW()
+RETURNING t.id, t.event_category_id, t.seat_number, ec.event_id;
+
+-- name: InsertVenue :one
+INSERT INTO venues (name, address)
+VALUES ($1, $2)
+    RETURNING *;
+
+-- name: InsertSection :one
+INSERT INTO sections (venue_id, name, code, pos_x, pos_y, width, height)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING *;
+
+-- name: InsertRow :one
+INSERT INTO rows (section_id, label, display_order)
+VALUES ($1, $2, $3)
+    RETURNING *;
+
+-- name: InsertSeat :one
+INSERT INTO seats (row_id, label, pos_x, pos_y, is_accessible, is_obstructed_view)
+VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING *;
+
+-- name: GetVenueByID :one
+SELECT * FROM venues
+WHERE id = $1;
+
+-- name: GetVenueChart :many
+SELECT sec.id AS section_id, sec.name AS section_name, sec.code AS section_code,
+       sec.pos_x AS section_pos_x, sec.pos_y AS section_pos_y, sec.width AS section_width, sec.height AS section_height,
+       r.id AS row_id, r.label AS row_label, r.display_order AS row_display_order,
+       s.id AS seat_id, s.label AS seat_label, s.pos_x AS seat_pos_x, s.pos_y AS seat_pos_y,
+       s.is_accessible, s.is_obstructed_view, s.companion_seat_id
+FROM sections sec
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+WHERE sec.venue_id = $1
+ORDER BY sec.id, r.display_order, r.id, s.id;
+
+-- name: SetEventVenue :exec
+UPDATE events
+SET venue_id = $1
+WHERE id = $2;
+
+-- name: UpsertEventSectionCategory :execrows
+-- mapping a section again to the same category is a no-op; mapping it to another
+-- category affects no row
+INSERT INTO event_section_categories (event_id, section_id, event_category_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (event_id, section_id) DO UPDATE
+SET event_category_id = EXCLUDED.event_category_id
+WHERE event_section_categories.event_category_id = EXCLUDED.event_category_id;
+
+-- name: GenerateTicketsFromLayout :execrows
+INSERT INTO tickets (event_category_id, seat_number, status, seat_id)
+SELECT esc.event_category_id, sec.code || '-' || r.label || '-' || s.label, 'AVAILABLE', s.id
+FROM event_section_categories esc
+JOIN sections sec ON sec.id = esc.section_id
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+WHERE esc.event_id = $1
+ON CONFLICT (event_category_id, seat_number) DO UPDATE
+SET seat_id = EXCLUDED.seat_id
+WHERE tickets.seat_id IS NULL;
+
+-- name: CountUnlinkedLayoutSeats :one
+SELECT COUNT(*) FROM event_section_categories esc
+JOIN rows r ON r.section_id = esc.section_id
+JOIN seats s ON s.row_id = r.id
+WHERE esc.event_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM tickets t
+    WHERE t.event_category_id = esc.event_category_id AND t.seat_id = s.id
+);
+
+-- name: SyncCategoryCapacityFromLayout :exec
+UPDATE event_categories ec
+SET total_capacity = t.total, available_stock = t.available
+FROM (
+    SELECT event_category_id,
+           COUNT(*) AS total,
+           COUNT(*) FILTER (WHERE status = 'AVAILABLE') AS available
+    FROM tickets
+    WHERE event_category_id IN (
+        SELECT event_category_id FROM event_section_categories WHERE event_id = $1
+    )
+    GROUP BY event_category_id
+     ) t
+WHERE ec.id = t.event_category_id AND ec.event_id = $1;
+
+-- name: GetEventSeatingChart :many
+SELECT sec.id AS section_id, sec.name AS section_name, sec.code AS section_code,
+       sec.pos_x AS section_pos_x, sec.pos_y AS section_pos_y, sec.width AS section_width, sec.height AS section_height,
+       r.id AS row_id, r.label AS row_label, r.display_order AS row_display_order,
+       s.id AS seat_id, s.label AS seat_label, s.pos_x AS seat_pos_x, s.pos_y AS seat_pos_y,
+       s.is_accessible, s.is_obstructed_view, s.companion_seat_id,
+       esc.event_category_id, t.id AS ticket_id, t.status AS ticket_status
+FROM events e
+JOIN sections sec ON sec.venue_id = e.venue_id
+JOIN rows r ON r.section_id = sec.id
+JOIN seats s ON s.row_id = r.id
+LEFT JOIN event_section_categories esc ON esc.section_id = sec.id AND esc.event_id = e.id
+LEFT JOIN tickets t ON t.seat_id = s.id AND t.event_category_id = esc.event_category_id
+WHERE e.id = $1
+ORDER BY sec.id, r.display_order, r.id, s.id;
+
+
+-- name: SetSeatCompanion :exec
+UPDATE seats
+SET companion_seat_id = $1
+WHERE id = $2;
+
+-- name: SetCategoryAccessibleRelease :exec
+UPDATE event_categories
+SET accessible_release_at = $1
+WHERE id = $2;
+
+-- name: GetSeatHold :one
+SELECT s.is_accessible,
+       EXISTS (SELECT 1 FROM seats a WHERE a.companion_seat_id = s.id) AS is_companion,
+       COALESCE(ec.accessible_release_at <= NOW(), FALSE)::boolean AS is_released
+FROM tickets t
+JOIN event_categories ec ON ec.id = t.event_category_id
+JOIN seats s ON s.id = t.seat_id
+WHERE t.id = $1;
+
+-- name: LockAccessibleSeatPair :one
+SELECT t.id AS ticket_id, t.seat_number, c.id AS companion_ticket_id, c.seat_number AS companion_seat_number
+FROM tickets t
+JOIN seats s ON s.id = t.seat_id
+JOIN tickets c ON c.seat_id = s.companion_seat_id AND c.event_category_id = t.event_category_id
+WHERE t.event_category_id = $1
+  AND s.is_accessible
+  AND t.status = 'AVAILABLE'
+  AND c.status = 'AVAILABLE'
+ORDER BY t.id
+LIMIT 1
+FOR UPDATE OF t, c SKIP LOCKED;
+
+-- name: MarkTicketSold :exec
+UPDATE tickets
+SET status = 'SOLD', reserved_until = NULL
+WHERE id = $1;
+
+-- name: ReleaseSoldTicket :execrows
+UPDATE tickets
+SET status = 'AVAILABLE'
+WHERE id = $1 AND event_category_id = $2 AND status = 'SOLD';
+
+-- name: GetStandingStockExpectations :many
+SELECT ec.id, ec.total_capacity, ec.available_stock,
+       COALESCE(b.confirmed, 0)::int AS confirmed
+FROM event_categories ec
+LEFT JOIN (
+    SELECT event_category_id, COUNT(*) AS confirmed
+    FROM bookings
+    WHERE ticket_id IS NULL AND status = 'CONFIRMED'
+    GROUP BY event_category_id
+          ) b ON b.event_category_id = ec.id
+WHERE ec.category_type = 'STANDING';
+
+-- name: InsertStockMovement :exec
+INSERT INTO stock_movements (event_category_id, delta, reason, reference)
+VALUES ($1, $2, $3, $4);
+
+-- name: InsertOutboxEvent :exec
+INSERT INTO outbox_events (topic, message_key, payload, headers)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetPendingOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE published_at IS NULL AND dead_lettered_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = NOW()
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: MarkOutboxEventDeadLettered :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2, dead_lettered_at = NOW()
+WHERE id = $1;
+
+-- name: GetEventOrganizer :one
+SELECT organizer_id FROM events
+WHERE id = $1;
+
+-- name: GetEventCategoryOrganizer :one
+SELECT e.organizer_id
+FROM event_categories ec
+JOIN events e ON e.id = ec.event_id
+WHERE ec.id = $1;
\ No newline at end of file